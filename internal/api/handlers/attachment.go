@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"tg_bot_asist/internal/api/middleware"
+	"tg_bot_asist/internal/attachment"
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+)
+
+type AttachmentHandler struct {
+	service *attachment.Service
+}
+
+func NewAttachmentHandler(service *attachment.Service) *AttachmentHandler {
+	return &AttachmentHandler{service: service}
+}
+
+// List возвращает вложения записи: GET ?owner=todo|finance&owner_id=<id>.
+func (h *AttachmentHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	owner, ok := attachment.ParseOwner(r.URL.Query().Get("owner"))
+	if !ok {
+		http.Error(w, "Invalid owner", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := strconv.Atoi(r.URL.Query().Get("owner_id"))
+	if err != nil || ownerID <= 0 {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.service.List(r.Context(), userID, owner, ownerID)
+	if err != nil {
+		writeAttachmentError(w, "Failed to list attachments", err)
+		return
+	}
+	if list == nil {
+		list = []attachment.Attachment{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// inlineTypes — типы файлов, которые браузер может показать сам. Остальные (HTML, SVG и т.п.)
+// отдаются как application/octet-stream на скачивание: иначе вложение из общего проекта
+// исполнялось бы на домене API у других участников.
+var inlineTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"audio/ogg":       true,
+	"audio/mpeg":      true,
+	"audio/mp4":       true,
+	"video/mp4":       true,
+	"video/quicktime": true,
+	"application/pdf": true,
+	"text/plain":      true,
+}
+
+// Download отдаёт содержимое вложения: GET ?id=<id>. Файл скачивается из Telegram
+// и передаётся клиенту потоком, токен бота наружу не попадает.
+func (h *AttachmentHandler) Download(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid id", http.StatusBadRequest)
+		return
+	}
+
+	a, body, err := h.service.Open(r.Context(), userID, id)
+	if err != nil {
+		writeAttachmentError(w, "Failed to download attachment", err)
+		return
+	}
+	defer body.Close()
+
+	contentType, disposition := "application/octet-stream", "attachment"
+	if mediaType, _, err := mime.ParseMediaType(a.MimeType); err == nil && inlineTypes[mediaType] {
+		contentType, disposition = mediaType, "inline"
+	}
+	if a.FileName != "" {
+		if v := mime.FormatMediaType(disposition, map[string]string{"filename": a.FileName}); v != "" {
+			disposition = v
+		}
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if _, err := io.Copy(w, body); err != nil {
+		logger.Error("Attachment download copy error: " + err.Error())
+	}
+}
+
+// writeAttachmentError отвечает ошибкой вложений с подходящим HTTP-статусом.
+func writeAttachmentError(w http.ResponseWriter, op string, err error) {
+	if errors.Is(err, attachment.ErrNotFound) || errors.Is(err, todo.ErrNotFound) || errors.Is(err, finance.ErrNotFound) {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, attachment.ErrOwner) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	logger.Error(op + ": " + err.Error())
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+}
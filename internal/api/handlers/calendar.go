@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"tg_bot_asist/internal/api/middleware"
+	"tg_bot_asist/internal/calendar"
+	"tg_bot_asist/internal/config"
+	"tg_bot_asist/internal/logger"
+)
+
+type CalendarHandler struct {
+	service *calendar.Service
+}
+
+func NewCalendarHandler(service *calendar.Service) *CalendarHandler {
+	return &CalendarHandler{service: service}
+}
+
+// Feed отдаёт календарь пользователя в формате iCalendar.
+// Авторизация по токену подписки (?token=), т.к. календарные приложения не умеют JWT.
+func (h *CalendarHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := h.service.UserByToken(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		if errors.Is(err, calendar.ErrInvalidToken) {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+		logger.Error("Failed to check calendar token: " + err.Error())
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	events, err := h.service.Events(r.Context(), userID, now)
+	if err != nil {
+		logger.Error("Failed to build calendar: " + err.Error())
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := calendar.WriteICS(&buf, "Ассистент", events, now); err != nil {
+		logger.Error("Failed to write calendar: " + err.Error())
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="calendar.ics"`)
+	w.Write(buf.Bytes())
+}
+
+// Token возвращает ссылку подписки на календарь (GET) или выпускает новую (POST).
+func (h *CalendarHandler) Token(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var token string
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		token, err = h.service.Token(r.Context(), userID)
+	case http.MethodPost:
+		token, err = h.service.RotateToken(r.Context(), userID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to get calendar token: " + err.Error())
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"token": token,
+		"url":   calendar.FeedURL(config.Get("API_PUBLIC_URL"), token),
+	})
+}
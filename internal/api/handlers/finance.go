@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
@@ -13,11 +14,12 @@ import (
 
 type FinanceHandler struct {
 	service *finance.Service
+	users   LocationProvider
 	hub     *websocket.Hub
 }
 
-func NewFinanceHandler(service *finance.Service, hub *websocket.Hub) *FinanceHandler {
-	return &FinanceHandler{service: service, hub: hub}
+func NewFinanceHandler(service *finance.Service, users LocationProvider, hub *websocket.Hub) *FinanceHandler {
+	return &FinanceHandler{service: service, users: users, hub: hub}
 }
 
 // List возвращает список финансовых операций.
@@ -44,6 +46,33 @@ type AddFinanceRequest struct {
 	Category string  `json:"category"`
 	Type     string  `json:"type"` // "income" or "expense"
 	Note     string  `json:"note"`
+	// OccurredAt — дата операции: RFC3339 или YYYY-MM-DD; пусто — сейчас.
+	OccurredAt string `json:"occurred_at"`
+}
+
+// parseOccurredAt разбирает дату операции из запроса: RFC3339 или YYYY-MM-DD.
+// Дата в будущем не допускается.
+func parseOccurredAt(value string) (time.Time, error) {
+	now := time.Now()
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		if t.After(now) {
+			return time.Time{}, finance.ErrFutureDate
+		}
+		return t, nil
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return time.Time{}, finance.ErrInvalidDate
+	}
+	return finance.ParseOccurredDate(value, now)
+}
+
+// writeOccurredAtError отвечает 400 на некорректную дату операции.
+func writeOccurredAtError(w http.ResponseWriter, err error) {
+	if errors.Is(err, finance.ErrFutureDate) {
+		http.Error(w, "occurred_at must not be in the future", http.StatusBadRequest)
+		return
+	}
+	http.Error(w, "occurred_at must be RFC3339 or YYYY-MM-DD", http.StatusBadRequest)
 }
 
 // Add создаёт новую финансовую операцию.
@@ -70,19 +99,29 @@ func (h *FinanceHandler) Add(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var occurredAt time.Time
+	if req.OccurredAt != "" {
+		t, err := parseOccurredAt(req.OccurredAt)
+		if err != nil {
+			writeOccurredAtError(w, err)
+			return
+		}
+		occurredAt = t
+	}
+
 	entry := &finance.FinanceEntry{
-		UserID:    userID,
-		Amount:    req.Amount,
-		Category:  req.Category,
-		Type:      req.Type,
-		Note:      req.Note,
-		CreatedAt: time.Now(),
+		UserID:     userID,
+		Amount:     req.Amount,
+		Category:   req.Category,
+		Type:       req.Type,
+		Note:       req.Note,
+		CreatedAt:  time.Now(),
+		OccurredAt: occurredAt,
 	}
 
 	err := h.service.AddEntry(r.Context(), entry)
 	if err != nil {
-		logger.Error("Failed to add finance entry: " + err.Error())
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeFinanceError(w, "Failed to add finance entry", err)
 		return
 	}
 
@@ -103,27 +142,18 @@ func (h *FinanceHandler) Stats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entries, err := h.service.ListEntries(r.Context(), userID)
+	totals, err := h.service.AllTimeTotals(r.Context(), userID)
 	if err != nil {
 		logger.Error("Failed to get finance stats: " + err.Error())
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	var totalIncome, totalExpense float64
-	for _, e := range entries {
-		if e.Type == "income" {
-			totalIncome += e.Amount
-		} else {
-			totalExpense += e.Amount
-		}
-	}
-
 	stats := map[string]interface{}{
-		"total_income":  totalIncome,
-		"total_expense": totalExpense,
-		"balance":       totalIncome - totalExpense,
-		"transactions":  len(entries),
+		"total_income":  totals.Income,
+		"total_expense": totals.Expense,
+		"balance":       totals.Balance(),
+		"transactions":  totals.Count,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
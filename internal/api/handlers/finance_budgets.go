@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"tg_bot_asist/internal/api/middleware"
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/logger"
+)
+
+// Budgets — месячные бюджеты на одном пути /api/finance/budgets:
+//
+//	GET    [?month=YYYY-MM][&tz=<IANA-зона>]                        — исполнение бюджетов за месяц (по умолчанию текущий)
+//	POST   {"category_id": N, "limit": 15000, "rollover": "none"}    — задать бюджет (category_id 0 — общий);
+//	                                                                 бюджет на ту же категорию заменяется
+//	DELETE {"id": N}                                                 — удалить бюджет
+//
+// Пересечение 80% и 100% бюджета при добавлении расхода рассылается событием budget_alert.
+func (h *FinanceHandler) Budgets(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		loc, err := requestLocation(r, h.users, userID)
+		if err != nil {
+			http.Error(w, "Invalid tz", http.StatusBadRequest)
+			return
+		}
+		month := time.Now().In(loc)
+		if v := q.Get("month"); v != "" {
+			m, err := time.ParseInLocation("2006-01", v, loc)
+			if err != nil {
+				http.Error(w, "month must be YYYY-MM", http.StatusBadRequest)
+				return
+			}
+			month = m
+		}
+
+		report, err := h.service.BudgetReport(r.Context(), userID, month)
+		if err != nil {
+			writeBudgetError(w, "Failed to build budget report", err)
+			return
+		}
+		if report == nil {
+			report = []finance.BudgetProgress{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+
+	case http.MethodPost:
+		var req struct {
+			CategoryID int              `json:"category_id"`
+			Limit      float64          `json:"limit"`
+			Rollover   finance.Rollover `json:"rollover"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		b, err := h.service.SetBudget(r.Context(), userID, req.CategoryID, req.Limit, req.Rollover)
+		if err != nil {
+			writeBudgetError(w, "Failed to set budget", err)
+			return
+		}
+		h.broadcast("budget_updated", userID, b)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(b)
+
+	case http.MethodDelete:
+		var req struct {
+			ID int `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := h.service.DeleteBudget(r.Context(), userID, req.ID); err != nil {
+			writeBudgetError(w, "Failed to delete budget", err)
+			return
+		}
+		h.broadcast("budget_deleted", userID, map[string]interface{}{"id": req.ID})
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// writeBudgetError отвечает 404 для несуществующего бюджета или категории, 400 для некорректных данных
+// и 500 для остальных ошибок.
+func writeBudgetError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, finance.ErrBudgetNotFound):
+		http.Error(w, "Budget not found", http.StatusNotFound)
+	case errors.Is(err, finance.ErrCategoryNotFound):
+		http.Error(w, "Category not found", http.StatusNotFound)
+	case errors.Is(err, finance.ErrBudgetLimit), errors.Is(err, finance.ErrBudgetCategory), errors.Is(err, finance.ErrBudgetRollover):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		logger.Error(op + ": " + err.Error())
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"tg_bot_asist/internal/api/middleware"
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/logger"
+)
+
+// Categories — CRUD справочника категорий на одном пути /api/finance/categories:
+//
+//	GET    [?type=income|expense]                                  — список категорий
+//	POST   {"name": "...", "type": "...", "icon": "...", "parent_id": N} — создать
+//	PUT    {"id": N, "name": ..., "icon": ..., "parent_id": ...}     — изменить (отсутствующие поля не меняются,
+//	                                                                parent_id 0 — сделать верхнеуровневой)
+//	DELETE {"id": N}                                               — удалить категорию без операций
+func (h *FinanceHandler) Categories(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := h.service.Categories(r.Context(), userID, r.URL.Query().Get("type"))
+		if err != nil {
+			writeCategoryError(w, "Failed to list categories", err)
+			return
+		}
+		if list == nil {
+			list = []finance.Category{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+
+	case http.MethodPost:
+		var req struct {
+			Name     string `json:"name"`
+			Type     string `json:"type"`
+			Icon     string `json:"icon"`
+			ParentID int    `json:"parent_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		c, err := h.service.CreateCategory(r.Context(), userID, req.Name, req.Type, req.Icon, req.ParentID)
+		if err != nil {
+			writeCategoryError(w, "Failed to create category", err)
+			return
+		}
+		h.broadcast("category_added", userID, c)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(c)
+
+	case http.MethodPut:
+		var req struct {
+			ID int `json:"id"`
+			finance.CategoryUpdate
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		c, err := h.service.UpdateCategory(r.Context(), userID, req.ID, req.CategoryUpdate)
+		if err != nil {
+			writeCategoryError(w, "Failed to update category", err)
+			return
+		}
+		h.broadcast("category_updated", userID, c)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(c)
+
+	case http.MethodDelete:
+		var req struct {
+			ID int `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := h.service.DeleteCategory(r.Context(), userID, req.ID); err != nil {
+			writeCategoryError(w, "Failed to delete category", err)
+			return
+		}
+		h.broadcast("category_deleted", userID, map[string]interface{}{"id": req.ID})
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// MergeCategories объединяет две категории: POST {"source_id": N, "target_id": M}.
+// Операции и подкатегории source переходят в target, source удаляется.
+func (h *FinanceHandler) MergeCategories(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		SourceID int `json:"source_id"`
+		TargetID int `json:"target_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	c, err := h.service.MergeCategories(r.Context(), userID, req.SourceID, req.TargetID)
+	if err != nil {
+		writeCategoryError(w, "Failed to merge categories", err)
+		return
+	}
+	h.broadcast("category_merged", userID, map[string]interface{}{"source_id": req.SourceID, "target": c})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// writeCategoryError отвечает 404 для несуществующей категории, 409 для совпадающего имени
+// и категории с операциями, 400 для некорректных данных и 500 для остальных ошибок.
+func writeCategoryError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, finance.ErrCategoryNotFound):
+		http.Error(w, "Category not found", http.StatusNotFound)
+	case errors.Is(err, finance.ErrCategoryExists), errors.Is(err, finance.ErrCategoryInUse):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, finance.ErrCategoryName), errors.Is(err, finance.ErrCategoryType),
+		errors.Is(err, finance.ErrCategoryParent), errors.Is(err, finance.ErrCategoryMerge):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		logger.Error(op + ": " + err.Error())
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"tg_bot_asist/internal/api/middleware"
+	"tg_bot_asist/internal/api/websocket"
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/logger"
+)
+
+// UpdateFinanceRequest — изменение операции; отсутствующие поля не меняются.
+type UpdateFinanceRequest struct {
+	ID       int      `json:"id"`
+	Amount   *float64 `json:"amount"`
+	Category *string  `json:"category"`
+	Type     *string  `json:"type"` // "income" or "expense"
+	Note     *string  `json:"note"`
+	// OccurredAt — новая дата операции: RFC3339 или YYYY-MM-DD.
+	OccurredAt *string `json:"occurred_at"`
+}
+
+// Update изменяет операцию; изменение записывается в историю и рассылается событием finance_updated.
+func (h *FinanceHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req UpdateFinanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	patch := finance.EntryPatch{
+		Amount:   req.Amount,
+		Category: req.Category,
+		Type:     req.Type,
+		Note:     req.Note,
+	}
+	if req.OccurredAt != nil {
+		t, err := parseOccurredAt(*req.OccurredAt)
+		if err != nil {
+			writeOccurredAtError(w, err)
+			return
+		}
+		patch.OccurredAt = &t
+	}
+
+	entry, err := h.service.UpdateEntry(r.Context(), userID, req.ID, patch)
+	if err != nil {
+		writeFinanceError(w, "Failed to update finance entry", err)
+		return
+	}
+
+	h.broadcast("finance_updated", userID, entry)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// Delete удаляет операцию; её последнее состояние остаётся в истории.
+func (h *FinanceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.service.DeleteEntry(r.Context(), userID, req.ID); err != nil {
+		writeFinanceError(w, "Failed to delete finance entry", err)
+		return
+	}
+
+	h.broadcast("finance_deleted", userID, map[string]interface{}{"id": req.ID})
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// History возвращает историю изменений операций: GET [?entry_id=<id>], новые сначала.
+func (h *FinanceHandler) History(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	entryID := 0
+	if v := r.URL.Query().Get("entry_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid entry_id", http.StatusBadRequest)
+			return
+		}
+		entryID = id
+	}
+
+	history, err := h.service.History(r.Context(), userID, entryID)
+	if err != nil {
+		writeFinanceError(w, "Failed to list finance history", err)
+		return
+	}
+	if history == nil {
+		history = []finance.EntryChange{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// broadcast отправляет событие через WebSocket, если hub настроен.
+func (h *FinanceHandler) broadcast(eventType string, userID int64, data interface{}) {
+	if h.hub != nil {
+		h.hub.Broadcast(websocket.NewEvent(eventType, userID, data))
+	}
+}
+
+// writeFinanceError отвечает 404 для несуществующей операции, 400 для некорректных данных и 500 для остальных ошибок.
+func writeFinanceError(w http.ResponseWriter, op string, err error) {
+	if errors.Is(err, finance.ErrNotFound) {
+		http.Error(w, "Finance entry not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, finance.ErrInvalidEntry) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	logger.Error(op + ": " + err.Error())
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+}
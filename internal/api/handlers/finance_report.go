@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"tg_bot_asist/internal/api/middleware"
+	"tg_bot_asist/internal/finance"
+)
+
+// Report возвращает отчёт за период со сравнением с предыдущим периодом:
+//
+//	GET ?from=YYYY-MM-DD&to=YYYY-MM-DD[&tz=<IANA-зона>]           — произвольный период, to включительно
+//	GET ?period=day|week|month|year[&date=YYYY-MM-DD][&tz=...]    — период, в который попадает date (по умолчанию сегодня)
+//
+// Без параметров — текущий месяц.
+func (h *FinanceHandler) Report(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	q := r.URL.Query()
+	loc, err := requestLocation(r, h.users, userID)
+	if err != nil {
+		http.Error(w, "Invalid tz", http.StatusBadRequest)
+		return
+	}
+	period, err := reportPeriod(q.Get("from"), q.Get("to"), q.Get("period"), q.Get("date"), time.Now().In(loc))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.Report(r.Context(), userID, period)
+	if err != nil {
+		if errors.Is(err, finance.ErrInvalidPeriod) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeFinanceError(w, "Failed to build finance report", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// reportPeriod разбирает параметры отчёта; даты — в часовом поясе now.
+func reportPeriod(from, to, kind, date string, now time.Time) (finance.Period, error) {
+	parse := func(v string) (time.Time, error) {
+		t, err := time.ParseInLocation("2006-01-02", v, now.Location())
+		if err != nil {
+			return time.Time{}, errors.New("dates must be YYYY-MM-DD")
+		}
+		return t, nil
+	}
+
+	if from != "" || to != "" {
+		if from == "" || to == "" {
+			return finance.Period{}, errors.New("both from and to are required")
+		}
+		f, err := parse(from)
+		if err != nil {
+			return finance.Period{}, err
+		}
+		t, err := parse(to)
+		if err != nil {
+			return finance.Period{}, err
+		}
+		return finance.CustomPeriod(f, t)
+	}
+
+	anchor := now
+	if date != "" {
+		d, err := parse(date)
+		if err != nil {
+			return finance.Period{}, err
+		}
+		anchor = d
+	}
+	if kind == "" {
+		kind = string(finance.PeriodMonth)
+	}
+	return finance.NewPeriod(finance.PeriodKind(kind), anchor, now)
+}
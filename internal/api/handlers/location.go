@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"tg_bot_asist/internal/config"
+)
+
+// LocationProvider возвращает часовой пояс, сохранённый пользователем (реализуется storage.UserRepo).
+type LocationProvider interface {
+	Location(ctx context.Context, userID int64) *time.Location
+}
+
+// requestLocation возвращает часовой пояс для границ «сегодня», недели и месяца в запросе:
+// query-параметр tz (IANA-зона), иначе часовой пояс из настроек пользователя — тот же, что в боте.
+func requestLocation(r *http.Request, users LocationProvider, userID int64) (*time.Location, error) {
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		return time.LoadLocation(tz)
+	}
+	if users == nil {
+		return config.DefaultLocation(), nil
+	}
+	return users.Location(r.Context(), userID), nil
+}
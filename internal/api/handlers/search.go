@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"tg_bot_asist/internal/api/middleware"
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/search"
+)
+
+type SearchHandler struct {
+	service *search.Service
+}
+
+func NewSearchHandler(service *search.Service) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+// Search ищет по задачам, операциям, регулярным платежам и кредитам: GET ?q=<запрос>.
+// Результаты сгруппированы по модулям и упорядочены по релевантности.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	groups, err := h.service.Search(r.Context(), userID, query)
+	if err != nil {
+		if errors.Is(err, search.ErrEmptyQuery) {
+			http.Error(w, "Query q is required", http.StatusBadRequest)
+			return
+		}
+		logger.Error("Failed to search: " + err.Error())
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if groups == nil {
+		groups = []search.Group{}
+	}
+
+	resp := struct {
+		Query  string         `json:"query"`
+		Total  int            `json:"total"`
+		Groups []search.Group `json:"groups"`
+	}{
+		Query:  query,
+		Total:  search.Total(groups),
+		Groups: groups,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
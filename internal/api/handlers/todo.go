@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"tg_bot_asist/internal/api/middleware"
@@ -13,14 +16,19 @@ import (
 
 type TodoHandler struct {
 	service *todo.Service
+	users   LocationProvider
 	hub     *websocket.Hub
 }
 
-func NewTodoHandler(service *todo.Service, hub *websocket.Hub) *TodoHandler {
-	return &TodoHandler{service: service, hub: hub}
+func NewTodoHandler(service *todo.Service, users LocationProvider, hub *websocket.Hub) *TodoHandler {
+	return &TodoHandler{service: service, users: users, hub: hub}
 }
 
 // List возвращает список задач пользователя.
+// Необязательные query-параметры: tag, priority (low|normal|high|urgent или 1-4),
+// view (overdue|today|week), project_id и tz (IANA-зона для границ «сегодня» и «неделя»;
+// по умолчанию — часовой пояс пользователя).
+// Задачи архивных проектов возвращаются только при явном project_id.
 func (h *TodoHandler) List(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
@@ -28,7 +36,51 @@ func (h *TodoHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	items, err := h.service.List(r.Context(), userID)
+	q := r.URL.Query()
+	var filter todo.ListFilter
+
+	if tag := q.Get("tag"); tag != "" {
+		filter.Tag = todo.NormalizeTag(tag)
+		if filter.Tag == "" {
+			http.Error(w, "Invalid tag", http.StatusBadRequest)
+			return
+		}
+	}
+	if p := q.Get("priority"); p != "" {
+		prio, ok := todo.ParsePriority(p)
+		if !ok {
+			http.Error(w, "Invalid priority", http.StatusBadRequest)
+			return
+		}
+		filter.Priority = prio
+	}
+	if p := q.Get("project_id"); p != "" {
+		id, err := strconv.Atoi(p)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid project_id", http.StatusBadRequest)
+			return
+		}
+		filter.ProjectID = id
+	}
+	view, ok := todo.ParseView(q.Get("view"))
+	if !ok {
+		http.Error(w, "Invalid view, expected overdue, today or week", http.StatusBadRequest)
+		return
+	}
+	filter.View = view
+
+	loc, err := requestLocation(r, h.users, userID)
+	if err != nil {
+		http.Error(w, "Invalid tz", http.StatusBadRequest)
+		return
+	}
+
+	var items []todo.Item
+	if filter == (todo.ListFilter{}) {
+		items, err = h.service.List(r.Context(), userID)
+	} else {
+		items, err = h.service.ListFiltered(r.Context(), userID, filter, loc)
+	}
 	if err != nil {
 		logger.Error("Failed to list todos: " + err.Error())
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -40,9 +92,15 @@ func (h *TodoHandler) List(w http.ResponseWriter, r *http.Request) {
 }
 
 type AddTodoRequest struct {
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	DueDate     *string `json:"due_date,omitempty"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	DueDate      *string  `json:"due_date,omitempty"`
+	Priority     int      `json:"priority,omitempty"` // 1-4, по умолчанию обычный
+	Tags         []string `json:"tags,omitempty"`
+	RRule        string   `json:"rrule,omitempty"` // правило повторения RFC 5545
+	ParentID     *int     `json:"parent_id,omitempty"`
+	ProjectID    int      `json:"project_id,omitempty"`    // по умолчанию Inbox
+	AutoComplete bool     `json:"auto_complete,omitempty"` // выполнить задачу, когда выполнены все пункты чек-листа и подзадачи
 }
 
 // Add создаёт новую задачу.
@@ -77,10 +135,25 @@ func (h *TodoHandler) Add(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	err := h.service.Add(r.Context(), userID, req.Title, req.Description, dueDate)
-	if err != nil {
-		logger.Error("Failed to add todo: " + err.Error())
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	if req.Priority != 0 && !todo.Priority(req.Priority).Valid() {
+		http.Error(w, "Invalid priority", http.StatusBadRequest)
+		return
+	}
+
+	item := &todo.Item{
+		UserID:       userID,
+		Title:        req.Title,
+		Description:  req.Description,
+		DueDate:      dueDate,
+		Priority:     todo.Priority(req.Priority),
+		Tags:         req.Tags,
+		RRule:        req.RRule,
+		ParentID:     req.ParentID,
+		ProjectID:    req.ProjectID,
+		AutoComplete: req.AutoComplete,
+	}
+	if err := h.service.Create(r.Context(), item); err != nil {
+		writeTodoError(w, "Failed to add todo", err)
 		return
 	}
 
@@ -121,10 +194,294 @@ func (h *TodoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	err := h.service.Delete(r.Context(), userID, req.ID)
 	if err != nil {
-		logger.Error("Failed to delete todo: " + err.Error())
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeTodoError(w, "Failed to delete todo", err)
 		return
 	}
 
+	h.broadcast("todo_deleted", userID, map[string]interface{}{"id": req.ID})
+
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
+
+// Complete отмечает задачу выполненной.
+// Для повторяющейся задачи следующее вхождение рассылается событием todo_added,
+// для подзадачи родительская задача (с новым прогрессом) — событием todo_updated.
+func (h *TodoHandler) Complete(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "todo_completed", func(ctx context.Context, userID int64, id int) (*todo.Item, error) {
+		item, next, err := h.service.CompleteWithNext(ctx, userID, id)
+		if err != nil && item != nil {
+			// задача выполнена, не удалось создать следующее вхождение или выполнить родительскую задачу
+			logger.Error("Failed to finish todo completion: " + err.Error())
+			err = nil
+		}
+		if next != nil {
+			h.broadcast("todo_added", userID, next)
+		}
+		if item != nil && item.ParentID != nil {
+			h.broadcastTodo(ctx, userID, *item.ParentID)
+		}
+		return item, err
+	})
+}
+
+// Reopen возвращает выполненную задачу в работу.
+func (h *TodoHandler) Reopen(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "todo_reopened", h.service.Reopen)
+}
+
+// changeStatus — общая обработка запросов вида {"id": N}, меняющих статус задачи.
+func (h *TodoHandler) changeStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	eventType string,
+	change func(ctx context.Context, userID int64, id int) (*todo.Item, error),
+) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	item, err := change(r.Context(), userID, req.ID)
+	if err != nil {
+		writeTodoError(w, "Failed to change todo status", err)
+		return
+	}
+
+	h.broadcast(eventType, userID, item)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+type UpdateTodoRequest struct {
+	ID           int       `json:"id"`
+	Title        *string   `json:"title,omitempty"`
+	Description  *string   `json:"description,omitempty"`
+	DueDate      *string   `json:"due_date,omitempty"` // RFC3339; пустая строка снимает срок
+	Priority     *int      `json:"priority,omitempty"`
+	Tags         *[]string `json:"tags,omitempty"`  // заменяет все метки; пустой список снимает их
+	RRule        *string   `json:"rrule,omitempty"` // правило повторения; пустая строка отключает повтор
+	AutoComplete *bool     `json:"auto_complete,omitempty"`
+	ProjectID    *int      `json:"project_id,omitempty"` // перенос задачи (с подзадачами) в проект
+}
+
+// Update изменяет заголовок, описание, срок, приоритет, метки, правило повторения, проект и автовыполнение задачи.
+// Отсутствующие поля не меняются. Все поля проверяются до сохранения: при ошибке задача остаётся прежней.
+func (h *TodoHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req UpdateTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Title != nil && *req.Title == "" {
+		http.Error(w, "Title must not be empty", http.StatusBadRequest)
+		return
+	}
+
+	patch := todo.Patch{
+		Title:        req.Title,
+		Description:  req.Description,
+		Tags:         req.Tags,
+		RRule:        req.RRule,
+		AutoComplete: req.AutoComplete,
+		ProjectID:    req.ProjectID,
+	}
+	if req.DueDate != nil {
+		if *req.DueDate == "" {
+			patch.ClearDue = true
+		} else {
+			parsed, err := time.Parse(time.RFC3339, *req.DueDate)
+			if err != nil {
+				http.Error(w, "Invalid due_date, RFC3339 expected", http.StatusBadRequest)
+				return
+			}
+			patch.DueDate = &parsed
+		}
+	}
+	if req.Priority != nil {
+		p := todo.Priority(*req.Priority)
+		patch.Priority = &p
+	}
+
+	item, err := h.service.Edit(r.Context(), userID, req.ID, patch)
+	if err != nil {
+		writeTodoError(w, "Failed to update todo", err)
+		return
+	}
+
+	h.broadcast("todo_updated", userID, item)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// ChecklistAdd добавляет пункт в чек-лист задачи: {"todo_id": N, "title": "..."}.
+// Возвращает задачу с обновлённым чек-листом и прогрессом.
+func (h *TodoHandler) ChecklistAdd(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		TodoID int    `json:"todo_id"`
+		Title  string `json:"title"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Title == "" {
+		http.Error(w, "Title is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.service.AddChecklistItem(r.Context(), userID, req.TodoID, req.Title); err != nil {
+		writeTodoError(w, "Failed to add checklist item", err)
+		return
+	}
+	h.respondTodo(r.Context(), w, userID, req.TodoID)
+}
+
+// ChecklistToggle отмечает пункт чек-листа или снимает отметку: {"id": N}.
+// Отметка последнего пункта может выполнить задачу с включённым auto_complete.
+func (h *TodoHandler) ChecklistToggle(w http.ResponseWriter, r *http.Request) {
+	h.changeChecklist(w, r, func(ctx context.Context, userID int64, id int) (*todo.Item, error) {
+		_, item, err := h.service.ToggleChecklistItem(ctx, userID, id)
+		return item, err
+	})
+}
+
+// ChecklistDelete удаляет пункт чек-листа: {"id": N}.
+func (h *TodoHandler) ChecklistDelete(w http.ResponseWriter, r *http.Request) {
+	h.changeChecklist(w, r, h.service.DeleteChecklistItem)
+}
+
+// changeChecklist — общая обработка запросов {"id": N} к пунктам чек-листа.
+// Отвечает задачей после изменения и рассылает её событием todo_updated.
+func (h *TodoHandler) changeChecklist(
+	w http.ResponseWriter,
+	r *http.Request,
+	change func(ctx context.Context, userID int64, id int) (*todo.Item, error),
+) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	item, err := change(r.Context(), userID, req.ID)
+	if err != nil {
+		writeTodoError(w, "Failed to change checklist item", err)
+		return
+	}
+
+	h.broadcast("todo_updated", userID, item)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// respondTodo отвечает актуальным состоянием задачи и рассылает его событием todo_updated.
+func (h *TodoHandler) respondTodo(ctx context.Context, w http.ResponseWriter, userID int64, id int) {
+	item, err := h.service.Get(ctx, userID, id)
+	if err != nil {
+		writeTodoError(w, "Failed to get todo", err)
+		return
+	}
+
+	h.broadcast("todo_updated", userID, item)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// broadcastTodo рассылает актуальное состояние задачи событием todo_updated.
+func (h *TodoHandler) broadcastTodo(ctx context.Context, userID int64, id int) {
+	item, err := h.service.Get(ctx, userID, id)
+	if err != nil {
+		logger.Error("Failed to get todo for broadcast: " + err.Error())
+		return
+	}
+	h.broadcast("todo_updated", userID, item)
+}
+
+// broadcast отправляет событие через WebSocket, если hub настроен.
+func (h *TodoHandler) broadcast(eventType string, userID int64, data interface{}) {
+	if h.hub != nil {
+		h.hub.Broadcast(websocket.NewEvent(eventType, userID, data))
+	}
+}
+
+// writeTodoError отвечает 404 для несуществующей задачи, 403 при нехватке прав в общем проекте,
+// 400 для некорректных данных и 500 для остальных ошибок.
+func writeTodoError(w http.ResponseWriter, op string, err error) {
+	if errors.Is(err, todo.ErrNotFound) {
+		http.Error(w, "Todo not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, todo.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, todo.ErrChecklistItemNotFound) {
+		http.Error(w, "Checklist item not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, todo.ErrProjectNotFound) {
+		http.Error(w, "Project not found", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, todo.ErrInvalidPriority) || errors.Is(err, todo.ErrInvalidRecurrence) || errors.Is(err, todo.ErrInvalidParent) ||
+		errors.Is(err, todo.ErrInvalidStatus) || errors.Is(err, todo.ErrInvalidColumns) || errors.Is(err, todo.ErrInvalidStatsPeriod) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	logger.Error(op + ": " + err.Error())
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+}
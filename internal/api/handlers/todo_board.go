@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"tg_bot_asist/internal/api/middleware"
+	"tg_bot_asist/internal/todo"
+)
+
+// Board возвращает канбан-доску: колонки с задачами верхнего уровня в порядке position.
+// Необязательный query-параметр project_id ограничивает доску проектом.
+func (h *TodoHandler) Board(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	projectID := 0
+	if v := r.URL.Query().Get("project_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid project_id", http.StatusBadRequest)
+			return
+		}
+		projectID = id
+	}
+
+	lanes, err := h.service.Board(r.Context(), userID, projectID)
+	if err != nil {
+		writeProjectError(w, "Failed to build board", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"columns": lanes})
+}
+
+// BoardColumns — настройка колонок доски на пути /api/todo/board/columns:
+//
+//	GET                                              — колонки (по умолчанию, если не настроены)
+//	PUT [{"status": "pending", "name": "..."}, ...]  — заменить колонки; порядок массива — порядок колонок
+func (h *TodoHandler) BoardColumns(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var (
+		cols []todo.BoardColumn
+		err  error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		cols, err = h.service.Columns(r.Context(), userID)
+	case http.MethodPut:
+		var req []todo.BoardColumn
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		cols, err = h.service.SetColumns(r.Context(), userID, req)
+		if err == nil {
+			h.broadcast("board_columns_updated", userID, cols)
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		writeTodoError(w, "Failed to save board columns", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cols)
+}
+
+// MoveTodoRequest — перемещение задачи на доске.
+type MoveTodoRequest struct {
+	ID        int    `json:"id"`
+	Status    string `json:"status"`     // статус колонки, в которую перемещается задача
+	Position  int    `json:"position"`   // место в колонке, с нуля
+	ProjectID int    `json:"project_id"` // проект доски, 0 — доска всех проектов
+}
+
+// Move перемещает задачу на доске: меняет статус и место в колонке в одной транзакции
+// и рассылает событие todo_moved.
+func (h *TodoHandler) Move(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req MoveTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.service.MoveOnBoard(r.Context(), userID, todo.BoardMove{
+		TodoID:    req.ID,
+		Status:    req.Status,
+		Position:  req.Position,
+		ProjectID: req.ProjectID,
+	})
+	if err != nil {
+		writeTodoError(w, "Failed to move todo", err)
+		return
+	}
+
+	h.broadcast(todo.EventTodoMoved, userID, item)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
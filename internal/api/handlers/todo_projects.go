@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"tg_bot_asist/internal/api/middleware"
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+)
+
+// Projects — CRUD проектов задач на одном пути /api/todo/projects:
+//
+//	GET    [?archived=1]         — список проектов
+//	POST   {"name": "..."}       — создать
+//	PUT    {"id": N, "name": ..., "position": ..., "sort_order": ..., "archived": ...}
+//	                             — изменить (отсутствующие поля не меняются)
+//	DELETE {"id": N}             — удалить, задачи переходят в Inbox
+func (h *TodoHandler) Projects(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := h.service.Projects(r.Context(), userID, r.URL.Query().Get("archived") == "1")
+		if err != nil {
+			writeProjectError(w, "Failed to list projects", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+
+	case http.MethodPost:
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		p, err := h.service.CreateProject(r.Context(), userID, req.Name)
+		if err != nil {
+			writeProjectError(w, "Failed to create project", err)
+			return
+		}
+		h.broadcast("project_added", userID, p)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(p)
+
+	case http.MethodPut:
+		var req struct {
+			ID int `json:"id"`
+			todo.ProjectUpdate
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		p, err := h.service.UpdateProject(r.Context(), userID, req.ID, req.ProjectUpdate)
+		if err != nil {
+			writeProjectError(w, "Failed to update project", err)
+			return
+		}
+		h.broadcast("project_updated", userID, p)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+
+	case http.MethodDelete:
+		var req struct {
+			ID int `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := h.service.DeleteProject(r.Context(), userID, req.ID); err != nil {
+			writeProjectError(w, "Failed to delete project", err)
+			return
+		}
+		h.broadcast("project_deleted", userID, map[string]interface{}{"id": req.ID})
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// writeProjectError отвечает 404 для несуществующего проекта, приглашения или участника, 403 при нехватке прав,
+// 409 для совпадающего имени и повторного вступления, 400 для некорректных данных и 500 для остальных ошибок.
+func writeProjectError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, todo.ErrProjectNotFound):
+		http.Error(w, "Project not found", http.StatusNotFound)
+	case errors.Is(err, todo.ErrInviteNotFound), errors.Is(err, todo.ErrNotMember):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, todo.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, todo.ErrProjectExists), errors.Is(err, todo.ErrAlreadyMember):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, todo.ErrProjectName), errors.Is(err, todo.ErrInboxProject), errors.Is(err, todo.ErrInvalidSortOrder),
+		errors.Is(err, todo.ErrInboxShared):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		logger.Error(op + ": " + err.Error())
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tg_bot_asist/internal/api/middleware"
+	"tg_bot_asist/internal/todo"
+)
+
+// ProjectInvite создаёт одноразовое приглашение в проект: POST {"project_id": N, "role": "view"|"edit"}.
+// В ответе start_param — параметр для ссылки https://t.me/<бот>?start=<start_param>.
+func (h *TodoHandler) ProjectInvite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		ProjectID int       `json:"project_id"`
+		Role      todo.Role `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if _, ok := todo.ParseRole(string(req.Role)); !ok {
+		http.Error(w, "role must be view or edit", http.StatusBadRequest)
+		return
+	}
+
+	inv, err := h.service.Invite(r.Context(), userID, req.ProjectID, req.Role)
+	if err != nil {
+		writeProjectError(w, "Failed to create invite", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		Token      string    `json:"token"`
+		StartParam string    `json:"start_param"`
+		Role       todo.Role `json:"role"`
+		ExpiresAt  time.Time `json:"expires_at"`
+	}{inv.Token, "join_" + inv.Token, inv.Role, inv.ExpiresAt})
+}
+
+// ProjectJoin принимает приглашение: POST {"token": "..."}. Возвращает проект.
+func (h *TodoHandler) ProjectJoin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.service.Join(r.Context(), userID, req.Token)
+	if err != nil {
+		writeProjectError(w, "Failed to join project", err)
+		return
+	}
+	h.broadcast("project_added", userID, p)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// ProjectMembers — участники проекта на одном пути /api/todo/projects/members:
+//
+//	GET    ?project_id=N                 — список участников (без владельца)
+//	DELETE {"project_id": N, "user_id": M} — исключить участника (владелец) или выйти из проекта (сам участник)
+func (h *TodoHandler) ProjectMembers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		projectID, err := strconv.Atoi(r.URL.Query().Get("project_id"))
+		if err != nil {
+			http.Error(w, "Invalid project_id", http.StatusBadRequest)
+			return
+		}
+		list, err := h.service.Members(r.Context(), userID, projectID)
+		if err != nil {
+			writeProjectError(w, "Failed to list members", err)
+			return
+		}
+		if list == nil {
+			list = []todo.Member{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+
+	case http.MethodDelete:
+		var req struct {
+			ProjectID int   `json:"project_id"`
+			UserID    int64 `json:"user_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := h.service.RemoveMember(r.Context(), userID, req.ProjectID, req.UserID); err != nil {
+			writeProjectError(w, "Failed to remove member", err)
+			return
+		}
+		// у исключённого участника проект пропадает из списка
+		h.broadcast("project_deleted", req.UserID, map[string]interface{}{"id": req.ProjectID})
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tg_bot_asist/internal/api/middleware"
+	"tg_bot_asist/internal/todo"
+)
+
+// Stats возвращает статистику выполнения задач: GET ?days=<1..365>&tz=<IANA-зона>.
+// По умолчанию — последние 28 дней в часовом поясе по умолчанию.
+func (h *TodoHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	q := r.URL.Query()
+	loc, err := requestLocation(r, h.users, userID)
+	if err != nil {
+		http.Error(w, "Invalid tz", http.StatusBadRequest)
+		return
+	}
+	days := todo.DefaultStatsDays
+	if v := q.Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid days", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	stats, err := h.service.Stats(r.Context(), userID, days, loc)
+	if err != nil {
+		writeTodoError(w, "Failed to build todo stats", err)
+		return
+	}
+
+	resp := struct {
+		todo.Stats
+		MedianCompletion int64   `json:"median_completion_seconds"`
+		OverdueRate      float64 `json:"overdue_rate"`
+	}{
+		Stats:            stats,
+		MedianCompletion: int64(stats.MedianCompletion / time.Second),
+		OverdueRate:      stats.OverdueRate(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
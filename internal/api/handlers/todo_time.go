@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/api/middleware"
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+)
+
+// timeGroupJSON — группа отчёта о времени в ответе API.
+type timeGroupJSON struct {
+	Name    string `json:"name"`
+	Seconds int64  `json:"seconds"`
+}
+
+// timeRowJSON — время по задаче в ответе API.
+type timeRowJSON struct {
+	TodoID  int      `json:"todo_id"`
+	Title   string   `json:"title"`
+	Project string   `json:"project"`
+	Tags    []string `json:"tags"`
+	Seconds int64    `json:"seconds"`
+}
+
+// TimeReport возвращает отчёт о затраченном времени по проектам, меткам и задачам.
+// Период: from и to (ГГГГ-ММ-ДД, оба дня включительно) или week (0 — текущая неделя, -1 — прошлая; по умолчанию 0).
+// tz — IANA-зона для границ дней (по умолчанию — часовой пояс пользователя). format=csv — CSV по задачам вместо JSON.
+func (h *TodoHandler) TimeReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	q := r.URL.Query()
+	loc, err := requestLocation(r, h.users, userID)
+	if err != nil {
+		http.Error(w, "Invalid tz", http.StatusBadRequest)
+		return
+	}
+
+	var from, to time.Time
+	if q.Get("from") != "" || q.Get("to") != "" {
+		var err error
+		if from, err = time.ParseInLocation("2006-01-02", q.Get("from"), loc); err != nil {
+			http.Error(w, "Invalid from, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		if to, err = time.ParseInLocation("2006-01-02", q.Get("to"), loc); err != nil {
+			http.Error(w, "Invalid to, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+		if !to.After(from) {
+			http.Error(w, "from must not be after to", http.StatusBadRequest)
+			return
+		}
+	} else {
+		week := 0
+		if v := q.Get("week"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n > 0 {
+				http.Error(w, "Invalid week, expected 0 or a negative offset", http.StatusBadRequest)
+				return
+			}
+			week = n
+		}
+		from, to = todo.WeekRange(time.Now(), loc, week)
+	}
+
+	report, err := h.service.TimeReport(r.Context(), userID, from, to)
+	if err != nil {
+		logger.Error("Failed to build time report: " + err.Error())
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if q.Get("format") == "csv" {
+		writeTimeReportCSV(w, report)
+		return
+	}
+
+	resp := struct {
+		From      time.Time       `json:"from"`
+		To        time.Time       `json:"to"`
+		Total     int64           `json:"total_seconds"`
+		ByProject []timeGroupJSON `json:"by_project"`
+		ByTag     []timeGroupJSON `json:"by_tag"`
+		Todos     []timeRowJSON   `json:"todos"`
+	}{
+		From:      report.From,
+		To:        report.To,
+		Total:     int64(report.Total / time.Second),
+		ByProject: timeGroups(report.ByProject),
+		ByTag:     timeGroups(report.ByTag),
+		Todos:     make([]timeRowJSON, 0, len(report.Todos)),
+	}
+	for _, row := range report.Todos {
+		resp.Todos = append(resp.Todos, timeRowJSON{
+			TodoID:  row.TodoID,
+			Title:   row.Title,
+			Project: row.Project,
+			Tags:    row.Tags,
+			Seconds: int64(row.Duration / time.Second),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// timeGroups переводит группы отчёта в формат ответа API.
+func timeGroups(groups []todo.TimeGroup) []timeGroupJSON {
+	out := make([]timeGroupJSON, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, timeGroupJSON{Name: g.Name, Seconds: int64(g.Duration / time.Second)})
+	}
+	return out
+}
+
+// writeTimeReportCSV отдаёт отчёт CSV-файлом: строка на задачу, время в часах с двумя знаками.
+func writeTimeReportCSV(w http.ResponseWriter, report todo.TimeReport) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="time-%s.csv"`, report.From.Format("2006-01-02")))
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"todo_id", "title", "project", "tags", "hours"})
+	for _, row := range report.Todos {
+		cw.Write([]string{
+			strconv.Itoa(row.TodoID),
+			row.Title,
+			row.Project,
+			strings.Join(row.Tags, " "),
+			strconv.FormatFloat(row.Duration.Hours(), 'f', 2, 64),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		logger.Error("Failed to write time report CSV: " + err.Error())
+	}
+}
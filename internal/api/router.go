@@ -8,9 +8,12 @@ import (
 	"tg_bot_asist/internal/api/handlers"
 	"tg_bot_asist/internal/api/middleware"
 	"tg_bot_asist/internal/api/websocket"
+	"tg_bot_asist/internal/attachment"
+	"tg_bot_asist/internal/calendar"
 	"tg_bot_asist/internal/credits"
 	"tg_bot_asist/internal/finance"
 	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/search"
 	"tg_bot_asist/internal/storage"
 	"tg_bot_asist/internal/todo"
 
@@ -18,11 +21,14 @@ import (
 )
 
 type Router struct {
-	authHandler    *handlers.AuthHandler
-	todoHandler    *handlers.TodoHandler
-	financeHandler *handlers.FinanceHandler
-	creditsHandler *handlers.CreditsHandler
-	hub            *websocket.Hub
+	authHandler       *handlers.AuthHandler
+	todoHandler       *handlers.TodoHandler
+	financeHandler    *handlers.FinanceHandler
+	creditsHandler    *handlers.CreditsHandler
+	calendarHandler   *handlers.CalendarHandler
+	searchHandler     *handlers.SearchHandler
+	attachmentHandler *handlers.AttachmentHandler
+	hub               *websocket.Hub
 }
 
 // NewRouter создаёт новый роутер API.
@@ -31,14 +37,20 @@ func NewRouter(
 	todoService *todo.Service,
 	financeService *finance.Service,
 	creditsService *credits.Service,
+	calendarService *calendar.Service,
+	searchService *search.Service,
+	attachmentService *attachment.Service,
 	hub *websocket.Hub,
 ) *Router {
 	return &Router{
-		authHandler:    handlers.NewAuthHandler(userRepo),
-		todoHandler:    handlers.NewTodoHandler(todoService, hub),
-		financeHandler: handlers.NewFinanceHandler(financeService, hub),
-		creditsHandler: handlers.NewCreditsHandler(creditsService, hub),
-		hub:            hub,
+		authHandler:       handlers.NewAuthHandler(userRepo),
+		todoHandler:       handlers.NewTodoHandler(todoService, userRepo, hub),
+		financeHandler:    handlers.NewFinanceHandler(financeService, userRepo, hub),
+		creditsHandler:    handlers.NewCreditsHandler(creditsService, hub),
+		calendarHandler:   handlers.NewCalendarHandler(calendarService),
+		searchHandler:     handlers.NewSearchHandler(searchService),
+		attachmentHandler: handlers.NewAttachmentHandler(attachmentService),
+		hub:               hub,
 	}
 }
 
@@ -71,10 +83,32 @@ func (r *Router) SetupRoutes() http.Handler {
 	mux.Handle("/api/todo/list", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.List)))
 	mux.Handle("/api/todo/add", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.Add)))
 	mux.Handle("/api/todo/delete", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.Delete)))
+	mux.Handle("/api/todo/update", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.Update)))
+	mux.Handle("/api/todo/complete", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.Complete)))
+	mux.Handle("/api/todo/reopen", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.Reopen)))
+	mux.Handle("/api/todo/projects", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.Projects)))
+	mux.Handle("/api/todo/projects/invite", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.ProjectInvite)))
+	mux.Handle("/api/todo/projects/join", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.ProjectJoin)))
+	mux.Handle("/api/todo/projects/members", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.ProjectMembers)))
+	mux.Handle("/api/todo/checklist/add", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.ChecklistAdd)))
+	mux.Handle("/api/todo/checklist/toggle", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.ChecklistToggle)))
+	mux.Handle("/api/todo/checklist/delete", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.ChecklistDelete)))
+	mux.Handle("/api/todo/stats", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.Stats)))
+	mux.Handle("/api/todo/time-report", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.TimeReport)))
+	mux.Handle("/api/todo/board", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.Board)))
+	mux.Handle("/api/todo/board/columns", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.BoardColumns)))
+	mux.Handle("/api/todo/move", middleware.JWTAuthMiddleware(http.HandlerFunc(r.todoHandler.Move)))
 
 	// Finance routes
 	mux.Handle("/api/finance/list", middleware.JWTAuthMiddleware(http.HandlerFunc(r.financeHandler.List)))
 	mux.Handle("/api/finance/add", middleware.JWTAuthMiddleware(http.HandlerFunc(r.financeHandler.Add)))
+	mux.Handle("/api/finance/update", middleware.JWTAuthMiddleware(http.HandlerFunc(r.financeHandler.Update)))
+	mux.Handle("/api/finance/delete", middleware.JWTAuthMiddleware(http.HandlerFunc(r.financeHandler.Delete)))
+	mux.Handle("/api/finance/history", middleware.JWTAuthMiddleware(http.HandlerFunc(r.financeHandler.History)))
+	mux.Handle("/api/finance/categories", middleware.JWTAuthMiddleware(http.HandlerFunc(r.financeHandler.Categories)))
+	mux.Handle("/api/finance/categories/merge", middleware.JWTAuthMiddleware(http.HandlerFunc(r.financeHandler.MergeCategories)))
+	mux.Handle("/api/finance/budgets", middleware.JWTAuthMiddleware(http.HandlerFunc(r.financeHandler.Budgets)))
+	mux.Handle("/api/finance/report", middleware.JWTAuthMiddleware(http.HandlerFunc(r.financeHandler.Report)))
 	mux.Handle("/api/finance/stats", middleware.JWTAuthMiddleware(http.HandlerFunc(r.financeHandler.Stats)))
 	mux.Handle("/api/finance/recurring/add", middleware.JWTAuthMiddleware(http.HandlerFunc(r.financeHandler.AddRecurring)))
 
@@ -84,6 +118,17 @@ func (r *Router) SetupRoutes() http.Handler {
 	mux.Handle("/api/credits/close", middleware.JWTAuthMiddleware(http.HandlerFunc(r.creditsHandler.Close)))
 	mux.Handle("/api/credits/schedule", middleware.JWTAuthMiddleware(http.HandlerFunc(r.creditsHandler.Schedule)))
 
+	// Calendar routes: подписка авторизуется токеном в ссылке, а не JWT
+	mux.HandleFunc(calendar.FeedPath, r.calendarHandler.Feed)
+	mux.Handle("/api/calendar/token", middleware.JWTAuthMiddleware(http.HandlerFunc(r.calendarHandler.Token)))
+
+	// Search routes
+	mux.Handle("/api/search", middleware.JWTAuthMiddleware(http.HandlerFunc(r.searchHandler.Search)))
+
+	// Attachment routes
+	mux.Handle("/api/attachments", middleware.JWTAuthMiddleware(http.HandlerFunc(r.attachmentHandler.List)))
+	mux.Handle("/api/attachments/download", middleware.JWTAuthMiddleware(http.HandlerFunc(r.attachmentHandler.Download)))
+
 	// WebSocket
 	mux.HandleFunc("/ws", r.handleWebSocket)
 
@@ -6,8 +6,9 @@ import (
 	"sync"
 	"time"
 
+	"tg_bot_asist/internal/logger"
+
 	"golang.org/x/net/websocket"
-	
 )
 
 // Event представляет событие синхронизации.
@@ -123,6 +124,11 @@ func (h *Hub) Broadcast(event Event) {
 	}
 }
 
+// Publish отправляет пользователю событие eventType с данными data.
+func (h *Hub) Publish(userID int64, eventType string, data interface{}) {
+	h.Broadcast(NewEvent(eventType, userID, data))
+}
+
 // HandleWebSocket обрабатывает WebSocket соединение.
 func (h *Hub) HandleWebSocket(ws *websocket.Conn, userID int64) {
 	client := &Client{
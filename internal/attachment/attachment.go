@@ -0,0 +1,230 @@
+// Package attachment хранит файлы Telegram (фото чеков, документы, голосовые заметки),
+// прикреплённые к задачам и финансовым операциям. Сами файлы остаются в Telegram,
+// в базе хранится только file_id; веб-приложение скачивает их через API.
+package attachment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/todo"
+)
+
+// Owner — тип записи, к которой прикреплён файл.
+type Owner string
+
+const (
+	OwnerTodo    Owner = "todo"
+	OwnerFinance Owner = "finance"
+)
+
+// ParseOwner разбирает тип записи.
+func ParseOwner(s string) (Owner, bool) {
+	switch o := Owner(s); o {
+	case OwnerTodo, OwnerFinance:
+		return o, true
+	}
+	return "", false
+}
+
+// Kind — вид файла Telegram.
+type Kind string
+
+const (
+	KindPhoto    Kind = "photo"
+	KindDocument Kind = "document"
+	KindVoice    Kind = "voice"
+	KindAudio    Kind = "audio"
+	KindVideo    Kind = "video"
+)
+
+// MaxPerItem — наибольшее число вложений одной записи.
+const MaxPerItem = 20
+
+// Ошибки вложений.
+var (
+	ErrNotFound  = errors.New("вложение не найдено")
+	ErrDuplicate = errors.New("файл уже прикреплён")
+	ErrTooMany   = errors.New("слишком много вложений")
+	ErrOwner     = errors.New("неизвестный тип записи")
+)
+
+// File — файл Telegram.
+type File struct {
+	Kind         Kind   `json:"kind"`
+	FileID       string `json:"-"`
+	FileUniqueID string `json:"-"`
+	FileName     string `json:"file_name"`
+	MimeType     string `json:"mime_type"`
+	Size         int64  `json:"size"`
+}
+
+// Attachment — файл, прикреплённый к задаче или операции.
+type Attachment struct {
+	ID        int       `json:"id"`
+	UserID    int64     `json:"user_id"` // кто прикрепил
+	Owner     Owner     `json:"owner"`
+	OwnerID   int       `json:"owner_id"`
+	CreatedAt time.Time `json:"created_at"`
+	File
+}
+
+// Repository хранит вложения.
+type Repository interface {
+	// Add сохраняет вложение и заполняет его ID и CreatedAt (ErrDuplicate, если файл уже прикреплён к записи).
+	Add(ctx context.Context, a *Attachment) error
+	// List возвращает вложения записи в порядке добавления.
+	List(ctx context.Context, owner Owner, ownerID int) ([]Attachment, error)
+	// Count возвращает число вложений записи.
+	Count(ctx context.Context, owner Owner, ownerID int) (int, error)
+	// Get возвращает вложение по ID (ErrNotFound, если его нет).
+	Get(ctx context.Context, id int) (*Attachment, error)
+	// Delete удаляет вложение.
+	Delete(ctx context.Context, id int) error
+}
+
+// FileLinker выдаёт временную ссылку на скачивание файла Telegram (реализуется tgbotapi.BotAPI).
+type FileLinker interface {
+	GetFileDirectURL(fileID string) (string, error)
+}
+
+// TodoAccess проверяет доступ к задачам (реализуется todo.Service).
+type TodoAccess interface {
+	Get(ctx context.Context, userID int64, id int) (*todo.Item, error)
+	GetForEdit(ctx context.Context, userID int64, id int) (*todo.Item, error)
+}
+
+// EntryAccess проверяет доступ к финансовым операциям (реализуется finance.Service).
+type EntryAccess interface {
+	GetEntry(ctx context.Context, userID int64, id int) (*finance.FinanceEntry, error)
+}
+
+// Service проверяет доступ к записям и управляет их вложениями.
+type Service struct {
+	repo    Repository
+	todos   TodoAccess
+	finance EntryAccess
+	files   FileLinker
+	client  *http.Client
+}
+
+func NewService(repo Repository, todos TodoAccess, fin EntryAccess, files FileLinker) *Service {
+	return &Service{repo: repo, todos: todos, finance: fin, files: files, client: &http.Client{Timeout: time.Minute}}
+}
+
+// checkAccess проверяет, что пользователь видит запись (а при edit — может её изменять).
+// Задачи общих проектов доступны участникам по их роли, операции — только владельцу.
+func (s *Service) checkAccess(ctx context.Context, userID int64, owner Owner, ownerID int, edit bool) error {
+	var err error
+	switch owner {
+	case OwnerTodo:
+		if edit {
+			_, err = s.todos.GetForEdit(ctx, userID, ownerID)
+		} else {
+			_, err = s.todos.Get(ctx, userID, ownerID)
+		}
+	case OwnerFinance:
+		_, err = s.finance.GetEntry(ctx, userID, ownerID)
+	default:
+		return ErrOwner
+	}
+	return err
+}
+
+// Attach прикрепляет файл к записи, которую пользователь может изменять.
+func (s *Service) Attach(ctx context.Context, userID int64, owner Owner, ownerID int, f File) (*Attachment, error) {
+	if err := s.checkAccess(ctx, userID, owner, ownerID, true); err != nil {
+		return nil, err
+	}
+	n, err := s.repo.Count(ctx, owner, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if n >= MaxPerItem {
+		return nil, ErrTooMany
+	}
+
+	a := &Attachment{UserID: userID, Owner: owner, OwnerID: ownerID, File: f}
+	if err := s.repo.Add(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// List возвращает вложения записи, доступной пользователю.
+func (s *Service) List(ctx context.Context, userID int64, owner Owner, ownerID int) ([]Attachment, error) {
+	if err := s.checkAccess(ctx, userID, owner, ownerID, false); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, owner, ownerID)
+}
+
+// Get возвращает вложение, если пользователю доступна его запись.
+func (s *Service) Get(ctx context.Context, userID int64, id int) (*Attachment, error) {
+	a, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAccess(ctx, userID, a.Owner, a.OwnerID, false); err != nil {
+		if errors.Is(err, todo.ErrNotFound) || errors.Is(err, finance.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+// Delete удаляет вложение записи, которую пользователь может изменять.
+func (s *Service) Delete(ctx context.Context, userID int64, id int) error {
+	a, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkAccess(ctx, userID, a.Owner, a.OwnerID, true); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Open открывает содержимое вложения из Telegram. Вызывающий закрывает возвращённый поток.
+// Bot API отдаёт файлы размером до 20 МБ.
+func (s *Service) Open(ctx context.Context, userID int64, id int) (*Attachment, io.ReadCloser, error) {
+	a, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	link, err := s.files.GetFileDirectURL(a.FileID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("telegram getFile: %w", stripURL(err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("telegram file download: %w", stripURL(err))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("telegram file download: %w", stripURL(err))
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, nil, fmt.Errorf("telegram file download: status %d", resp.StatusCode)
+	}
+	return a, resp.Body, nil
+}
+
+// stripURL убирает из ошибки запроса адрес: ссылка на файл Telegram содержит токен бота
+// и не должна попадать в логи.
+func stripURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,151 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/todo"
+)
+
+// fakeTodos — задача 1 доступна пользователю 1 (владелец) на изменение, пользователю 2 — только на просмотр.
+type fakeTodos struct{}
+
+func (fakeTodos) Get(_ context.Context, userID int64, id int) (*todo.Item, error) {
+	if id != 1 || (userID != 1 && userID != 2) {
+		return nil, todo.ErrNotFound
+	}
+	return &todo.Item{ID: id, UserID: 1, ProjectID: 10}, nil
+}
+
+func (f fakeTodos) GetForEdit(ctx context.Context, userID int64, id int) (*todo.Item, error) {
+	item, err := f.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if userID != 1 {
+		return nil, todo.ErrForbidden
+	}
+	return item, nil
+}
+
+type fakeEntries struct{}
+
+func (fakeEntries) GetEntry(_ context.Context, userID int64, id int) (*finance.FinanceEntry, error) {
+	if id != 1 || userID != 1 {
+		return nil, finance.ErrNotFound
+	}
+	return &finance.FinanceEntry{ID: id, UserID: userID}, nil
+}
+
+type fakeRepo struct {
+	items  map[int]*Attachment
+	nextID int
+}
+
+func (r *fakeRepo) Add(_ context.Context, a *Attachment) error {
+	r.nextID++
+	a.ID = r.nextID
+	r.items[a.ID] = a
+	return nil
+}
+
+func (r *fakeRepo) List(_ context.Context, owner Owner, ownerID int) ([]Attachment, error) {
+	var list []Attachment
+	for _, a := range r.items {
+		if a.Owner == owner && a.OwnerID == ownerID {
+			list = append(list, *a)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeRepo) Count(ctx context.Context, owner Owner, ownerID int) (int, error) {
+	list, err := r.List(ctx, owner, ownerID)
+	return len(list), err
+}
+
+func (r *fakeRepo) Get(_ context.Context, id int) (*Attachment, error) {
+	a, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return a, nil
+}
+
+func (r *fakeRepo) Delete(_ context.Context, id int) error {
+	delete(r.items, id)
+	return nil
+}
+
+func TestServiceAccess(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeRepo{items: map[int]*Attachment{}}
+	s := NewService(repo, fakeTodos{}, fakeEntries{}, nil)
+
+	a, err := s.Attach(ctx, 1, OwnerTodo, 1, File{Kind: KindPhoto, FileID: "f1"})
+	if err != nil {
+		t.Fatalf("owner Attach: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{name: "участник-читатель видит вложения", call: func() error {
+			list, err := s.List(ctx, 2, OwnerTodo, 1)
+			if err == nil && len(list) != 1 {
+				return errors.New("unexpected list length")
+			}
+			return err
+		}},
+		{name: "участник-читатель получает вложение", call: func() error {
+			_, err := s.Get(ctx, 2, a.ID)
+			return err
+		}},
+		{name: "участник-читатель не прикрепляет", want: todo.ErrForbidden, call: func() error {
+			_, err := s.Attach(ctx, 2, OwnerTodo, 1, File{Kind: KindPhoto, FileID: "f2"})
+			return err
+		}},
+		{name: "участник-читатель не удаляет", want: todo.ErrForbidden, call: func() error {
+			return s.Delete(ctx, 2, a.ID)
+		}},
+		{name: "посторонний не видит вложение", want: ErrNotFound, call: func() error {
+			_, err := s.Get(ctx, 3, a.ID)
+			return err
+		}},
+		{name: "чужая операция", want: finance.ErrNotFound, call: func() error {
+			_, err := s.List(ctx, 2, OwnerFinance, 1)
+			return err
+		}},
+		{name: "неизвестный тип записи", want: ErrOwner, call: func() error {
+			_, err := s.List(ctx, 1, Owner("note"), 1)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, ok := repo.items[a.ID]; !ok {
+		t.Error("attachment deleted by view-only member")
+	}
+	if err := s.Delete(ctx, 1, a.ID); err != nil {
+		t.Errorf("owner Delete: %v", err)
+	}
+}
+
+func TestStripURL(t *testing.T) {
+	err := &url.Error{Op: "Get", URL: "https://api.telegram.org/file/bot123:SECRET/photos/1.jpg", Err: context.DeadlineExceeded}
+	if got := stripURL(err); strings.Contains(got.Error(), "SECRET") || !errors.Is(got, context.DeadlineExceeded) {
+		t.Errorf("stripURL = %v", got)
+	}
+}
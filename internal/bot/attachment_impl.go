@@ -0,0 +1,313 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tg_bot_asist/internal/attachment"
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Вложения: фото, документы, голосовые и видео прикрепляются к задачам и операциям.
+// Файл можно прислать во время мастера добавления (прикрепится к созданной записи)
+// или ответом на сообщение бота с подсказкой attachHint (прикрепится к существующей записи).
+
+// Подсказки в сообщениях о задачах и операциях; по ним ответ с файлом находит запись.
+const (
+	todoAttachHint    = "📎 Ответьте на это сообщение файлом, чтобы прикрепить его к задаче #%d"
+	financeAttachHint = "📎 Ответьте на это сообщение файлом, чтобы прикрепить его к операции #%d"
+)
+
+// kindTitles — подписи видов вложений.
+var kindTitles = map[attachment.Kind]string{
+	attachment.KindPhoto:    "🖼 Фото",
+	attachment.KindDocument: "📄 Документ",
+	attachment.KindVoice:    "🎤 Голосовое",
+	attachment.KindAudio:    "🎵 Аудио",
+	attachment.KindVideo:    "🎬 Видео",
+}
+
+// attachHint возвращает строку-подсказку для записи.
+func attachHint(owner attachment.Owner, id int) string {
+	if owner == attachment.OwnerFinance {
+		return fmt.Sprintf(financeAttachHint, id)
+	}
+	return fmt.Sprintf(todoAttachHint, id)
+}
+
+// parseAttachHint ищет в тексте сообщения бота подсказку attachHint и возвращает её запись.
+func parseAttachHint(text string) (attachment.Owner, int, bool) {
+	hints := map[attachment.Owner]string{
+		attachment.OwnerTodo:    strings.TrimSuffix(todoAttachHint, "%d"),
+		attachment.OwnerFinance: strings.TrimSuffix(financeAttachHint, "%d"),
+	}
+	for _, line := range strings.Split(text, "\n") {
+		for owner, prefix := range hints {
+			rest, ok := strings.CutPrefix(strings.TrimSpace(line), prefix)
+			if !ok {
+				continue
+			}
+			if id, err := strconv.Atoi(rest); err == nil && id > 0 {
+				return owner, id, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// messageFile возвращает файл сообщения; у фото берётся самый крупный размер.
+func messageFile(m *tgbotapi.Message) (attachment.File, bool) {
+	switch {
+	case len(m.Photo) > 0:
+		p := m.Photo[len(m.Photo)-1]
+		return attachment.File{Kind: attachment.KindPhoto, FileID: p.FileID, FileUniqueID: p.FileUniqueID,
+			MimeType: "image/jpeg", Size: int64(p.FileSize)}, true
+	case m.Document != nil:
+		d := m.Document
+		return attachment.File{Kind: attachment.KindDocument, FileID: d.FileID, FileUniqueID: d.FileUniqueID,
+			FileName: d.FileName, MimeType: d.MimeType, Size: int64(d.FileSize)}, true
+	case m.Voice != nil:
+		v := m.Voice
+		return attachment.File{Kind: attachment.KindVoice, FileID: v.FileID, FileUniqueID: v.FileUniqueID,
+			MimeType: v.MimeType, Size: int64(v.FileSize)}, true
+	case m.Audio != nil:
+		a := m.Audio
+		return attachment.File{Kind: attachment.KindAudio, FileID: a.FileID, FileUniqueID: a.FileUniqueID,
+			FileName: a.FileName, MimeType: a.MimeType, Size: int64(a.FileSize)}, true
+	case m.Video != nil:
+		v := m.Video
+		return attachment.File{Kind: attachment.KindVideo, FileID: v.FileID, FileUniqueID: v.FileUniqueID,
+			FileName: v.FileName, MimeType: v.MimeType, Size: int64(v.FileSize)}, true
+	}
+	return attachment.File{}, false
+}
+
+// handleAttachment обрабатывает сообщение с файлом: ответ на подсказку прикрепляет файл
+// к существующей записи, во время мастера файл откладывается до сохранения записи.
+func (h *Handler) handleAttachment(update tgbotapi.Update, f attachment.File) {
+	m := update.Message
+	userID := m.From.ID
+
+	if reply := m.ReplyToMessage; reply != nil && reply.From != nil && reply.From.ID == h.bot.Self.ID {
+		if owner, id, ok := parseAttachHint(reply.Text); ok {
+			h.attachToItem(userID, owner, id, f)
+			return
+		}
+	}
+
+	if state := h.fsm.Get(userID); state != nil {
+		if w, ok := h.wizards[state.Name]; ok && w.Attach != "" {
+			h.addWizardFile(userID, w, state.Data, f)
+			return
+		}
+	}
+
+	h.Send(userID, "Чтобы прикрепить файл, отправьте его во время добавления задачи или операции "+
+		"или ответьте им на сообщение бота о сохранённой задаче или операции.", HomeKeyboard())
+}
+
+// attachToItem прикрепляет файл к существующей записи и сообщает результат.
+func (h *Handler) attachToItem(userID int64, owner attachment.Owner, id int, f attachment.File) {
+	_, err := h.attachments.Attach(context.Background(), userID, owner, id, f)
+	if err != nil {
+		h.Send(userID, attachErrorText(err), HomeKeyboard())
+		return
+	}
+
+	if owner == attachment.OwnerTodo {
+		h.Send(userID, fmt.Sprintf("📎 Файл прикреплён к задаче #%d", id), TodoKeyboard())
+		h.showTodoCard(userID, id)
+		return
+	}
+	h.Send(userID, fmt.Sprintf("📎 Файл прикреплён к операции #%d", id), FinanceKeyboard())
+}
+
+// attachErrorText — текст ошибки прикрепления для пользователя.
+func attachErrorText(err error) string {
+	switch {
+	case errors.Is(err, todo.ErrNotFound), errors.Is(err, finance.ErrNotFound):
+		return "Запись не найдена"
+	case errors.Is(err, todo.ErrForbidden):
+		return "Недостаточно прав, чтобы прикреплять файлы"
+	case errors.Is(err, attachment.ErrDuplicate):
+		return "Этот файл уже прикреплён"
+	case errors.Is(err, attachment.ErrTooMany):
+		return fmt.Sprintf("Можно прикрепить не больше %d файлов", attachment.MaxPerItem)
+	}
+	logger.Error("Attach file failed: " + err.Error())
+	return "Не удалось прикрепить файл. Попробуйте позже."
+}
+
+// pendingFile — файл, ожидающий сохранения записи мастера (в State.Data хранится как JSON).
+type pendingFile struct {
+	Kind         attachment.Kind `json:"k"`
+	FileID       string          `json:"id"`
+	FileUniqueID string          `json:"u"`
+	FileName     string          `json:"n,omitempty"`
+	MimeType     string          `json:"m,omitempty"`
+	Size         int64           `json:"s,omitempty"`
+}
+
+// addWizardFile откладывает файл до сохранения записи и повторяет текущий вопрос мастера.
+func (h *Handler) addWizardFile(userID int64, w *wizard, data map[string]any, f attachment.File) {
+	if data == nil {
+		data = map[string]any{}
+	}
+	files, _ := data[wizardFilesKey].([]string)
+	if len(files) >= attachment.MaxPerItem {
+		h.Send(userID, fmt.Sprintf("Можно прикрепить не больше %d файлов", attachment.MaxPerItem), w.Keyboard())
+		return
+	}
+
+	raw, err := json.Marshal(pendingFile(f))
+	if err != nil {
+		logger.Error("Wizard file encode error: " + err.Error())
+		return
+	}
+	data[wizardFilesKey] = append(files, string(raw))
+	if !h.setState(userID, w.Name, data) {
+		return
+	}
+
+	text := fmt.Sprintf("📎 Файл прикрепится после сохранения (файлов: %d)", len(files)+1)
+	step, _ := data[wizardStepKey].(int)
+	if step >= len(w.Steps) {
+		h.Send(userID, text, confirmKeyboard())
+		return
+	}
+	h.Send(userID, text, stepKeyboard(w.Steps[step], data))
+}
+
+// attachWizardFiles прикрепляет файлы мастера к созданной записи и возвращает строку для итогового сообщения.
+func (h *Handler) attachWizardFiles(userID int64, owner attachment.Owner, id int, data map[string]any) string {
+	files, _ := data[wizardFilesKey].([]string)
+	if len(files) == 0 {
+		return ""
+	}
+
+	attached := 0
+	for _, raw := range files {
+		var pf pendingFile
+		if err := json.Unmarshal([]byte(raw), &pf); err != nil {
+			logger.Error("Wizard file decode error: " + err.Error())
+			continue
+		}
+		if _, err := h.attachments.Attach(context.Background(), userID, owner, id, attachment.File(pf)); err != nil {
+			if !errors.Is(err, attachment.ErrDuplicate) {
+				logger.Error("Wizard file attach error: " + err.Error())
+			}
+			continue
+		}
+		attached++
+	}
+	return fmt.Sprintf("\n📎 Прикреплено файлов: %d", attached)
+}
+
+// attachmentTitle — подпись вложения в карточке.
+func attachmentTitle(a attachment.Attachment) string {
+	if a.FileName != "" {
+		return kindTitles[a.Kind] + " " + a.FileName
+	}
+	return kindTitles[a.Kind]
+}
+
+// todoAttachmentsSection возвращает список вложений задачи для карточки и кнопки открытия и удаления.
+func (h *Handler) todoAttachmentsSection(userID int64, todoID int) (string, [][]tgbotapi.InlineKeyboardButton) {
+	list, err := h.attachments.List(context.Background(), userID, attachment.OwnerTodo, todoID)
+	if err != nil {
+		logger.Error("Todo attachments error: " + err.Error())
+		return "", nil
+	}
+	if len(list) == 0 {
+		return "", nil
+	}
+
+	var (
+		b    strings.Builder
+		rows [][]tgbotapi.InlineKeyboardButton
+	)
+	b.WriteString("Вложения:\n")
+	for _, a := range list {
+		title := attachmentTitle(a)
+		b.WriteString(title + "\n")
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			callbackButton(truncate(title, 30), cbAttach, actOpen, a.ID),
+			callbackButton("🗑", cbAttach, actDelete, a.ID),
+		))
+	}
+	return b.String(), rows
+}
+
+// attachmentMessage — сообщение, повторно отправляющее файл по его file_id.
+func attachmentMessage(chatID int64, a *attachment.Attachment) tgbotapi.Chattable {
+	file := tgbotapi.FileID(a.FileID)
+	switch a.Kind {
+	case attachment.KindPhoto:
+		return tgbotapi.NewPhoto(chatID, file)
+	case attachment.KindVoice:
+		return tgbotapi.NewVoice(chatID, file)
+	case attachment.KindAudio:
+		return tgbotapi.NewAudio(chatID, file)
+	case attachment.KindVideo:
+		return tgbotapi.NewVideo(chatID, file)
+	}
+	return tgbotapi.NewDocument(chatID, file)
+}
+
+// registerAttachmentCallbacks регистрирует кнопки вложений в карточке задачи.
+func (h *Handler) registerAttachmentCallbacks(r *callbackRouter) {
+	r.Handle(cbAttach, actOpen, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		a, err := h.attachments.Get(context.Background(), cb.From.ID, data.Arg(0))
+		if err != nil {
+			h.afterAttachmentCallback(cb, 0, err)
+			return
+		}
+		h.answerCallback(cb, "")
+		if _, err := h.bot.Send(attachmentMessage(cb.Message.Chat.ID, a)); err != nil {
+			logger.Error("Failed to send attachment: " + err.Error())
+		}
+	})
+	r.Handle(cbAttach, actDelete, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		ctx := context.Background()
+		a, err := h.attachments.Get(ctx, cb.From.ID, data.Arg(0))
+		if err == nil {
+			err = h.attachments.Delete(ctx, cb.From.ID, a.ID)
+		}
+		if err != nil {
+			h.afterAttachmentCallback(cb, 0, err)
+			return
+		}
+		h.afterAttachmentCallback(cb, a.OwnerID, nil)
+	})
+}
+
+// afterAttachmentCallback отвечает на удаление вложения и перерисовывает карточку задачи.
+func (h *Handler) afterAttachmentCallback(cb *tgbotapi.CallbackQuery, todoID int, err error) {
+	if err != nil {
+		if errors.Is(err, attachment.ErrNotFound) {
+			h.answerCallback(cb, "Вложение не найдено")
+		} else if errors.Is(err, todo.ErrForbidden) {
+			h.answerCallback(cb, "Недостаточно прав")
+		} else {
+			logger.Error("Attachment callback failed: " + err.Error())
+			h.answerCallback(cb, "Ошибка, попробуйте позже")
+		}
+		return
+	}
+	h.answerCallback(cb, "🗑 Вложение удалено")
+
+	text, kb, err := h.renderTodoCard(cb.From.ID, todoID)
+	if err != nil {
+		logger.Error("Todo card error: " + err.Error())
+		return
+	}
+	h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, text, kb)
+}
@@ -0,0 +1,217 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/logger"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Месячные бюджеты: кнопка «🎯 Бюджеты» показывает исполнение бюджетов за текущий месяц,
+// «➕ Задать бюджет» запускает мастер, 🗑 удаляет бюджет. Уведомления о пересечении
+// 80% и 100% отправляет finance.Service при добавлении расхода (через Notifier).
+
+// wizBudgetSet — имя мастера (и состояния FSM) установки бюджета.
+const wizBudgetSet = "BUDGET_SET"
+
+// budgetTotalOption — вариант шага «Категория» для общего бюджета.
+const budgetTotalOption = "💰 Общий бюджет"
+
+// budgetRollovers — подписи режимов переноса остатка.
+var budgetRollovers = []struct {
+	Label string
+	Mode  finance.Rollover
+}{
+	{"Без переноса", finance.RolloverNone},
+	{"Переносить остаток", finance.RolloverUnused},
+	{"Переносить остаток и перерасход", finance.RolloverAll},
+}
+
+// rolloverLabel возвращает подпись режима переноса.
+func rolloverLabel(r finance.Rollover) string {
+	for _, opt := range budgetRollovers {
+		if opt.Mode == r {
+			return opt.Label
+		}
+	}
+	return string(r)
+}
+
+// budgetSetWizard — мастер установки бюджета: категория (или общий), лимит, перенос остатка.
+// Бюджет на категорию, у которой он уже есть, заменяется.
+func (h *Handler) budgetSetWizard() *wizard {
+	return &wizard{
+		Name: wizBudgetSet,
+		Steps: []wizardStep{
+			{
+				Key:    "category_id",
+				Label:  "Категория",
+				Prompt: staticPrompt("На что задать месячный бюджет? Выберите категорию расходов или общий бюджет:"),
+				Options: func(data map[string]any) []string {
+					return append([]string{budgetTotalOption}, h.categoryOptions(data)...)
+				},
+				Parse: func(text string, data map[string]any) (any, error) {
+					if text == budgetTotalOption {
+						data["category"] = ""
+						return 0, nil
+					}
+					c, ok := finance.MatchCategory(h.keyboardCategories(data), text)
+					if !ok {
+						return nil, errors.New("Категория не найдена. Выберите категорию кнопкой:")
+					}
+					data["category"] = c.Name
+					return c.ID, nil
+				},
+				Format: func(_ any, data map[string]any) string {
+					if name := getString(data, "category"); name != "" {
+						return name
+					}
+					return "Общий бюджет"
+				},
+			},
+			{
+				Key:    "limit",
+				Label:  "Лимит в месяц",
+				Prompt: staticPrompt("Введите месячный лимит, например: 15000"),
+				Parse:  parsePositiveFloat("Введите корректную сумму:"),
+				Format: formatRubles,
+			},
+			{
+				Key:    "rollover",
+				Label:  "Перенос остатка",
+				Prompt: staticPrompt("Что делать с остатком в конце месяца?"),
+				Options: func(map[string]any) []string {
+					opts := make([]string, 0, len(budgetRollovers))
+					for _, opt := range budgetRollovers {
+						opts = append(opts, opt.Label)
+					}
+					return opts
+				},
+				Parse: func(text string, _ map[string]any) (any, error) {
+					for _, opt := range budgetRollovers {
+						if text == opt.Label {
+							return string(opt.Mode), nil
+						}
+					}
+					return nil, errors.New("Выберите вариант кнопкой:")
+				},
+				Format: func(v any, _ map[string]any) string { return rolloverLabel(finance.Rollover(v.(string))) },
+			},
+		},
+		Submit: func(userID int64, data map[string]any) (string, error) {
+			categoryID, _ := data["category_id"].(int)
+			b, err := h.finance.SetBudget(context.Background(), userID, categoryID, getFloat(data, "limit"),
+				finance.Rollover(getString(data, "rollover")))
+			if err != nil {
+				if errors.Is(err, finance.ErrCategoryNotFound) || errors.Is(err, finance.ErrBudgetCategory) {
+					return "Категория не найдена — бюджет можно задать только для категории расходов.", nil
+				}
+				return "", err
+			}
+			return fmt.Sprintf("🎯 %s: %.2f ₽ в месяц (%s).\nПредупрежу, когда расходы дойдут до 80%% и 100%%.",
+				b.Title(), b.Limit, strings.ToLower(rolloverLabel(b.Rollover))), nil
+		},
+		Keyboard: FinanceKeyboard,
+	}
+}
+
+// showBudgets отправляет исполнение бюджетов за текущий месяц.
+func (h *Handler) showBudgets(userID int64) {
+	text, kb, err := h.renderBudgets(userID)
+	if err != nil {
+		logger.Error("Budget report error: " + err.Error())
+		h.Send(userID, "Ошибка получения бюджетов", FinanceKeyboard())
+		return
+	}
+	h.SendInline(userID, text, kb)
+}
+
+// renderBudgets формирует отчёт по бюджетам за текущий месяц с кнопками удаления и установки бюджета.
+func (h *Handler) renderBudgets(userID int64) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	now := time.Now().In(h.userLocation(userID))
+	report, err := h.finance.BudgetReport(context.Background(), userID, now)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	setRow := tgbotapi.NewInlineKeyboardRow(callbackButton("➕ Задать бюджет", cbBudget, actEdit))
+	if len(report) == 0 {
+		return "Бюджетов пока нет.\nЗадайте месячный лимит на категорию, например «Кафе: 15 000 ₽», или общий бюджет.",
+			tgbotapi.NewInlineKeyboardMarkup(setRow), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🎯 Бюджеты на %s:\n", now.Format("01.2006"))
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, p := range report {
+		b.WriteString("\n" + formatBudgetProgress(p))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			callbackButton("🗑 "+truncate(p.Title(), 24), cbBudget, actDelete, p.ID),
+		))
+	}
+	rows = append(rows, setRow)
+	return b.String(), tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// formatBudgetProgress — блок отчёта по одному бюджету.
+func formatBudgetProgress(p finance.BudgetProgress) string {
+	icon := "🟢"
+	switch {
+	case p.Percent >= 100:
+		icon = "🔴"
+	case p.Percent >= 80:
+		icon = "🟡"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n%s %.0f%% — %.2f из %.2f ₽\n", icon, p.Title(), progressBar(p.Percent), p.Percent, p.Spent, p.Available)
+	if p.Carried != 0 {
+		fmt.Fprintf(&b, "Лимит %.2f ₽, перенос %+.2f ₽\n", p.Limit, p.Carried)
+	}
+	if p.Remaining >= 0 {
+		fmt.Fprintf(&b, "Осталось %.2f ₽\n", p.Remaining)
+	} else {
+		fmt.Fprintf(&b, "Перерасход %.2f ₽\n", -p.Remaining)
+	}
+	return b.String()
+}
+
+// progressBar — полоса из 10 делений для процента исполнения.
+func progressBar(percent float64) string {
+	filled := int(math.Round(math.Min(percent, 100) / 10))
+	return strings.Repeat("▓", filled) + strings.Repeat("░", 10-filled)
+}
+
+// registerBudgetCallbacks регистрирует кнопки экрана бюджетов.
+func (h *Handler) registerBudgetCallbacks(r *callbackRouter) {
+	r.Handle(cbBudget, actEdit, func(cb *tgbotapi.CallbackQuery, _ callbackData) {
+		h.answerCallback(cb, "")
+		h.startWizard(cb.From.ID, h.wizards[wizBudgetSet], map[string]any{"type": finance.TypeExpense})
+	})
+	r.Handle(cbBudget, actDelete, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		if err := h.finance.DeleteBudget(context.Background(), cb.From.ID, data.Arg(0)); err != nil {
+			if errors.Is(err, finance.ErrBudgetNotFound) {
+				h.answerCallback(cb, "Бюджет не найден")
+				return
+			}
+			logger.Error("Budget delete error: " + err.Error())
+			h.answerCallback(cb, "Ошибка, попробуйте позже")
+			return
+		}
+		h.answerCallback(cb, "🗑 Бюджет удалён")
+
+		text, kb, err := h.renderBudgets(cb.From.ID)
+		if err != nil {
+			logger.Error("Budget report error: " + err.Error())
+			return
+		}
+		h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, text, kb)
+	})
+}
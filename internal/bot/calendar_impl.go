@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/calendar"
+	"tg_bot_asist/internal/config"
+	"tg_bot_asist/internal/logger"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleCalendarCommand — /calendar отправляет файл .ics со сроками задач и платежей
+// и ссылку для подписки; /calendar reset выпускает новую ссылку (старая перестаёт работать).
+func (h *Handler) handleCalendarCommand(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+	ctx := context.Background()
+
+	_, arg, _ := strings.Cut(strings.TrimSpace(update.Message.Text), " ")
+
+	var token string
+	var err error
+	if strings.TrimSpace(arg) == "reset" {
+		token, err = h.calendar.RotateToken(ctx, userID)
+	} else {
+		token, err = h.calendar.Token(ctx, userID)
+	}
+	if err != nil {
+		logger.Error("Calendar token error: " + err.Error())
+		h.Send(chatID, "Ошибка при подготовке календаря", HomeKeyboard())
+		return
+	}
+
+	now := time.Now()
+	events, err := h.calendar.Events(ctx, userID, now)
+	if err != nil {
+		logger.Error("Calendar events error: " + err.Error())
+		h.Send(chatID, "Ошибка при подготовке календаря", HomeKeyboard())
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := calendar.WriteICS(&buf, "Ассистент", events, now); err != nil {
+		logger.Error("Calendar write error: " + err.Error())
+		h.Send(chatID, "Ошибка при подготовке календаря", HomeKeyboard())
+		return
+	}
+
+	caption := "📅 Сроки задач, регулярные платежи и платежи по кредитам. Откройте файл, чтобы импортировать события."
+	if base := config.Get("API_PUBLIC_URL"); base != "" {
+		caption += "\n\nПодписка (календарь будет обновляться сам):\n" + calendar.FeedURL(base, token) +
+			"\n\nНовая ссылка: /calendar reset"
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: "calendar.ics", Bytes: buf.Bytes()})
+	doc.Caption = caption
+	if _, err := h.bot.Send(doc); err != nil {
+		logger.Error("Failed to send calendar: " + err.Error())
+	}
+}
@@ -0,0 +1,178 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tg_bot_asist/internal/logger"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data inline-кнопок кодируется компактно: "<модуль>:<действие>[:<аргумент>...]",
+// например "t:d:42" — отметить задачу 42 выполненной.
+// Telegram ограничивает callback data 64 байтами, поэтому модули и действия — короткие коды,
+// а аргументы — целые числа (ID, номер страницы).
+
+// Коды модулей.
+const (
+	cbTodo      = "t"
+	cbFinance   = "f"
+	cbRecurring = "r"
+	cbCredit    = "c"
+	cbChecklist = "k"
+	cbReminder  = "n"
+	cbProject   = "p"
+	cbTimer     = "w"
+	cbImport    = "i"
+	cbAttach    = "a"
+	cbBudget    = "b"
+	cbReport    = "o"
+)
+
+// Коды действий.
+const (
+	actDone     = "d" // выполнить
+	actReopen   = "o" // вернуть в работу
+	actEdit     = "e" // редактировать
+	actDelete   = "x" // удалить
+	actSchedule = "s" // показать график
+	actCopy     = "c" // скопировать
+	actList     = "l" // вернуться к списку
+	actPage     = "p" // перейти на страницу
+	actOpen     = "v" // открыть карточку
+	actSnooze   = "z" // отложить
+	actAck      = "a" // принять (больше не напоминать)
+	actStart    = "b" // запустить
+	actStop     = "h" // остановить
+)
+
+// maxCallbackData — ограничение Telegram на размер callback data.
+const maxCallbackData = 64
+
+// callbackData — разобранные данные inline-кнопки.
+type callbackData struct {
+	Module string
+	Action string
+	Args   []int
+}
+
+// Arg возвращает i-й аргумент или 0, если его нет.
+func (c callbackData) Arg(i int) int {
+	if i < len(c.Args) {
+		return c.Args[i]
+	}
+	return 0
+}
+
+// encodeCallback кодирует модуль, действие и аргументы в callback data.
+func encodeCallback(module, action string, args ...int) string {
+	var b strings.Builder
+	b.WriteString(module)
+	b.WriteByte(':')
+	b.WriteString(action)
+	for _, a := range args {
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(a))
+	}
+	return b.String()
+}
+
+// decodeCallback разбирает callback data, созданные encodeCallback.
+func decodeCallback(data string) (callbackData, error) {
+	if len(data) > maxCallbackData {
+		return callbackData{}, fmt.Errorf("callback data too long: %d bytes", len(data))
+	}
+
+	parts := strings.Split(data, ":")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return callbackData{}, fmt.Errorf("invalid callback data %q", data)
+	}
+
+	cd := callbackData{Module: parts[0], Action: parts[1]}
+	for _, p := range parts[2:] {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return callbackData{}, fmt.Errorf("invalid callback argument %q", p)
+		}
+		cd.Args = append(cd.Args, n)
+	}
+	return cd, nil
+}
+
+// callbackButton создаёт inline-кнопку с закодированными callback data.
+func callbackButton(text, module, action string, args ...int) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(text, encodeCallback(module, action, args...))
+}
+
+// callbackHandler обрабатывает нажатие inline-кнопки.
+type callbackHandler func(cb *tgbotapi.CallbackQuery, data callbackData)
+
+// callbackRouter направляет callback-запросы обработчикам по паре модуль/действие.
+type callbackRouter struct {
+	routes map[string]callbackHandler
+}
+
+func newCallbackRouter() *callbackRouter {
+	return &callbackRouter{routes: make(map[string]callbackHandler)}
+}
+
+// Handle регистрирует обработчик для модуля и действия.
+func (r *callbackRouter) Handle(module, action string, fn callbackHandler) {
+	r.routes[module+":"+action] = fn
+}
+
+// Dispatch разбирает callback data и вызывает зарегистрированный обработчик.
+// Возвращает false, если данные некорректны или обработчик не найден.
+func (r *callbackRouter) Dispatch(cb *tgbotapi.CallbackQuery) bool {
+	data, err := decodeCallback(cb.Data)
+	if err != nil {
+		logger.Warn("Callback decode failed: " + err.Error())
+		return false
+	}
+
+	fn, ok := r.routes[data.Module+":"+data.Action]
+	if !ok {
+		logger.Warn("No callback handler for " + cb.Data)
+		return false
+	}
+
+	fn(cb, data)
+	return true
+}
+
+// registerCallbacks регистрирует обработчики inline-кнопок всех модулей.
+func (h *Handler) registerCallbacks() {
+	h.registerTodoCallbacks(h.callbacks)
+	h.registerFinanceCallbacks(h.callbacks)
+	h.registerRecurringCallbacks(h.callbacks)
+	h.registerCreditCallbacks(h.callbacks)
+	h.registerReminderCallbacks(h.callbacks)
+	h.registerProjectCallbacks(h.callbacks)
+	h.registerTimerCallbacks(h.callbacks)
+	h.registerImportCallbacks(h.callbacks)
+	h.registerAttachmentCallbacks(h.callbacks)
+	h.registerBudgetCallbacks(h.callbacks)
+	h.registerReportCallbacks(h.callbacks)
+}
+
+// handleCallback обрабатывает нажатие inline-кнопки.
+func (h *Handler) handleCallback(cb *tgbotapi.CallbackQuery) {
+	if cb.Message == nil {
+		h.answerCallback(cb, "")
+		return
+	}
+	if !h.callbacks.Dispatch(cb) {
+		h.answerCallback(cb, "Действие устарело")
+	}
+}
+
+// truncate обрезает текст кнопки до n символов.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
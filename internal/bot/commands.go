@@ -1,5 +1,7 @@
 package bot
 
+import "strings"
+
 // Константы команд и текстов кнопок бота.
 // Используются для обработки сообщений и построения клавиатур.
 const (
@@ -8,6 +10,10 @@ const (
 	CmdHome  = "🏠 Дом"   // Возврат в главное меню
 	CmdBack  = "🔙 Назад" // Возврат на предыдущий уровень
 
+	// Навигация в пошаговых диалогах
+	CmdStepBack = "⬅️ Шаг назад" // Вернуться к предыдущему вопросу
+	CmdConfirm  = "✅ Сохранить"  // Подтвердить ввод на последнем шаге
+
 	// TODO модуль
 	CmdTodo     = "📝 Задачи"          // Вход в модуль задач
 	CmdTodoAdd  = "➕ Добавить задачу" // Создание новой задачи
@@ -20,10 +26,54 @@ const (
 	CmdRecurring     = "🔁 Регулярные платежи"  // Управление регулярными платежами
 	CmdRecurringAdd  = "➕ Добавить регулярный" // Создание регулярного платежа
 	CmdRecurringList = "📅 Список регулярных"   // Просмотр регулярных платежей
+	CmdBudgets       = "🎯 Бюджеты"             // Месячные бюджеты по категориям
+	CmdFinanceReport = "📈 Отчёт"               // Отчёт за текущий месяц с навигацией по периодам
 
 	// Credits модуль
 	CmdCredits        = "🏦 Кредиты"         // Вход в кредитный модуль
 	CmdCreditAdd      = "➕ Добавить кредит" // Создание нового кредита
 	CmdCreditList     = "📄 Список кредитов" // Просмотр списка кредитов
 	CmdCreditPayments = "📆 График платежей" // Просмотр графика платежей
+
+	// Slash-команды
+	CmdCalendar   = "/calendar" // Файл .ics и ссылка подписки; /calendar reset — новая ссылка
+	CmdDigest     = "/digest"   // Настройки утренней сводки; /digest now — сводка сейчас
+	CmdNudge      = "/nudge"    // Повторные напоминания о просроченных задачах
+	CmdTimer      = "/timer"    // Запущенный таймер; /timer report — недельный отчёт
+	CmdSearch     = "/search"   // Поиск по всем модулям: /search <запрос>
+	CmdTodoStats  = "/stats"    // Статистика выполнения задач: /stats [дней]
+	CmdReport     = "/report"   // Отчёт по финансам: /report [день | неделя | месяц | год | 01.03-15.03]
+	CmdTodoImport = "/import"   // Массовый импорт задач: /import [id проекта]
 )
+
+// Текстовые команды модуля задач.
+const (
+	CmdTodoDone     = "/done"        // Отметить задачу выполненной
+	CmdTodoReopen   = "/reopen"      // Вернуть задачу в работу
+	CmdTodoEdit     = "/edit_todo"   // Изменить заголовок, описание и срок
+	CmdTodoDue      = "/due"         // Изменить или снять срок
+	CmdTodoDelete   = "/delete_todo" // Удалить задачу
+	CmdTodoTag      = "/tag"         // Заменить метки задачи
+	CmdTodoPriority = "/priority"    // Изменить приоритет задачи
+	CmdTodoRepeat   = "/repeat"      // Задать правило повторения задачи
+	CmdTodoSub      = "/sub"         // Добавить подзадачи
+	CmdTodoCheck    = "/check"       // Показать чек-лист или добавить пункты
+	CmdTodoAutoDone = "/autodone"    // Автовыполнение задачи по чек-листу и подзадачам
+	CmdTodoMove     = "/move"        // Перенести задачу в другой проект
+
+	CmdTodoFilter = "/todo" // Список задач с фильтрами (без <id>)
+
+	CmdProjects = "/projects" // Список проектов
+	CmdProject  = "/project"  // Задачи проекта и управление проектами
+)
+
+// isTodoCommand проверяет, является ли текст текстовой командой модуля задач.
+func isTodoCommand(text string) bool {
+	cmd, _, _ := strings.Cut(text, " ")
+	switch cmd {
+	case CmdTodoDone, CmdTodoReopen, CmdTodoEdit, CmdTodoDue, CmdTodoDelete, CmdTodoTag, CmdTodoPriority, CmdTodoRepeat,
+		CmdTodoSub, CmdTodoCheck, CmdTodoAutoDone, CmdTodoMove, CmdTodoFilter, CmdProjects, CmdProject:
+		return true
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"strconv"
@@ -14,125 +15,179 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-// startCreditAdd — запускает FSM для добавления нового кредита
+// wizCreditAdd — имя мастера (и состояния FSM) добавления кредита.
+const wizCreditAdd = "CREDIT_ADD"
+
+// creditAddWizard — мастер добавления кредита: название, сумма, ставка, срок.
+func (h *Handler) creditAddWizard() *wizard {
+	return &wizard{
+		Name: wizCreditAdd,
+		Steps: []wizardStep{
+			{
+				Key:    "title",
+				Label:  "Название",
+				Prompt: staticPrompt("Введите название кредита:"),
+				Parse:  parseRequired("Название не может быть пустым. Введите название кредита:"),
+			},
+			{
+				Key:    "principal",
+				Label:  "Сумма",
+				Prompt: staticPrompt("Введите сумму кредита (числом):"),
+				Parse:  parsePositiveFloat("Неверная сумма. Введите сумму (например 1230000):"),
+				Format: formatRubles,
+			},
+			{
+				Key:    "rate",
+				Label:  "Ставка",
+				Prompt: staticPrompt("Введите годовую процентную ставку (например 12.5):"),
+				Parse: func(text string, _ map[string]any) (any, error) {
+					r, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64)
+					if err != nil || r < 0 {
+						return nil, errors.New("Неверная ставка. Введите число (например 12.5):")
+					}
+					return r, nil
+				},
+				Format: func(v any, _ map[string]any) string { return fmt.Sprintf("%.2f%%", v) },
+			},
+			{
+				Key:    "months",
+				Label:  "Срок, мес.",
+				Prompt: staticPrompt("Введите срок кредита в месяцах (например 36):"),
+				Parse: func(text string, _ map[string]any) (any, error) {
+					m, err := strconv.Atoi(text)
+					if err != nil || m <= 0 {
+						return nil, errors.New("Неверный срок. Введите целое число месяцев:")
+					}
+					return m, nil
+				},
+			},
+		},
+		Submit:   h.saveCreditFromDraft,
+		Keyboard: CreditsKeyboard,
+	}
+}
+
+// startCreditAdd запускает мастер добавления кредита.
 func (h *Handler) startCreditAdd(userID int64) {
-	h.fsm.Set(userID, "CREDIT_ADD", map[string]any{})
-	h.Send(userID, "Введите название кредита:", BackKeyboard())
+	h.startWizard(userID, h.wizards[wizCreditAdd], nil)
 }
 
-// handleCreditAdd — FSM: собирает данные по шагам и сохраняет кредит через сервис
-func (h *Handler) handleCreditAdd(update tgbotapi.Update) {
-	if update.Message == nil {
-		return
+// saveCreditFromDraft сохраняет кредит, собранный мастером.
+func (h *Handler) saveCreditFromDraft(userID int64, data map[string]any) (string, error) {
+	title, _ := data["title"].(string)
+	principal, _ := data["principal"].(float64)
+	rate, _ := data["rate"].(float64)
+	months, _ := data["months"].(int)
+
+	credit := &credits.Credit{
+		UserID:    userID,
+		Title:     title,
+		Principal: principal,
+		Rate:      rate,
+		Months:    months,
 	}
-	userID := update.Message.From.ID
-	chatID := update.Message.Chat.ID
-	text := strings.TrimSpace(update.Message.Text)
 
-	state := h.fsm.Get(userID)
-	if state == nil || state.Name != "CREDIT_ADD" {
-		h.Send(chatID, "Нет активного процесса добавления кредита", CreditsKeyboard())
-		return
-	}
-
-	// state.Data уже map[string]any, используем напрямую
-	data := state.Data
-	if data == nil {
-		data = map[string]any{}
+	id, err := h.credits.Add(context.Background(), credit)
+	if err != nil {
+		return "", err
 	}
 
-	// шаги: title -> principal -> rate -> months -> confirm
-	step, _ := data["step"].(string)
-	switch step {
-	case "", "title":
-		// записываем название
-		data["title"] = text
-		data["step"] = "principal"
-		h.fsm.Set(userID, "CREDIT_ADD", data)
-		h.Send(chatID, "Введите сумму кредита (числом):", BackKeyboard())
-		return
-
-	case "principal":
-		p, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64)
-		if err != nil || p <= 0 {
-			h.Send(chatID, "Неверная сумма. Введите сумму (например 1230000):", BackKeyboard())
-			return
-		}
-		data["principal"] = p
-		data["step"] = "rate"
-		h.fsm.Set(userID, "CREDIT_ADD", data)
-		h.Send(chatID, "Введите годовую процентную ставку (например 12.5):", BackKeyboard())
-		return
-
-	case "rate":
-		r, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64)
-		if err != nil || r < 0 {
-			h.Send(chatID, "Неверная ставка. Введите число (например 12.5):", BackKeyboard())
-			return
-		}
-		data["rate"] = r
-		data["step"] = "months"
-		h.fsm.Set(userID, "CREDIT_ADD", data)
-		h.Send(chatID, "Введите срок кредита в месяцах (например 36):", BackKeyboard())
-		return
-
-	case "months":
-		m, err := strconv.Atoi(text)
-		if err != nil || m <= 0 {
-			h.Send(chatID, "Неверный срок. Введите целое число месяцев:", BackKeyboard())
-			return
-		}
-		data["months"] = m
-		// финальный шаг — сохраняем
-		credit := &credits.Credit{
-			UserID:    userID,
-			Title:     data["title"].(string),
-			Principal: data["principal"].(float64),
-			Rate:      data["rate"].(float64),
-			Months:    m,
-		}
-
-		id, err := h.credits.Add(context.Background(), credit)
-		if err != nil {
-			logger.Error("credit save error: " + err.Error())
-			h.Send(chatID, "Ошибка при сохранении кредита", CreditsKeyboard())
-			h.fsm.Clear(userID)
-			return
-		}
-
-		msg := fmt.Sprintf("Кредит сохранён. ID: %d\n%s — %.2f ₽, ставка %.2f%%, %d мес.",
-			id, credit.Title, credit.Principal, credit.Rate, credit.Months)
-		h.Send(chatID, msg, CreditsKeyboard())
-		h.fsm.Clear(userID)
-		return
-	}
+	return fmt.Sprintf("Кредит сохранён. ID: %d\n%s — %.2f ₽, ставка %.2f%%, %d мес.",
+		id, credit.Title, credit.Principal, credit.Rate, credit.Months), nil
 }
 
 // showCreditList — выводит список кредитов пользователя
 func (h *Handler) showCreditList(userID int64) {
 	chatID := userID
-	ctx := context.Background()
 
-	list, err := h.credits.List(ctx, userID)
+	text, kb, err := h.renderCreditList(userID)
 	if err != nil {
 		logger.Error("credits list error: " + err.Error())
 		h.Send(chatID, "Ошибка получения списка кредитов", CreditsKeyboard())
 		return
 	}
 
-	if len(list) == 0 {
-		h.Send(chatID, "Кредитов нет", CreditsKeyboard())
+	if len(kb.InlineKeyboard) == 0 {
+		h.Send(chatID, text, CreditsKeyboard())
 		return
 	}
+	h.SendInline(chatID, text, kb)
+}
+
+// renderCreditList формирует список кредитов с кнопками графика, копирования и закрытия.
+func (h *Handler) renderCreditList(userID int64) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	list, err := h.credits.List(context.Background(), userID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	if len(list) == 0 {
+		return "Кредитов нет", tgbotapi.InlineKeyboardMarkup{}, nil
+	}
 
 	var b strings.Builder
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(list))
 	b.WriteString("Ваши кредиты:\n\n")
 	for _, c := range list {
 		line := fmt.Sprintf("ID:%d • %s\nСумма: %.2f ₽ • %.2f%% • %d мес.\n\n", c.ID, c.Title, c.Principal, c.Rate, c.Months)
 		b.WriteString(line)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			callbackButton("📆 "+truncate(c.Title, 24), cbCredit, actSchedule, c.ID),
+			callbackButton("📋", cbCredit, actCopy, c.ID),
+			callbackButton("❌", cbCredit, actDelete, c.ID),
+		))
 	}
-	b.WriteString("Чтобы посмотреть график платежей: /payments <id>\nЧтобы скопировать кредит: /copy_credit <id>\nЧтобы закрыть кредит: /close_credit <id>")
-	h.Send(chatID, b.String(), CreditsKeyboard())
+	b.WriteString("📆 — график платежей, 📋 — копировать, ❌ — закрыть кредит.\nТакже доступны команды /payments <id>, /copy_credit <id>, /close_credit <id>")
+
+	return b.String(), tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// registerCreditCallbacks регистрирует inline-действия списка кредитов.
+func (h *Handler) registerCreditCallbacks(r *callbackRouter) {
+	r.Handle(cbCredit, actSchedule, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		found, err := h.credits.GetByID(context.Background(), data.Arg(0), cb.From.ID)
+		if err != nil {
+			h.answerCallback(cb, "Кредит не найден")
+			return
+		}
+		h.answerCallback(cb, "")
+		kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			callbackButton("◀️ К списку", cbCredit, actList),
+		))
+		h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, creditScheduleText(found), kb)
+	})
+	r.Handle(cbCredit, actCopy, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		if _, err := h.credits.Copy(context.Background(), data.Arg(0), cb.From.ID); err != nil {
+			logger.Error("credit copy error: " + err.Error())
+			h.answerCallback(cb, "Ошибка копирования кредита")
+			return
+		}
+		h.answerCallback(cb, "📋 Кредит скопирован")
+		h.refreshCreditList(cb)
+	})
+	r.Handle(cbCredit, actDelete, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		if err := h.credits.Delete(context.Background(), data.Arg(0), cb.From.ID); err != nil {
+			logger.Error("credit close error: " + err.Error())
+			h.answerCallback(cb, "Ошибка закрытия кредита")
+			return
+		}
+		h.answerCallback(cb, "❌ Кредит закрыт")
+		h.refreshCreditList(cb)
+	})
+	r.Handle(cbCredit, actList, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		h.answerCallback(cb, "")
+		h.refreshCreditList(cb)
+	})
+}
+
+// refreshCreditList перерисовывает список кредитов в сообщении с нажатой кнопкой.
+func (h *Handler) refreshCreditList(cb *tgbotapi.CallbackQuery) {
+	text, kb, err := h.renderCreditList(cb.From.ID)
+	if err != nil {
+		logger.Error("credits list error: " + err.Error())
+		return
+	}
+	h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, text, kb)
 }
 
 // handlePaymentsCommand — парсит /payments <id> и отправляет график
@@ -161,10 +216,15 @@ func (h *Handler) handlePaymentsCommand(update tgbotapi.Update) {
 		return
 	}
 
+	h.Send(chatID, creditScheduleText(found), CreditsKeyboard())
+}
+
+// creditScheduleText форматирует первые 12 платежей аннуитетного графика по кредиту.
+func creditScheduleText(found *credits.Credit) string {
 	// считаем график (аннуитет)
 	schedule := calcAnnuitySchedule(found.Principal, found.Rate, found.Months, time.Now())
 
-	// форматируем и отправляем первые N строк (чтобы не спамить)
+	// форматируем первые N строк (чтобы не спамить)
 	var b strings.Builder
 	b.WriteString(fmt.Sprintf("График платежей по кредиту %s (ID:%d)\n\n", found.Title, found.ID))
 	total := 0.0
@@ -184,7 +244,7 @@ func (h *Handler) handlePaymentsCommand(update tgbotapi.Update) {
 		}
 	}
 
-	h.Send(chatID, b.String(), CreditsKeyboard())
+	return b.String()
 }
 
 // calcAnnuitySchedule — возвращает список платежей (аннуитет)
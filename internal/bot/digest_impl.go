@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/digest"
+	"tg_bot_asist/internal/logger"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const digestHelp = "Команды:\n" +
+	"/digest on | off — включить или выключить\n" +
+	"/digest 08:30 — время отправки\n" +
+	"/digest days 3 — за сколько дней показывать платежи\n" +
+	"/digest budget 2000 — дневной бюджет расходов (0 — без бюджета)\n" +
+	"/digest now — показать сводку сейчас"
+
+// handleDigestCommand — /digest [on|off|ЧЧ:ММ|days N|budget X|now], настройки утренней сводки.
+func (h *Handler) handleDigestCommand(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+	ctx := context.Background()
+	parts := strings.Fields(update.Message.Text)
+
+	st, err := h.digest.Settings(ctx, userID)
+	if err != nil {
+		h.Send(chatID, "Ошибка загрузки настроек сводки", HomeKeyboard())
+		return
+	}
+
+	if len(parts) < 2 {
+		h.Send(chatID, describeDigest(st)+"\n\n"+digestHelp, HomeKeyboard())
+		return
+	}
+
+	switch arg := strings.ToLower(parts[1]); {
+	case arg == "now":
+		text, err := h.digest.Build(ctx, st, time.Now())
+		if err != nil {
+			logger.Error("Digest build error: " + err.Error())
+			h.Send(chatID, "Ошибка при подготовке сводки", HomeKeyboard())
+			return
+		}
+		h.Send(chatID, text, HomeKeyboard())
+		return
+	case arg == "on":
+		st.Enabled = true
+	case arg == "off":
+		st.Enabled = false
+	case arg == "days" && len(parts) == 3:
+		n, err := strconv.Atoi(parts[2])
+		if err != nil {
+			h.Send(chatID, "Укажите число дней, например /digest days 3", HomeKeyboard())
+			return
+		}
+		st.DaysAhead = n
+	case arg == "budget" && len(parts) == 3:
+		v, err := strconv.ParseFloat(strings.ReplaceAll(parts[2], ",", "."), 64)
+		if err != nil {
+			h.Send(chatID, "Укажите сумму, например /digest budget 2000", HomeKeyboard())
+			return
+		}
+		st.DailyBudget = v
+	default:
+		minute, err := digest.ParseSendTime(parts[1])
+		if err != nil {
+			h.Send(chatID, err.Error()+"\n\n"+digestHelp, HomeKeyboard())
+			return
+		}
+		st.SendMinute = minute
+		st.Enabled = true
+	}
+
+	if err := h.digest.SaveSettings(ctx, st); err != nil {
+		h.Send(chatID, "Не удалось сохранить: "+err.Error(), HomeKeyboard())
+		return
+	}
+	h.Send(chatID, "Сохранено.\n\n"+describeDigest(st), HomeKeyboard())
+}
+
+// describeDigest — текущие настройки сводки для пользователя.
+func describeDigest(st digest.Settings) string {
+	status := "выключена"
+	if st.Enabled {
+		status = "включена, отправка в " + st.SendTime()
+	}
+	text := fmt.Sprintf("☀️ Утренняя сводка: %s\nПлатежи на %d дн. вперёд", status, st.DaysAhead)
+	if st.DailyBudget > 0 {
+		text += fmt.Sprintf("\nДневной бюджет: %.2f ₽", st.DailyBudget)
+	}
+	return text
+}
@@ -0,0 +1,176 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tg_bot_asist/internal/attachment"
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/logger"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Изменение и удаление операций кнопками ✏️ и 🗑 в списке операций.
+// Редактирование проходит те же шаги, что и добавление; текущее значение поля
+// предлагается кнопкой, чтобы оставить его без изменений. Все изменения попадают в историю.
+
+// wizFinanceEdit — имя мастера (и состояния FSM) редактирования операции.
+const wizFinanceEdit = "FIN_EDIT"
+
+// financeEditWizard — мастер редактирования операции; ID операции хранится в data["id"].
+func (h *Handler) financeEditWizard() *wizard {
+	steps := h.financeSteps()
+	for i := range steps {
+		s := &steps[i]
+		prompt := s.Prompt
+		s.Prompt = func(data map[string]any) string {
+			return prompt(data) + "\nЧтобы оставить текущее значение, нажмите кнопку с ним."
+		}
+		switch s.Key {
+		case "amount":
+			s.Options = func(data map[string]any) []string {
+				return []string{strconv.FormatFloat(getFloat(data, "amount"), 'f', -1, 64)}
+			}
+		case "category":
+			s.Options = func(data map[string]any) []string {
+				current := getString(data, "category")
+				opts := []string{current}
+				for i, c := range h.keyboardCategories(data) {
+					if i == maxCategoryButtons {
+						break
+					}
+					if !strings.EqualFold(c.Name, current) {
+						opts = append(opts, c.Label())
+					}
+				}
+				return opts
+			}
+		case "description":
+			s.Options = func(data map[string]any) []string {
+				if note := getString(data, "description"); note != "" {
+					return []string{note, "-"}
+				}
+				return []string{"-"}
+			}
+		case "date":
+			parse := s.Parse
+			s.Options = func(data map[string]any) []string {
+				return []string{h.formatEntryDate(data), "Сегодня", "Вчера"}
+			}
+			s.Parse = func(text string, data map[string]any) (any, error) {
+				// Текущую дату оставляем как есть, не сбрасывая время операции на полдень.
+				if text == h.formatEntryDate(data) {
+					return getTime(data, "date"), nil
+				}
+				return parse(text, data)
+			}
+		}
+	}
+
+	return &wizard{
+		Name:  wizFinanceEdit,
+		Steps: steps,
+		Submit: func(userID int64, data map[string]any) (string, error) {
+			id, _ := data["id"].(int)
+			amount := getFloat(data, "amount")
+			category := getString(data, "category")
+			typ := getString(data, "type")
+			note := getString(data, "description")
+			occurred := getTime(data, "date")
+
+			entry, err := h.finance.UpdateEntry(context.Background(), userID, id, finance.EntryPatch{
+				Amount:     &amount,
+				Category:   &category,
+				Type:       &typ,
+				Note:       &note,
+				OccurredAt: &occurred,
+			})
+			if err != nil {
+				if errors.Is(err, finance.ErrNotFound) {
+					return "Операция не найдена — возможно, она уже удалена.", nil
+				}
+				return "", err
+			}
+			data[wizardCreatedKey] = entry.ID
+			return fmt.Sprintf("✏️ Операция обновлена:\n%s на сумму %.2f ₽\nКатегория: %s\nДата: %s",
+				financeTypeNames[entry.Type], entry.Amount, entry.Category,
+				entry.OccurredAt.In(h.userLocation(userID)).Format("02.01.2006")), nil
+		},
+		Keyboard: FinanceKeyboard,
+		Attach:   attachment.OwnerFinance,
+	}
+}
+
+// startFinanceEdit запускает мастер редактирования с текущими значениями операции.
+func (h *Handler) startFinanceEdit(userID int64, id int) error {
+	e, err := h.finance.GetEntry(context.Background(), userID, id)
+	if err != nil {
+		return err
+	}
+	h.startWizard(userID, h.wizards[wizFinanceEdit], map[string]any{
+		"id":          e.ID,
+		"type":        e.Type,
+		"amount":      e.Amount,
+		"category":    e.Category,
+		"description": e.Note,
+		"date":        e.OccurredAt,
+	})
+	return nil
+}
+
+// formatEntryDate — дата операции из данных мастера в часовом поясе пользователя.
+func (h *Handler) formatEntryDate(data map[string]any) string {
+	return getTime(data, "date").In(h.userLocation(wizardUser(data))).Format("02.01.2006")
+}
+
+// financeEntryRow — кнопки изменения и удаления операции в списке; page — страница для перерисовки после удаления.
+func financeEntryRow(e *finance.FinanceEntry, page int) []tgbotapi.InlineKeyboardButton {
+	sign := "+"
+	if e.Type == finance.TypeExpense {
+		sign = "-"
+	}
+	title := fmt.Sprintf("✏️ %s%.2f ₽ %s", sign, e.Amount, truncate(e.Category, 16))
+	return tgbotapi.NewInlineKeyboardRow(
+		callbackButton(title, cbFinance, actEdit, e.ID),
+		callbackButton("🗑", cbFinance, actDelete, e.ID, page),
+	)
+}
+
+// registerFinanceEditCallbacks регистрирует кнопки изменения и удаления операций.
+func (h *Handler) registerFinanceEditCallbacks(r *callbackRouter) {
+	r.Handle(cbFinance, actEdit, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		if err := h.startFinanceEdit(cb.From.ID, data.Arg(0)); err != nil {
+			h.answerFinanceError(cb, err)
+			return
+		}
+		h.answerCallback(cb, "")
+	})
+	r.Handle(cbFinance, actDelete, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		if _, err := h.finance.DeleteEntry(context.Background(), cb.From.ID, data.Arg(0)); err != nil {
+			h.answerFinanceError(cb, err)
+			return
+		}
+		h.answerCallback(cb, "🗑 Операция удалена")
+
+		text, kb, err := h.renderFinanceList(cb.From.ID, data.Arg(1))
+		if err != nil {
+			logger.Error("Finance list error: " + err.Error())
+			return
+		}
+		h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, text, kb)
+	})
+}
+
+// answerFinanceError отвечает на нажатие кнопки операции текстом ошибки.
+func (h *Handler) answerFinanceError(cb *tgbotapi.CallbackQuery, err error) {
+	if errors.Is(err, finance.ErrNotFound) {
+		h.answerCallback(cb, "Операция не найдена")
+		return
+	}
+	logger.Error("Finance callback failed: " + err.Error())
+	h.answerCallback(cb, "Ошибка, попробуйте позже")
+}
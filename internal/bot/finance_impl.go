@@ -2,11 +2,12 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
+	"tg_bot_asist/internal/attachment"
 	"tg_bot_asist/internal/finance"
 	"tg_bot_asist/internal/logger"
 
@@ -28,6 +29,13 @@ func getFloat(m map[string]any, key string) float64 {
 	return 0
 }
 
+func getTime(m map[string]any, key string) time.Time {
+	if v, ok := m[key].(time.Time); ok {
+		return v
+	}
+	return time.Time{}
+}
+
 // =============================
 // FSM: ADD_FINANCE
 // =============================
@@ -40,173 +48,235 @@ type financeDraft struct {
 	Date        time.Time
 }
 
-// financeStart создаёт первую ступень FSM: выбор доход/расход.
-func (h *Handler) financeStart(userID int64, msgID int) {
-	draft := &financeDraft{}
-	h.fsm.Set(userID, "FIN_ADD_TYPE", map[string]any{
-		"type":        draft.Type,
-		"category":    draft.Category,
-		"amount":      draft.Amount,
-		"description": draft.Description,
-	})
-	h.Send(userID, "Выберите тип операции:\nДоход или Расход", BackKeyboard())
+// wizFinanceAdd — имя мастера (и состояния FSM) добавления операции.
+const wizFinanceAdd = "FIN_ADD"
+
+// financeTypeNames — подписи типов операций.
+var financeTypeNames = map[string]string{"income": "Доход", "expense": "Расход"}
+
+// financeSteps — шаги мастеров добавления и редактирования операции: тип, сумма, категория, описание, дата.
+func (h *Handler) financeSteps() []wizardStep {
+	return []wizardStep{
+		{
+			Key:     "type",
+			Label:   "Тип",
+			Prompt:  staticPrompt("Выберите тип операции:\nДоход или Расход"),
+			Options: staticOptions("Доход", "Расход"),
+			Parse: func(text string, _ map[string]any) (any, error) {
+				switch text {
+				case "Доход":
+					return "income", nil
+				case "Расход":
+					return "expense", nil
+				}
+				return nil, errors.New("Выберите тип: Доход или Расход")
+			},
+			Format: func(v any, _ map[string]any) string { return financeTypeNames[v.(string)] },
+		},
+		{
+			Key:    "amount",
+			Label:  "Сумма",
+			Prompt: staticPrompt("Введите сумму операции:"),
+			Parse:  parsePositiveFloat("Введите корректную сумму:"),
+			Format: formatRubles,
+		},
+		{
+			Key:     "category",
+			Label:   "Категория",
+			Prompt:  staticPrompt("Выберите категорию или введите новую:"),
+			Options: h.categoryOptions,
+			Parse: func(text string, data map[string]any) (any, error) {
+				if c, ok := finance.MatchCategory(h.keyboardCategories(data), text); ok {
+					return c.Name, nil
+				}
+				name, err := finance.NormalizeCategoryName(text)
+				if err != nil {
+					return nil, errors.New("Категория не может быть пустой или длиннее 64 символов. Введите категорию:")
+				}
+				return name, nil
+			},
+		},
+		{
+			Key:     "description",
+			Label:   "Описание",
+			Prompt:  staticPrompt("Введите описание (или '-' если нет):"),
+			Options: staticOptions("-"),
+			Parse: func(text string, _ map[string]any) (any, error) {
+				if text == "-" {
+					return "", nil
+				}
+				return text, nil
+			},
+			Format: func(v any, _ map[string]any) string {
+				if v == "" {
+					return "—"
+				}
+				return v.(string)
+			},
+		},
+		{
+			Key:     "date",
+			Label:   "Дата",
+			Prompt:  staticPrompt("Когда была операция?\n«сегодня», «вчера», «позавчера» или дата: 12.03, 12.03.2025"),
+			Options: staticOptions("Сегодня", "Вчера"),
+			Parse: func(text string, data map[string]any) (any, error) {
+				now := time.Now().In(h.userLocation(wizardUser(data)))
+				t, err := finance.ParseOccurredDate(text, now)
+				if errors.Is(err, finance.ErrFutureDate) {
+					return nil, errors.New("Дата операции не может быть в будущем. Введите дату:")
+				}
+				if err != nil {
+					return nil, errors.New("Не понял дату. Введите «сегодня», «вчера» или дату в формате ДД.ММ:")
+				}
+				return t, nil
+			},
+			Format: func(v any, data map[string]any) string {
+				return v.(time.Time).In(h.userLocation(wizardUser(data))).Format("02.01.2006")
+			},
+		},
+	}
 }
 
-// Обработка всех этапов FSM по фин. операциям
-func (h *Handler) handleFinanceFSM(update tgbotapi.Update) {
-	if update.Message == nil {
-		return
-	}
+// maxCategoryButtons — сколько категорий показывать кнопками; остальные можно ввести текстом.
+const maxCategoryButtons = 12
 
-	userID := update.Message.From.ID
-	text := strings.TrimSpace(update.Message.Text)
-	state := h.fsm.Get(userID)
-	if state == nil {
-		return
+// keyboardCategories возвращает категории выбранного в мастере типа, часто используемые первыми.
+func (h *Handler) keyboardCategories(data map[string]any) []finance.Category {
+	list, err := h.finance.KeyboardCategories(context.Background(), wizardUser(data), getString(data, "type"))
+	if err != nil {
+		logger.Error("Finance categories error: " + err.Error())
+		return nil
 	}
+	return list
+}
 
-	// state.Data уже map[string]any, используем напрямую
-	data := state.Data
-	if data == nil {
-		return
+// categoryOptions — кнопки категорий для шага «Категория».
+func (h *Handler) categoryOptions(data map[string]any) []string {
+	list := h.keyboardCategories(data)
+	opts := make([]string, 0, min(len(list), maxCategoryButtons))
+	for i := 0; i < len(list) && i < maxCategoryButtons; i++ {
+		opts = append(opts, list[i].Label())
 	}
+	return opts
+}
 
-	// Восстанавливаем draft из map
-	draft := &financeDraft{
-		Type:        getString(data, "type"),
-		Category:    getString(data, "category"),
-		Amount:      getFloat(data, "amount"),
-		Description: getString(data, "description"),
+// financeAddWizard — мастер добавления операции.
+func (h *Handler) financeAddWizard() *wizard {
+	return &wizard{
+		Name:  wizFinanceAdd,
+		Steps: h.financeSteps(),
+		Submit: func(userID int64, data map[string]any) (string, error) {
+			entry, err := h.saveFinance(userID, &financeDraft{
+				Type:        getString(data, "type"),
+				Category:    getString(data, "category"),
+				Amount:      getFloat(data, "amount"),
+				Description: getString(data, "description"),
+				Date:        getTime(data, "date"),
+			})
+			if err != nil {
+				return "", err
+			}
+			data[wizardCreatedKey] = entry.ID
+			return financeSavedText(entry, h.userLocation(userID)), nil
+		},
+		Keyboard: FinanceKeyboard,
+		Attach:   attachment.OwnerFinance,
 	}
+}
 
-	switch state.Name {
-
-	case "FIN_ADD_TYPE":
-		// user picked income/expense
-		if text == "Доход" {
-			draft.Type = "income"
-		} else if text == "Расход" {
-			draft.Type = "expense"
-		} else {
-			h.Send(userID, "Выберите тип: Доход или Расход", BackKeyboard())
-			return
-		}
-
-		h.fsm.Set(userID, "FIN_ADD_AMOUNT", map[string]any{
-			"type":        draft.Type,
-			"category":    draft.Category,
-			"amount":      draft.Amount,
-			"description": draft.Description,
-		})
-		h.Send(userID, "Введите сумму операции:", BackKeyboard())
-		return
-
-	case "FIN_ADD_AMOUNT":
-		val, err := strconv.ParseFloat(text, 64)
-		if err != nil || val <= 0 {
-			h.Send(userID, "Введите корректную сумму:", BackKeyboard())
-			return
-		}
-		draft.Amount = val
-
-		h.fsm.Set(userID, "FIN_ADD_CATEGORY", map[string]any{
-			"type":        draft.Type,
-			"category":    draft.Category,
-			"amount":      draft.Amount,
-			"description": draft.Description,
-		})
-		h.Send(userID, "Введите категорию операции:", BackKeyboard())
-		return
-
-	case "FIN_ADD_CATEGORY":
-		if text == "" {
-			h.Send(userID, "Категория не может быть пустой. Введите категорию:", BackKeyboard())
-			return
-		}
-		draft.Category = text
-
-		h.fsm.Set(userID, "FIN_ADD_DESC", map[string]any{
-			"type":        draft.Type,
-			"category":    draft.Category,
-			"amount":      draft.Amount,
-			"description": draft.Description,
-		})
-		h.Send(userID, "Введите описание (или '-' если нет):", BackKeyboard())
-		return
-
-	case "FIN_ADD_DESC":
-		if text != "-" {
-			draft.Description = text
-		}
-
-		// Готовим финальный шаг: сохраняем
-		h.saveFinance(userID, draft)
-		h.fsm.Clear(userID)
-		return
-	}
+// financeStart запускает мастер добавления операции.
+func (h *Handler) financeStart(userID int64, msgID int) {
+	h.startWizard(userID, h.wizards[wizFinanceAdd], nil)
 }
 
-func (h *Handler) saveFinance(userID int64, d *financeDraft) {
+// saveFinance сохраняет операцию, собранную мастером.
+func (h *Handler) saveFinance(userID int64, d *financeDraft) (*finance.FinanceEntry, error) {
 	ctx := context.Background()
 
 	entry := &finance.FinanceEntry{
-		UserID:    userID,
-		Amount:    d.Amount,
-		Category:  d.Category,
-		Type:      d.Type,
-		Note:      d.Description,
-		CreatedAt: time.Now(),
+		UserID:     userID,
+		Amount:     d.Amount,
+		Category:   d.Category,
+		Type:       d.Type,
+		Note:       d.Description,
+		CreatedAt:  time.Now(),
+		OccurredAt: d.Date,
 	}
 
-	err := h.finance.AddEntry(ctx, entry)
-	if err != nil {
-		logger.Error("Finance save error: " + err.Error())
-		h.Send(userID, "Ошибка при сохранении операции", FinanceKeyboard())
-		return
+	if err := h.finance.AddEntry(ctx, entry); err != nil {
+		return nil, err
 	}
+	return entry, nil
+}
 
-	msg := fmt.Sprintf(
-		"%s на сумму %.2f ₽ сохранён.\nКатегория: %s",
-		map[string]string{"income": "Доход", "expense": "Расход"}[d.Type],
-		d.Amount,
-		d.Category,
+// financeSavedText — сообщение о сохранённой операции с подсказкой, как прикрепить чек.
+func financeSavedText(e *finance.FinanceEntry, loc *time.Location) string {
+	return fmt.Sprintf(
+		"%s на сумму %.2f ₽ сохранён.\nКатегория: %s\nДата: %s\n\n%s",
+		financeTypeNames[e.Type],
+		e.Amount,
+		e.Category,
+		e.OccurredAt.In(loc).Format("02.01.2006"),
+		attachHint(attachment.OwnerFinance, e.ID),
 	)
-
-	h.Send(userID, msg, FinanceKeyboard())
 }
 
 // ===========================
 // Список операций
 // ===========================
 
-func (h *Handler) showFinanceList(userID int64) {
-	ctx := context.Background()
+// financePageSize — количество операций на одной странице списка.
+const financePageSize = 10
 
-	ops, err := h.finance.ListEntries(ctx, userID)
+func (h *Handler) showFinanceList(userID int64) {
+	text, kb, err := h.renderFinanceList(userID, 0)
 	if err != nil {
 		logger.Error("Finance list error: " + err.Error())
 		h.Send(userID, "Ошибка получения списка финансов", FinanceKeyboard())
 		return
 	}
 
-	if len(ops) == 0 {
-		h.Send(userID, "У вас пока нет операций.", FinanceKeyboard())
+	if len(kb.InlineKeyboard) == 0 {
+		h.Send(userID, text, FinanceKeyboard())
 		return
 	}
+	h.SendInline(userID, text, kb)
+}
 
-	var b strings.Builder
-	b.WriteString("Ваши операции:\n\n")
+// renderFinanceList формирует страницу списка операций с итогами и кнопками навигации.
+func (h *Handler) renderFinanceList(userID int64, page int) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	ctx := context.Background()
+
+	totals, err := h.finance.AllTimeTotals(ctx, userID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	if totals.Count == 0 {
+		return "У вас пока нет операций.", tgbotapi.InlineKeyboardMarkup{}, nil
+	}
+
+	pages := (totals.Count + financePageSize - 1) / financePageSize
+	if page < 0 {
+		page = 0
+	}
+	if page >= pages {
+		page = pages - 1
+	}
 
-	totalIncome := 0.0
-	totalExpense := 0.0
+	ops, err := h.finance.EntriesPage(ctx, userID, financePageSize, page*financePageSize)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	loc := h.userLocation(userID)
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Ваши операции (стр. %d/%d):\n\n", page+1, pages))
 
 	for _, op := range ops {
 		sign := "+"
 		if op.Type == "expense" {
 			sign = "-"
-			totalExpense += op.Amount
-		} else {
-			totalIncome += op.Amount
 		}
 
 		line := fmt.Sprintf(
@@ -214,15 +284,50 @@ func (h *Handler) showFinanceList(userID int64) {
 			sign,
 			op.Amount,
 			op.Category,
-			op.CreatedAt.Format("02.01.2006"),
+			op.OccurredAt.In(loc).Format("02.01.2006"),
 		)
 		b.WriteString(line)
 	}
 
 	b.WriteString("\nИтоги:\n")
-	b.WriteString(fmt.Sprintf("Доходы: %.2f ₽\n", totalIncome))
-	b.WriteString(fmt.Sprintf("Расходы: %.2f ₽\n", totalExpense))
-	b.WriteString(fmt.Sprintf("Баланс: %.2f ₽\n", totalIncome-totalExpense))
+	b.WriteString(fmt.Sprintf("Доходы: %.2f ₽\n", totals.Income))
+	b.WriteString(fmt.Sprintf("Расходы: %.2f ₽\n", totals.Expense))
+	b.WriteString(fmt.Sprintf("Баланс: %.2f ₽\n", totals.Balance()))
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(ops)+1)
+	for _, op := range ops {
+		rows = append(rows, financeEntryRow(op, page))
+	}
+	rows = append(rows, pagerKeyboard(cbFinance, page, pages).InlineKeyboard...)
+
+	return b.String(), tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
 
-	h.Send(userID, b.String(), FinanceKeyboard())
+// pagerKeyboard — кнопки «назад/вперёд» для постраничного списка.
+func pagerKeyboard(module string, page, pages int) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		row = append(row, callbackButton("◀️", module, actPage, page-1))
+	}
+	if page < pages-1 {
+		row = append(row, callbackButton("▶️", module, actPage, page+1))
+	}
+	if len(row) == 0 {
+		return tgbotapi.InlineKeyboardMarkup{}
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
+
+// registerFinanceCallbacks регистрирует inline-действия списка операций.
+func (h *Handler) registerFinanceCallbacks(r *callbackRouter) {
+	r.Handle(cbFinance, actPage, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		h.answerCallback(cb, "")
+		text, kb, err := h.renderFinanceList(cb.From.ID, data.Arg(0))
+		if err != nil {
+			logger.Error("Finance list error: " + err.Error())
+			return
+		}
+		h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, text, kb)
+	})
+	h.registerFinanceEditCallbacks(r)
 }
@@ -0,0 +1,263 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/logger"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Отчёты по финансам за период: кнопка «📈 Отчёт» (текущий месяц) и /report [период].
+// Под отчётом — кнопки ◀️/▶️ для соседних периодов и переключатель День/Неделя/Месяц/Год.
+// Период в callback data кодируется видом (индекс в reportKinds) и днями начала и конца
+// (номер дня от 1970-01-01), чтобы уложиться в 64 байта.
+
+// reportKinds — виды периодов в callback data; произвольный период — последний.
+var reportKinds = append(append([]finance.PeriodKind{}, finance.PeriodKinds...), finance.PeriodCustom)
+
+// reportKindNames — подписи кнопок переключения вида периода.
+var reportKindNames = map[finance.PeriodKind]string{
+	finance.PeriodDay:   "День",
+	finance.PeriodWeek:  "Неделя",
+	finance.PeriodMonth: "Месяц",
+	finance.PeriodYear:  "Год",
+}
+
+// reportKindAliases — аргументы /report для видов периода.
+var reportKindAliases = map[string]finance.PeriodKind{
+	"day": finance.PeriodDay, "день": finance.PeriodDay, "сегодня": finance.PeriodDay,
+	"week": finance.PeriodWeek, "неделя": finance.PeriodWeek,
+	"month": finance.PeriodMonth, "месяц": finance.PeriodMonth,
+	"year": finance.PeriodYear, "год": finance.PeriodYear, "ytd": finance.PeriodYear,
+}
+
+const reportUsage = "Использование: /report [день | неделя | месяц | год | 01.03-15.03]\n" +
+	"Без аргумента — отчёт за текущий месяц. Интервал — не длиннее года."
+
+// handleReportCommand обрабатывает /report [период].
+func (h *Handler) handleReportCommand(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	now := time.Now().In(h.userLocation(userID))
+
+	period, err := parseReportPeriod(strings.TrimSpace(strings.TrimPrefix(update.Message.Text, CmdReport)), now)
+	if err != nil {
+		h.Send(userID, reportUsage, FinanceKeyboard())
+		return
+	}
+	h.showReport(userID, period)
+}
+
+// showMonthReport отправляет отчёт за текущий месяц (кнопка «📈 Отчёт»).
+func (h *Handler) showMonthReport(userID int64) {
+	now := time.Now().In(h.userLocation(userID))
+	period, _ := finance.NewPeriod(finance.PeriodMonth, now, now)
+	h.showReport(userID, period)
+}
+
+// parseReportPeriod разбирает аргумент /report: вид периода или интервал «с-по» в формате дат операций.
+func parseReportPeriod(arg string, now time.Time) (finance.Period, error) {
+	if arg == "" {
+		return finance.NewPeriod(finance.PeriodMonth, now, now)
+	}
+	if kind, ok := reportKindAliases[strings.ToLower(arg)]; ok {
+		return finance.NewPeriod(kind, now, now)
+	}
+
+	fromText, toText, ok := strings.Cut(arg, "-")
+	if !ok {
+		return finance.Period{}, finance.ErrInvalidPeriod
+	}
+	from, err := finance.ParseOccurredDate(fromText, now)
+	if err != nil {
+		return finance.Period{}, err
+	}
+	to, err := finance.ParseOccurredDate(toText, now)
+	if err != nil {
+		return finance.Period{}, err
+	}
+	return finance.CustomPeriod(from, to)
+}
+
+// showReport отправляет отчёт за период.
+func (h *Handler) showReport(userID int64, period finance.Period) {
+	text, kb, err := h.renderReport(userID, period)
+	if err != nil {
+		logger.Error("Finance report error: " + err.Error())
+		h.Send(userID, "Ошибка построения отчёта", FinanceKeyboard())
+		return
+	}
+	h.SendInline(userID, text, kb)
+}
+
+// renderReport формирует текст отчёта и кнопки навигации по периодам.
+func (h *Handler) renderReport(userID int64, period finance.Period) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	report, err := h.finance.Report(context.Background(), userID, period)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+	now := time.Now().In(period.From.Location())
+	return formatReport(report), reportKeyboard(period, now), nil
+}
+
+// formatReport — текст отчёта.
+func formatReport(r *finance.Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📈 Отчёт за %s\n\n", periodTitle(r.Period))
+
+	if r.Totals.Count == 0 {
+		fmt.Fprintf(&b, "Операций за период нет.\n")
+	}
+	fmt.Fprintf(&b, "Доходы: %.2f ₽%s\n", r.Totals.Income, changeSuffix(r.Totals.Income, r.PrevTotals.Income))
+	fmt.Fprintf(&b, "Расходы: %.2f ₽%s\n", r.Totals.Expense, changeSuffix(r.Totals.Expense, r.PrevTotals.Expense))
+	fmt.Fprintf(&b, "Баланс: %+.2f ₽\n", r.Totals.Balance())
+	fmt.Fprintf(&b, "Сравнение с периодом %s\n", periodTitle(r.Previous))
+
+	writeCategoryTotals(&b, "Расходы по категориям", r.Expenses)
+	writeCategoryTotals(&b, "Доходы по категориям", r.Incomes)
+
+	if len(r.Top) > 0 {
+		b.WriteString("\nКрупнейшие расходы:\n")
+		loc := r.Period.From.Location()
+		for i, e := range r.Top {
+			fmt.Fprintf(&b, "%d. %.2f ₽ — %s, %s", i+1, e.Amount, e.Category, e.OccurredAt.In(loc).Format("02.01"))
+			if e.Note != "" {
+				fmt.Fprintf(&b, " «%s»", truncate(e.Note, 40))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// writeCategoryTotals дописывает разбивку по категориям с долями и изменением к прошлому периоду.
+func writeCategoryTotals(b *strings.Builder, title string, list []finance.CategoryTotal) {
+	if len(list) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n%s:\n", title)
+	for _, c := range list {
+		name := c.Category
+		if c.Icon != "" {
+			name = c.Icon + " " + name
+		}
+		fmt.Fprintf(b, "%s — %.2f ₽ (%.0f%%)%s\n", name, c.Amount, c.Share, changeSuffix(c.Amount, c.PrevAmount))
+	}
+}
+
+// changeSuffix — изменение к прошлому периоду: « ▲ 12%», « ▼ 5%» или пусто, если сравнивать не с чем.
+func changeSuffix(cur, prev float64) string {
+	change, ok := finance.Change(cur, prev)
+	switch {
+	case !ok:
+		return ""
+	case change > 0:
+		return fmt.Sprintf(" ▲ %.0f%%", change)
+	case change < 0:
+		return fmt.Sprintf(" ▼ %.0f%%", -change)
+	}
+	return " = 0%"
+}
+
+// periodTitle — название периода: «17.10.2026», «12.10–18.10.2026», «10.2026», «2026 (с начала года)».
+func periodTitle(p finance.Period) string {
+	last := p.To.AddDate(0, 0, -1)
+	switch p.Kind {
+	case finance.PeriodDay:
+		return p.From.Format("02.01.2006")
+	case finance.PeriodMonth:
+		return p.From.Format("01.2006")
+	case finance.PeriodYear:
+		if last.Month() != time.December || last.Day() != 31 {
+			return fmt.Sprintf("%d (с начала года по %s)", p.From.Year(), last.Format("02.01"))
+		}
+		return fmt.Sprintf("%d", p.From.Year())
+	}
+	if p.From.Year() == last.Year() {
+		return p.From.Format("02.01") + "–" + last.Format("02.01.2006")
+	}
+	return p.From.Format("02.01.2006") + "–" + last.Format("02.01.2006")
+}
+
+// epochDay — номер дня t (по календарной дате в часовом поясе t) от 1970-01-01.
+func epochDay(t time.Time) int {
+	return int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// dayFromEpoch — полночь дня с номером n в часовом поясе loc.
+func dayFromEpoch(n int, loc *time.Location) time.Time {
+	d := time.Unix(int64(n)*86400, 0).UTC()
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc)
+}
+
+// reportButton — кнопка перехода к отчёту за период.
+func reportButton(text string, p finance.Period) tgbotapi.InlineKeyboardButton {
+	kind := 0
+	for i, k := range reportKinds {
+		if k == p.Kind {
+			kind = i
+		}
+	}
+	return callbackButton(text, cbReport, actPage, kind, epochDay(p.From), epochDay(p.To.AddDate(0, 0, -1)))
+}
+
+// reportKeyboard — кнопки соседних периодов и переключения вида периода.
+// Следующий период показывается, только если он уже начался.
+func reportKeyboard(p finance.Period, now time.Time) tgbotapi.InlineKeyboardMarkup {
+	nav := []tgbotapi.InlineKeyboardButton{reportButton("◀️", p.Shift(-1, now))}
+	if next := p.Shift(1, now); !next.From.After(now) {
+		nav = append(nav, reportButton("▶️", next))
+	}
+
+	var kinds []tgbotapi.InlineKeyboardButton
+	for _, kind := range finance.PeriodKinds {
+		title := reportKindNames[kind]
+		if kind == p.Kind {
+			title = "• " + title
+		}
+		other, _ := finance.NewPeriod(kind, now, now)
+		kinds = append(kinds, reportButton(title, other))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(nav, kinds)
+}
+
+// decodeReportPeriod восстанавливает период из аргументов кнопки.
+func decodeReportPeriod(data callbackData, now time.Time) (finance.Period, error) {
+	kind := data.Arg(0)
+	if kind < 0 || kind >= len(reportKinds) {
+		return finance.Period{}, finance.ErrInvalidPeriod
+	}
+	from := dayFromEpoch(data.Arg(1), now.Location())
+	if reportKinds[kind] == finance.PeriodCustom {
+		return finance.CustomPeriod(from, dayFromEpoch(data.Arg(2), now.Location()))
+	}
+	return finance.NewPeriod(reportKinds[kind], from, now)
+}
+
+// registerReportCallbacks регистрирует кнопки навигации отчёта.
+func (h *Handler) registerReportCallbacks(r *callbackRouter) {
+	r.Handle(cbReport, actPage, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		now := time.Now().In(h.userLocation(cb.From.ID))
+		period, err := decodeReportPeriod(data, now)
+		if errors.Is(err, finance.ErrInvalidPeriod) {
+			h.answerCallback(cb, "Действие устарело")
+			return
+		}
+		h.answerCallback(cb, "")
+
+		text, kb, err := h.renderReport(cb.From.ID, period)
+		if err != nil {
+			logger.Error("Finance report error: " + err.Error())
+			return
+		}
+		h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, text, kb)
+	})
+}
@@ -3,36 +3,63 @@ package bot
 import (
 	"strings"
 
+	"tg_bot_asist/internal/attachment"
+	"tg_bot_asist/internal/calendar"
 	"tg_bot_asist/internal/credits"
+	"tg_bot_asist/internal/digest"
 	"tg_bot_asist/internal/finance"
 	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/search"
+	"tg_bot_asist/internal/storage"
 	"tg_bot_asist/internal/todo"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 type Handler struct {
-	bot     *tgbotapi.BotAPI
-	fsm     *FSM
-	todo    *todo.Service
-	finance *finance.Service
-	credits *credits.Service
+	bot         *tgbotapi.BotAPI
+	fsm         *FSM
+	todo        *todo.Service
+	finance     *finance.Service
+	credits     *credits.Service
+	users       *storage.UserRepo
+	calendar    *calendar.Service
+	digest      *digest.Service
+	search      *search.Service
+	attachments *attachment.Service
+
+	callbacks *callbackRouter
+	wizards   map[string]*wizard
 }
 
-func NewHandler(
-	b *tgbotapi.BotAPI,
-	fsm *FSM,
-	todoSvc *todo.Service,
-	finSvc *finance.Service,
-	credSvc *credits.Service,
-) *Handler {
-	return &Handler{
-		bot:     b,
-		fsm:     fsm,
-		todo:    todoSvc,
-		finance: finSvc,
-		credits: credSvc,
+func NewHandler(b *tgbotapi.BotAPI, fsm *FSM, deps Deps) *Handler {
+	h := &Handler{
+		bot:         b,
+		fsm:         fsm,
+		todo:        deps.Todo,
+		finance:     deps.Finance,
+		credits:     deps.Credits,
+		users:       deps.Users,
+		calendar:    deps.Calendar,
+		digest:      deps.Digest,
+		search:      deps.Search,
+		attachments: deps.Attachments,
+		callbacks:   newCallbackRouter(),
+		wizards:     make(map[string]*wizard),
 	}
+	h.registerCallbacks()
+	h.registerWizards()
+	return h
+}
+
+// registerWizards регистрирует пошаговые диалоги модулей.
+func (h *Handler) registerWizards() {
+	h.registerWizard(h.todoAddWizard())
+	h.registerWizard(h.financeAddWizard())
+	h.registerWizard(h.financeEditWizard())
+	h.registerWizard(h.budgetSetWizard())
+	h.registerWizard(h.recurringAddWizard())
+	h.registerWizard(h.creditAddWizard())
 }
 
 // Send отправляет текстовое сообщение с клавиатурой пользователю.
@@ -44,16 +71,57 @@ func (h *Handler) Send(chatID int64, text string, kb tgbotapi.ReplyKeyboardMarku
 	}
 }
 
-// Edit отправляет сообщение с клавиатурой (используется вместо редактирования для ReplyKeyboard).
-func (h *Handler) Edit(chatID int64, messageID int, text string, kb tgbotapi.ReplyKeyboardMarkup) {
+// Edit редактирует ранее отправленное сообщение: заменяет текст и inline-клавиатуру.
+// ReplyKeyboard при редактировании изменить нельзя, поэтому используется только с inline-списками.
+func (h *Handler) Edit(chatID int64, messageID int, text string, kb tgbotapi.InlineKeyboardMarkup) {
+	msg := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	if len(kb.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = &kb
+	}
+	if _, err := h.bot.Send(msg); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		logger.Error("Failed to edit message: " + err.Error())
+	}
+}
+
+// SendInline отправляет текстовое сообщение с inline-клавиатурой.
+func (h *Handler) SendInline(chatID int64, text string, kb tgbotapi.InlineKeyboardMarkup) {
 	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ReplyMarkup = kb
+	if len(kb.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = kb
+	}
 	if _, err := h.bot.Send(msg); err != nil {
 		logger.Error("Failed to send message: " + err.Error())
 	}
 }
 
+// answerCallback подтверждает получение callback-запроса (убирает «часики» на кнопке).
+func (h *Handler) answerCallback(cb *tgbotapi.CallbackQuery, text string) {
+	if _, err := h.bot.Request(tgbotapi.NewCallback(cb.ID, text)); err != nil {
+		logger.Error("Failed to answer callback: " + err.Error())
+	}
+}
+
+// setState сохраняет состояние диалога. Если сохранить не удалось, диалог сбрасывается
+// и пользователь получает сообщение об ошибке; false — продолжать диалог не нужно.
+func (h *Handler) setState(userID int64, name string, data map[string]any) bool {
+	if err := h.fsm.Set(userID, name, data); err != nil {
+		logger.Error("State save error: " + err.Error())
+		h.fsm.Clear(userID)
+		h.Send(userID, "Ошибка, попробуйте позже", HomeKeyboard())
+		return false
+	}
+	return true
+}
+
+// startGreeting — ответ на /start.
+const startGreeting = "Привет! Я бот-помощник.\n\nИспользуйте меню для навигации или откройте веб-интерфейс через кнопку меню."
+
 func (h *Handler) Handle(update tgbotapi.Update) {
+	if update.CallbackQuery != nil {
+		h.handleCallback(update.CallbackQuery)
+		return
+	}
+
 	if update.Message == nil {
 		return
 	}
@@ -74,21 +142,28 @@ func (h *Handler) Handle(update tgbotapi.Update) {
 		return
 	}
 
+	// Фото, документы и голосовые прикрепляются к задачам и операциям
+	if f, ok := messageFile(update.Message); ok {
+		h.handleAttachment(update, f)
+		return
+	}
+
 	// FSM → переслать в нужный модуль
 	state := h.fsm.Get(userID)
 	if state != nil {
-		switch state.Name {
-		case "TODO_ADD":
-			h.handleTodoAdd(update)
+		if w, ok := h.wizards[state.Name]; ok {
+			h.handleWizard(w, update)
 			return
-		case "FIN_ADD_TYPE", "FIN_ADD_AMOUNT", "FIN_ADD_CATEGORY", "FIN_ADD_DESC":
-			h.handleFinanceFSM(update)
+		}
+		switch state.Name {
+		case "TODO_EDIT":
+			h.handleTodoEdit(update)
 			return
-		case "RECURRING_ADD":
-			h.handleRecurringAdd(update)
+		case stateTodoSnooze:
+			h.handleTodoSnooze(update)
 			return
-		case "CREDIT_ADD":
-			h.handleCreditAdd(update)
+		case stateTodoImport:
+			h.handleTodoImport(update)
 			return
 		}
 	}
@@ -97,7 +172,7 @@ func (h *Handler) Handle(update tgbotapi.Update) {
 	switch text {
 
 	case CmdStart:
-		h.Send(userID, "Привет! Я бот-помощник.\n\nИспользуйте меню для навигации или откройте веб-интерфейс через кнопку меню.", HomeKeyboard())
+		h.Send(userID, startGreeting, HomeKeyboard())
 
 	case CmdTodo:
 		h.Send(userID, "Модуль задач", TodoKeyboard())
@@ -119,6 +194,10 @@ func (h *Handler) Handle(update tgbotapi.Update) {
 		h.financeStart(userID, 0)
 	case CmdFinanceList:
 		h.showFinanceList(userID)
+	case CmdBudgets:
+		h.showBudgets(userID)
+	case CmdFinanceReport:
+		h.showMonthReport(userID)
 
 	case CmdRecurring:
 		h.Send(userID, "Регулярные платежи", RecurringKeyboard())
@@ -134,7 +213,9 @@ func (h *Handler) Handle(update tgbotapi.Update) {
 		h.showCreditList(userID)
 	default:
 		// Обработка команд с префиксом /
-		if strings.HasPrefix(text, "/payments") {
+		if strings.HasPrefix(text, CmdStart+" ") {
+			h.handleStartPayload(update)
+		} else if strings.HasPrefix(text, "/payments") {
 			h.handlePaymentsCommand(update)
 		} else if strings.HasPrefix(text, "/copy_credit") {
 			h.copyCreditCommand(update)
@@ -142,6 +223,26 @@ func (h *Handler) Handle(update tgbotapi.Update) {
 			h.closeCreditCommand(update)
 		} else if strings.HasPrefix(text, "/delete_recurring") {
 			h.handleDeleteRecurringCommand(update)
+		} else if isTodoCommand(text) {
+			h.handleTodoCommand(update)
+		} else if strings.HasPrefix(text, "/timezone") {
+			h.handleTimezoneCommand(update)
+		} else if strings.HasPrefix(text, CmdCalendar) {
+			h.handleCalendarCommand(update)
+		} else if strings.HasPrefix(text, CmdDigest) {
+			h.handleDigestCommand(update)
+		} else if strings.HasPrefix(text, CmdNudge) {
+			h.handleNudgeCommand(update)
+		} else if strings.HasPrefix(text, CmdTimer) {
+			h.handleTimerCommand(update)
+		} else if strings.HasPrefix(text, CmdTodoImport) {
+			h.handleImportCommand(update)
+		} else if strings.HasPrefix(text, CmdSearch) {
+			h.handleSearchCommand(update)
+		} else if strings.HasPrefix(text, CmdTodoStats) {
+			h.handleStatsCommand(update)
+		} else if strings.HasPrefix(text, CmdReport) {
+			h.handleReportCommand(update)
 		}
 	}
 }
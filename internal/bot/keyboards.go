@@ -47,6 +47,10 @@ func FinanceKeyboard() tgbotapi.ReplyKeyboardMarkup {
 			tgbotapi.NewKeyboardButton(CmdFinanceAdd),
 			tgbotapi.NewKeyboardButton(CmdFinanceList),
 		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(CmdFinanceReport),
+			tgbotapi.NewKeyboardButton(CmdBudgets),
+		),
 		tgbotapi.NewKeyboardButtonRow(
 			tgbotapi.NewKeyboardButton(CmdRecurring),
 		),
@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"context"
+
+	"tg_bot_asist/internal/todo"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Notifier отправляет уведомления пользователям от имени бота.
+// Используется планировщиками, которые работают вне обработки обновлений.
+type Notifier struct {
+	bot *tgbotapi.BotAPI
+}
+
+// NewNotifier создаёт новый Notifier.
+func NewNotifier(b *tgbotapi.BotAPI) *Notifier {
+	return &Notifier{bot: b}
+}
+
+// Notify отправляет текстовое сообщение пользователю.
+// В нашей архитектуре chatID == userID (private chat).
+func (n *Notifier) Notify(ctx context.Context, userID int64, text string) error {
+	msg := tgbotapi.NewMessage(userID, text)
+	_, err := n.bot.Send(msg)
+	return err
+}
+
+// NotifyTodo отправляет напоминание о задаче с кнопками «Отложить», «Выполнено» и «Принято».
+func (n *Notifier) NotifyTodo(ctx context.Context, item todo.Item, text string) error {
+	msg := tgbotapi.NewMessage(item.UserID, text)
+	msg.ReplyMarkup = reminderKeyboard(item.ID)
+	_, err := n.bot.Send(msg)
+	return err
+}
@@ -2,6 +2,7 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -17,120 +18,75 @@ import (
 // FSM / Recurring implementation
 // -----------------------------
 
-// startRecurringAdd — вызывается из Handler.startRecurringAdd (помеченного ранее)
-func (h *Handler) startRecurringAdd(userID int64) {
-	// подготовим пустой черновик
-	draft := map[string]any{
-		"title":       "",
-		"amount":      0.0,
-		"period":      "",
-		"payment_day": 0, // для monthly
-	}
-	h.fsm.Set(userID, "RECURRING_ADD", draft)
-
-	// Просим название
-	h.Send(userID, "Введите название регулярного платежа:", RecurringKeyboard())
-}
-
-// handleRecurringAdd — основной FSM-обработчик (вызывается из Handler.handle когда state active)
-func (h *Handler) handleRecurringAdd(update tgbotapi.Update) {
-	if update.Message == nil {
-		return
-	}
-
-	userID := update.Message.From.ID
-	chatID := update.Message.Chat.ID
-	text := strings.TrimSpace(update.Message.Text)
-
-	state := h.fsm.Get(userID)
-	if state == nil || state.Name != "RECURRING_ADD" {
-		h.Send(chatID, "Нет активного добавления регулярки.", RecurringKeyboard())
-		return
-	}
-
-	// draft у нас map[string]any, используем напрямую
-	draft := state.Data
-	if draft == nil {
-		// защита: восстановим структуру
-		draft = map[string]any{
-			"title":       "",
-			"amount":      0.0,
-			"period":      "",
-			"payment_day": 0,
-		}
-		h.fsm.Set(userID, "RECURRING_ADD", draft)
-	}
-
-	// определим текущий шаг по заполненным полям
-	// шаги: title -> amount -> period -> (payment_day if monthly) -> confirm (auto-save)
-	if draft["title"] == "" {
-		draft["title"] = text
-		h.fsm.Set(userID, "RECURRING_ADD", draft)
-		h.Send(chatID, "Введите сумму (числом), например: 1999.50", RecurringKeyboard())
-		return
-	}
+// wizRecurringAdd — имя мастера (и состояния FSM) добавления регулярного платежа.
+const wizRecurringAdd = "RECURRING_ADD"
 
-	if draft["amount"].(float64) == 0.0 {
-		// парсим сумму
-		s := strings.ReplaceAll(text, ",", ".")
-		amt, err := strconv.ParseFloat(s, 64)
-		if err != nil || amt <= 0 {
-			h.Send(chatID, "Некорректная сумма — введи число, например 1999.50", RecurringKeyboard())
-			return
-		}
-		draft["amount"] = amt
-		h.fsm.Set(userID, "RECURRING_ADD", draft)
-		h.Send(chatID, "Введите период: daily / weekly / monthly / yearly", RecurringKeyboard())
-		return
-	}
-
-	if draft["period"] == "" {
-		p := strings.ToLower(text)
-		if p != "daily" && p != "weekly" && p != "monthly" && p != "yearly" {
-			h.Send(chatID, "Период должен быть одним из: daily, weekly, monthly, yearly", RecurringKeyboard())
-			return
-		}
-		draft["period"] = p
-		// если monthly — спросим день месяца (1-28 для безопасности)
-		if p == "monthly" {
-			h.fsm.Set(userID, "RECURRING_ADD", draft)
-			h.Send(chatID, "Введите день месяца для платежа (1-28):", RecurringKeyboard())
-			return
-		}
-		// иначе — сохраняем
-		if err := h.saveRecurringFromDraft(userID, draft); err != nil {
-			logger.Error("saveRecurring error: " + err.Error())
-			h.Send(chatID, "Ошибка при сохранении регулярного платежа", RecurringKeyboard())
-			h.fsm.Clear(userID)
-			return
-		}
-		h.fsm.Clear(userID)
-		h.Send(chatID, "Регулярный платёж сохранён.", RecurringKeyboard())
-		return
-	}
+// recurringPeriods — допустимые периоды регулярного платежа.
+var recurringPeriods = []string{"daily", "weekly", "monthly", "yearly"}
 
-	// если мы сюда пришли — возможно period == monthly и нужно обработать payment_day
-	if draft["period"] == "monthly" && draft["payment_day"].(int) == 0 {
-		day, err := strconv.Atoi(text)
-		if err != nil || day < 1 || day > 28 {
-			h.Send(chatID, "Введите корректный день месяца: число от 1 до 28", RecurringKeyboard())
-			return
-		}
-		draft["payment_day"] = day
-		// сохраняем
-		if err := h.saveRecurringFromDraft(userID, draft); err != nil {
-			logger.Error("saveRecurring error: " + err.Error())
-			h.Send(chatID, "Ошибка при сохранении регулярного платежа", RecurringKeyboard())
-			h.fsm.Clear(userID)
-			return
-		}
-		h.fsm.Clear(userID)
-		h.Send(chatID, "Регулярный платёж сохранён.", RecurringKeyboard())
-		return
+// recurringAddWizard — мастер добавления регулярного платежа.
+// День месяца спрашивается только для ежемесячных платежей.
+func (h *Handler) recurringAddWizard() *wizard {
+	return &wizard{
+		Name: wizRecurringAdd,
+		Steps: []wizardStep{
+			{
+				Key:    "title",
+				Label:  "Название",
+				Prompt: staticPrompt("Введите название регулярного платежа:"),
+				Parse:  parseRequired("Название не может быть пустым. Введите название:"),
+			},
+			{
+				Key:    "amount",
+				Label:  "Сумма",
+				Prompt: staticPrompt("Введите сумму (числом), например: 1999.50"),
+				Parse:  parsePositiveFloat("Некорректная сумма — введи число, например 1999.50"),
+				Format: formatRubles,
+			},
+			{
+				Key:     "period",
+				Label:   "Период",
+				Prompt:  staticPrompt("Выберите период: daily / weekly / monthly / yearly"),
+				Options: staticOptions(recurringPeriods...),
+				Parse: func(text string, _ map[string]any) (any, error) {
+					p := strings.ToLower(text)
+					for _, allowed := range recurringPeriods {
+						if p == allowed {
+							return p, nil
+						}
+					}
+					return nil, errors.New("Период должен быть одним из: daily, weekly, monthly, yearly")
+				},
+			},
+			{
+				Key:    "payment_day",
+				Label:  "День платежа",
+				Prompt: staticPrompt("Введите день месяца для платежа (1-28):"),
+				When: func(data map[string]any) bool {
+					return getString(data, "period") == "monthly"
+				},
+				Parse: func(text string, _ map[string]any) (any, error) {
+					day, err := strconv.Atoi(text)
+					if err != nil || day < 1 || day > 28 {
+						return nil, errors.New("Введите число от 1 до 28:")
+					}
+					return day, nil
+				},
+			},
+		},
+		Submit: func(userID int64, data map[string]any) (string, error) {
+			if err := h.saveRecurringFromDraft(userID, data); err != nil {
+				return "", err
+			}
+			return "Регулярный платёж сохранён.", nil
+		},
+		Keyboard: RecurringKeyboard,
 	}
+}
 
-	// fallback
-	h.Send(chatID, "Не удалось обработать сообщение. Попробуйте /start или Домой.", RecurringKeyboard())
+// startRecurringAdd запускает мастер добавления регулярного платежа.
+func (h *Handler) startRecurringAdd(userID int64) {
+	h.startWizard(userID, h.wizards[wizRecurringAdd], nil)
 }
 
 // saveRecurringFromDraft — конвертация draft -> finance.RecurringPayment и сохранение в репо + создание todo-напоминания
@@ -203,29 +159,70 @@ func (h *Handler) saveRecurringFromDraft(userID int64, draft map[string]any) err
 // showRecurringList — выводит список регулярных платежей (через recurringRepo)
 func (h *Handler) showRecurringList(userID int64) {
 	chatID := userID
-	ctx := context.Background()
 
-	// Получаем список через сервис
-	list, err := h.finance.GetRecurringList(ctx, userID)
+	text, kb, err := h.renderRecurringList(userID)
 	if err != nil {
 		logger.Error("GetRecurringList error: " + err.Error())
 		h.Send(chatID, "Ошибка получения списка регулярных платежей", RecurringKeyboard())
 		return
 	}
 
-	if len(list) == 0 {
-		h.Send(chatID, "Регулярных платежей не найдено", RecurringKeyboard())
+	if len(kb.InlineKeyboard) == 0 {
+		h.Send(chatID, text, RecurringKeyboard())
 		return
 	}
+	h.SendInline(chatID, text, kb)
+}
+
+// renderRecurringList формирует список регулярных платежей с кнопкой удаления у каждого.
+func (h *Handler) renderRecurringList(userID int64) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	// Получаем список через сервис
+	list, err := h.finance.GetRecurringList(context.Background(), userID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	if len(list) == 0 {
+		return "Регулярных платежей не найдено", tgbotapi.InlineKeyboardMarkup{}, nil
+	}
 
 	var sb strings.Builder
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(list))
 	sb.WriteString("Регулярные платежи:\n\n")
 	for _, p := range list {
 		sb.WriteString(fmt.Sprintf("ID:%d • %s — %.2f ₽ • %s • next: %s\n",
 			p.ID, p.Title, p.Amount, p.Period, p.NextPayment.Format("02.01.2006")))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			callbackButton("🗑 "+truncate(p.Title, 28), cbRecurring, actDelete, p.ID),
+		))
 	}
-	sb.WriteString("\nДля удаления используйте /delete_recurring <id>")
-	h.Send(chatID, sb.String(), RecurringKeyboard())
+	sb.WriteString("\nДля удаления нажмите кнопку или используйте /delete_recurring <id>")
+
+	return sb.String(), tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// registerRecurringCallbacks регистрирует inline-действия списка регулярных платежей.
+func (h *Handler) registerRecurringCallbacks(r *callbackRouter) {
+	r.Handle(cbRecurring, actDelete, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		err := h.finance.DeleteRecurring(context.Background(), cb.From.ID, data.Arg(0))
+		switch {
+		case errors.Is(err, finance.ErrNotFound):
+			h.answerCallback(cb, "Платёж не найден")
+		case err != nil:
+			logger.Error("DeleteRecurring failed: " + err.Error())
+			h.answerCallback(cb, "Ошибка удаления")
+			return
+		default:
+			h.answerCallback(cb, "🗑 Удалено")
+		}
+
+		text, kb, err := h.renderRecurringList(cb.From.ID)
+		if err != nil {
+			logger.Error("GetRecurringList error: " + err.Error())
+			return
+		}
+		h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, text, kb)
+	})
 }
 
 // handleDeleteRecurringCommand — /delete_recurring <id>
@@ -246,7 +243,11 @@ func (h *Handler) handleDeleteRecurringCommand(update tgbotapi.Update) {
 	}
 
 	// Удаляем через сервис
-	if err := h.finance.DeleteRecurring(context.Background(), id); err != nil {
+	if err := h.finance.DeleteRecurring(context.Background(), update.Message.From.ID, id); err != nil {
+		if errors.Is(err, finance.ErrNotFound) {
+			h.Send(chatID, "Регулярный платёж не найден", RecurringKeyboard())
+			return
+		}
 		logger.Error("DeleteRecurring failed: " + err.Error())
 		h.Send(chatID, "Ошибка удаления регулярного платежа", RecurringKeyboard())
 		return
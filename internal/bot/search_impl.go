@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/search"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Поиск по всем модулям: /search <запрос>.
+
+// searchModuleTitles — заголовки групп результатов поиска.
+var searchModuleTitles = map[search.Module]string{
+	search.ModuleTodo:      "📝 Задачи",
+	search.ModuleFinance:   "💰 Операции",
+	search.ModuleRecurring: "🔁 Регулярные платежи",
+	search.ModuleCredit:    "🏦 Кредиты",
+}
+
+// searchOpenButtons — сколько найденных задач получают кнопку открытия карточки.
+const searchOpenButtons = 5
+
+// handleSearchCommand — /search <запрос>.
+func (h *Handler) handleSearchCommand(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	query := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, CmdSearch))
+
+	groups, err := h.search.Search(context.Background(), userID, query)
+	if err != nil {
+		if errors.Is(err, search.ErrEmptyQuery) {
+			h.Send(userID, "Использование: /search <запрос>\nИщу по задачам, операциям, регулярным платежам и кредитам.", HomeKeyboard())
+			return
+		}
+		logger.Error("Search failed: " + err.Error())
+		h.Send(userID, "Ошибка поиска. Попробуйте позже.", HomeKeyboard())
+		return
+	}
+	if len(groups) == 0 {
+		h.Send(userID, "🔎 По запросу «"+query+"» ничего не найдено", HomeKeyboard())
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, g := range groups {
+		if g.Module != search.ModuleTodo {
+			continue
+		}
+		for i, hit := range g.Hits {
+			if i == searchOpenButtons {
+				break
+			}
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(callbackButton("📋 "+truncate(hit.Title, 32), cbTodo, actOpen, hit.ID)))
+		}
+	}
+
+	text := formatSearchResults(query, groups, h.userLocation(userID))
+	if len(rows) == 0 {
+		h.Send(userID, text, HomeKeyboard())
+		return
+	}
+	h.SendInline(userID, text, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// formatSearchResults — текст результатов поиска, сгруппированных по модулям.
+func formatSearchResults(query string, groups []search.Group, loc *time.Location) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🔎 «%s» — найдено: %d\n", query, search.Total(groups)))
+	for _, g := range groups {
+		b.WriteString("\n" + searchModuleTitles[g.Module] + ":\n")
+		for _, hit := range g.Hits {
+			b.WriteString(formatSearchHit(hit, loc) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// formatSearchHit — строка одного результата поиска.
+func formatSearchHit(hit search.Hit, loc *time.Location) string {
+	switch hit.Module {
+	case search.ModuleTodo:
+		mark := "▫️"
+		if hit.Done {
+			mark = "✅"
+		}
+		line := fmt.Sprintf("%s %d) %s", mark, hit.ID, hit.Title)
+		if hit.Date != nil {
+			line += " — " + formatDue(*hit.Date, loc)
+		}
+		return line
+	case search.ModuleFinance:
+		line := fmt.Sprintf("▫️ %+.2f ₽", hit.Amount)
+		if hit.Date != nil {
+			line = fmt.Sprintf("▫️ %s %+.2f ₽", hit.Date.In(loc).Format("02.01.2006"), hit.Amount)
+		}
+		if hit.Detail != "" && hit.Detail != hit.Title {
+			line += " | " + hit.Detail
+		}
+		return line + " | " + hit.Title
+	case search.ModuleRecurring:
+		line := fmt.Sprintf("▫️ %d) %s — %.2f ₽", hit.ID, hit.Title, hit.Amount)
+		if hit.Date != nil {
+			line += ", следующий платёж " + hit.Date.UTC().Format("02.01.2006")
+		}
+		return line
+	default:
+		return fmt.Sprintf("▫️ %d) %s — %.2f ₽", hit.ID, hit.Title, hit.Amount)
+	}
+}
@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/logger"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// userLocation возвращает часовой пояс пользователя для разбора и отображения дат.
+func (h *Handler) userLocation(userID int64) *time.Location {
+	return h.users.Location(context.Background(), userID)
+}
+
+// handleTimezoneCommand — /timezone [<IANA-имя>], показывает или меняет часовой пояс пользователя.
+func (h *Handler) handleTimezoneCommand(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+	parts := strings.Fields(update.Message.Text)
+
+	if len(parts) < 2 {
+		loc := h.userLocation(userID)
+		h.Send(chatID, "Ваш часовой пояс: "+loc.String()+
+			"\nЧтобы изменить: /timezone <пояс>, например /timezone Europe/Moscow", HomeKeyboard())
+		return
+	}
+
+	loc, err := time.LoadLocation(parts[1])
+	if err != nil || parts[1] == "Local" {
+		h.Send(chatID, "Неизвестный часовой пояс. Пример: Europe/Moscow, Asia/Yekaterinburg", HomeKeyboard())
+		return
+	}
+
+	if err := h.users.SetTimezone(context.Background(), userID, loc.String()); err != nil {
+		logger.Error("SetTimezone failed: " + err.Error())
+		h.Send(chatID, "Ошибка сохранения часового пояса", HomeKeyboard())
+		return
+	}
+
+	h.Send(chatID, "Часовой пояс сохранён: "+loc.String()+
+		"\nСейчас у вас "+time.Now().In(loc).Format("15:04"), HomeKeyboard())
+}
@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Состояние FSM хранится в user_states.state_json.
+// Обычный json.Marshal теряет типы: int возвращается как float64 и ломает
+// приведения вида draft["payment_day"].(int). Поэтому каждое значение State.Data
+// сохраняется вместе с кодом своего типа: {"payment_day": {"t": "i", "v": 15}}.
+
+// Коды типов значений State.Data.
+const (
+	typeNil     = "n"
+	typeString  = "s"
+	typeInt     = "i"
+	typeInt64   = "i64"
+	typeFloat   = "f"
+	typeBool    = "b"
+	typeTime    = "t"
+	typeInts    = "is"
+	typeStrings = "ss"
+	typeMap     = "m" // map[string]any, значения кодируются так же, рекурсивно
+)
+
+// storedState — формат состояния в базе данных.
+type storedState struct {
+	Name string                `json:"name"`
+	Data map[string]typedValue `json:"data"`
+}
+
+type typedValue struct {
+	Type  string          `json:"t"`
+	Value json.RawMessage `json:"v,omitempty"`
+}
+
+// encodeState сериализует состояние с сохранением типов значений.
+func encodeState(s *State) (string, error) {
+	stored := storedState{Name: s.Name, Data: make(map[string]typedValue, len(s.Data))}
+
+	for k, v := range s.Data {
+		tv, err := encodeValue(v)
+		if err != nil {
+			return "", fmt.Errorf("state %s, key %s: %w", s.Name, k, err)
+		}
+		stored.Data[k] = tv
+	}
+
+	b, err := json.Marshal(stored)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// decodeState восстанавливает состояние, сохранённое encodeState.
+func decodeState(raw string) (*State, error) {
+	var stored storedState
+	if err := json.Unmarshal([]byte(raw), &stored); err != nil {
+		return nil, err
+	}
+
+	s := &State{Name: stored.Name, Data: make(map[string]any, len(stored.Data))}
+	for k, tv := range stored.Data {
+		v, err := decodeValue(tv)
+		if err != nil {
+			return nil, fmt.Errorf("state %s, key %s: %w", stored.Name, k, err)
+		}
+		s.Data[k] = v
+	}
+	return s, nil
+}
+
+func encodeValue(v any) (typedValue, error) {
+	var t string
+	switch val := v.(type) {
+	case nil:
+		return typedValue{Type: typeNil}, nil
+	case string:
+		t = typeString
+	case int:
+		t = typeInt
+	case int64:
+		t = typeInt64
+	case float64:
+		t = typeFloat
+	case bool:
+		t = typeBool
+	case time.Time:
+		t = typeTime
+	case []int:
+		t = typeInts
+	case []string:
+		t = typeStrings
+	case map[string]any:
+		m := make(map[string]typedValue, len(val))
+		for k, item := range val {
+			tv, err := encodeValue(item)
+			if err != nil {
+				return typedValue{}, fmt.Errorf("key %s: %w", k, err)
+			}
+			m[k] = tv
+		}
+		raw, err := json.Marshal(m)
+		if err != nil {
+			return typedValue{}, err
+		}
+		return typedValue{Type: typeMap, Value: raw}, nil
+	default:
+		return typedValue{}, fmt.Errorf("unsupported type %T", v)
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return typedValue{}, err
+	}
+	return typedValue{Type: t, Value: raw}, nil
+}
+
+func decodeValue(tv typedValue) (any, error) {
+	switch tv.Type {
+	case typeNil:
+		return nil, nil
+	case typeString:
+		return unmarshalAs[string](tv.Value)
+	case typeInt:
+		return unmarshalAs[int](tv.Value)
+	case typeInt64:
+		return unmarshalAs[int64](tv.Value)
+	case typeFloat:
+		return unmarshalAs[float64](tv.Value)
+	case typeBool:
+		return unmarshalAs[bool](tv.Value)
+	case typeTime:
+		return unmarshalAs[time.Time](tv.Value)
+	case typeInts:
+		return unmarshalAs[[]int](tv.Value)
+	case typeStrings:
+		return unmarshalAs[[]string](tv.Value)
+	case typeMap:
+		var m map[string]typedValue
+		if err := json.Unmarshal(tv.Value, &m); err != nil {
+			return nil, err
+		}
+		out := make(map[string]any, len(m))
+		for k, item := range m {
+			v, err := decodeValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %w", k, err)
+			}
+			out[k] = v
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unknown value type %q", tv.Type)
+}
+
+func unmarshalAs[T any](raw json.RawMessage) (any, error) {
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
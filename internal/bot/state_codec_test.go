@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStateCodecRoundTrip(t *testing.T) {
+	at := time.Date(2026, time.October, 17, 18, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{name: "nil", value: nil},
+		{name: "string", value: "Кафе"},
+		{name: "int", value: 15},
+		{name: "int64", value: int64(123456789012)},
+		{name: "float64", value: 1500.5},
+		{name: "bool", value: true},
+		{name: "time.Time", value: at},
+		{name: "[]int", value: []int{0, 2, 1}},
+		{name: "[]string", value: []string{"a", "б"}},
+		{name: "nested map", value: map[string]any{
+			"id":    7,
+			"sum":   99.9,
+			"at":    at,
+			"inner": map[string]any{"ids": []int{1, 2}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := &State{Name: "TEST", Data: map[string]any{"v": tt.value}}
+			raw, err := encodeState(in)
+			if err != nil {
+				t.Fatalf("encodeState: %v", err)
+			}
+			out, err := decodeState(raw)
+			if err != nil {
+				t.Fatalf("decodeState: %v", err)
+			}
+			if out.Name != in.Name {
+				t.Errorf("name = %q, want %q", out.Name, in.Name)
+			}
+
+			got := out.Data["v"]
+			if want, ok := tt.value.(time.Time); ok {
+				if gotTime, ok := got.(time.Time); !ok || !gotTime.Equal(want) {
+					t.Errorf("value = %#v, want %#v", got, want)
+				}
+				return
+			}
+			if tt.name == "nested map" {
+				m, ok := got.(map[string]any)
+				if !ok || !m["at"].(time.Time).Equal(at) {
+					t.Fatalf("value = %#v", got)
+				}
+				delete(m, "at")
+				got = m
+				want := map[string]any{"id": 7, "sum": 99.9, "inner": map[string]any{"ids": []int{1, 2}}}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("value = %#v, want %#v", got, want)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("value = %#v (%T), want %#v (%T)", got, got, tt.value, tt.value)
+			}
+		})
+	}
+}
+
+func TestStateCodecUnsupportedType(t *testing.T) {
+	for _, v := range []any{float32(1), struct{}{}, map[string]int{"a": 1}, map[string]any{"bad": []float64{1}}} {
+		if _, err := encodeState(&State{Name: "TEST", Data: map[string]any{"v": v}}); err == nil {
+			t.Errorf("encodeState(%T) error = nil, want error", v)
+		}
+	}
+	if _, err := decodeState(`{"name":"TEST","data":{"v":{"t":"x","v":1}}}`); err == nil {
+		t.Error("decodeState(unknown type) error = nil, want error")
+	}
+}
+
+// memoryStore — хранилище состояний в памяти.
+type memoryStore map[int64]string
+
+func (m memoryStore) Save(_ context.Context, userID int64, state string) error {
+	m[userID] = state
+	return nil
+}
+
+func (m memoryStore) Get(_ context.Context, userID int64) (string, time.Time, error) {
+	return m[userID], time.Now(), nil
+}
+
+func (m memoryStore) Delete(_ context.Context, userID int64) error {
+	delete(m, userID)
+	return nil
+}
+
+func (m memoryStore) DeleteExpired(context.Context, time.Time) (int64, error) { return 0, nil }
+
+func TestFSMSet(t *testing.T) {
+	store := memoryStore{}
+	fsm := NewFSM(store, time.Hour)
+
+	if err := fsm.Set(1, "TODO_EDIT", map[string]any{"id": 5}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := fsm.Set(1, "BROKEN", map[string]any{"v": float32(1)}); err == nil {
+		t.Fatal("Set with unsupported value: error = nil, want error")
+	}
+	if s := fsm.Get(1); s == nil || s.Name != "TODO_EDIT" {
+		t.Errorf("state after failed Set = %+v, want TODO_EDIT", s)
+	}
+
+	// после перезапуска состояние восстанавливается из хранилища с исходными типами
+	restored := NewFSM(store, time.Hour).Get(1)
+	if restored == nil || restored.Name != "TODO_EDIT" {
+		t.Fatalf("restored state = %+v", restored)
+	}
+	if id, ok := restored.Data["id"].(int); !ok || id != 5 {
+		t.Errorf("restored id = %#v, want int 5", restored.Data["id"])
+	}
+}
@@ -1,48 +1,176 @@
 package bot
 
-import "sync"
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"tg_bot_asist/internal/logger"
+)
 
 // State представляет состояние пользователя в FSM.
 // Name — имя состояния (например, "TODO_ADD", "FIN_ADD_TYPE").
 // Data — произвольные данные состояния (map для гибкости).
+// Поддерживаемые типы значений перечислены в state_codec.go.
 type State struct {
 	Name string
 	Data map[string]any
 }
 
-// FSM (Finite State Machine) управляет состояниями пользователей в памяти.
+// StateStore — постоянное хранилище состояний (реализуется storage.StateRepo).
+type StateStore interface {
+	Save(ctx context.Context, userID int64, state string) error
+	Get(ctx context.Context, userID int64) (string, time.Time, error)
+	Delete(ctx context.Context, userID int64) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// storeTimeout — ограничение на одну операцию с хранилищем состояний.
+const storeTimeout = 3 * time.Second
+
+// FSM (Finite State Machine) управляет состояниями пользователей.
 // Используется для многошаговых диалогов (создание задач, финансовых операций и т.д.).
-// Состояния хранятся в памяти и очищаются после завершения диалога.
+// Состояния сохраняются в Postgres и переживают перезапуск бота; в памяти держится
+// кэш для быстрого доступа. Диалоги, которые не менялись дольше ttl, считаются брошенными.
 type FSM struct {
 	mu    sync.RWMutex
-	store map[int64]*State
+	cache map[int64]*cachedState
+	store StateStore
+	ttl   time.Duration
+}
+
+type cachedState struct {
+	state     *State
+	updatedAt time.Time
 }
 
 // NewFSM создаёт новый экземпляр FSM.
-func NewFSM() *FSM {
+// Если store == nil, состояния хранятся только в памяти.
+func NewFSM(store StateStore, ttl time.Duration) *FSM {
 	return &FSM{
-		store: make(map[int64]*State),
+		cache: make(map[int64]*cachedState),
+		store: store,
+		ttl:   ttl,
 	}
 }
 
 // Set устанавливает состояние для пользователя.
-// Если состояние уже существует, оно перезаписывается.
-func (f *FSM) Set(userID int64, name string, data map[string]any) {
+// Если состояние уже существует, оно перезаписывается. Состояние попадает в кэш только после
+// сохранения в хранилище: при ошибке (в том числе при значении неподдерживаемого типа)
+// прежнее состояние не меняется, а ошибка возвращается вызывающему.
+func (f *FSM) Set(userID int64, name string, data map[string]any) error {
+	state := &State{Name: name, Data: data}
+
+	if f.store != nil {
+		raw, err := encodeState(state)
+		if err != nil {
+			return fmt.Errorf("FSM: encode state for user %d: %w", userID, err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+		if err := f.store.Save(ctx, userID, raw); err != nil {
+			return fmt.Errorf("FSM: persist state for user %d: %w", userID, err)
+		}
+	}
+
 	f.mu.Lock()
-	defer f.mu.Unlock()
-	f.store[userID] = &State{Name: name, Data: data}
+	f.cache[userID] = &cachedState{state: state, updatedAt: time.Now()}
+	f.mu.Unlock()
+	return nil
 }
 
-// Get возвращает текущее состояние пользователя или nil, если состояние не установлено.
+// Get возвращает текущее состояние пользователя или nil, если состояние не установлено или истекло.
 func (f *FSM) Get(userID int64) *State {
 	f.mu.RLock()
-	defer f.mu.RUnlock()
-	return f.store[userID]
+	cached, ok := f.cache[userID]
+	f.mu.RUnlock()
+
+	if ok {
+		if f.expired(cached.updatedAt) {
+			f.Clear(userID)
+			return nil
+		}
+		return cached.state
+	}
+
+	if f.store == nil {
+		return nil
+	}
+
+	// после перезапуска кэш пуст — восстанавливаем состояние из хранилища
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	raw, updatedAt, err := f.store.Get(ctx, userID)
+	if err != nil || raw == "" {
+		return nil
+	}
+
+	if f.expired(updatedAt) {
+		f.Clear(userID)
+		return nil
+	}
+
+	state, err := decodeState(raw)
+	if err != nil {
+		logger.Error(fmt.Sprintf("FSM: failed to decode state for user %d: %s", userID, err.Error()))
+		f.Clear(userID)
+		return nil
+	}
+
+	f.mu.Lock()
+	f.cache[userID] = &cachedState{state: state, updatedAt: updatedAt}
+	f.mu.Unlock()
+
+	return state
 }
 
 // Clear удаляет состояние пользователя (завершение диалога).
 func (f *FSM) Clear(userID int64) {
 	f.mu.Lock()
-	defer f.mu.Unlock()
-	delete(f.store, userID)
+	delete(f.cache, userID)
+	f.mu.Unlock()
+
+	if f.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := f.store.Delete(ctx, userID); err != nil {
+		logger.Error(fmt.Sprintf("FSM: failed to delete state for user %d: %s", userID, err.Error()))
+	}
+}
+
+// CleanupExpired удаляет брошенные диалоги из кэша и хранилища.
+func (f *FSM) CleanupExpired(ctx context.Context) {
+	if f.ttl <= 0 {
+		return
+	}
+
+	f.mu.Lock()
+	for userID, cached := range f.cache {
+		if f.expired(cached.updatedAt) {
+			delete(f.cache, userID)
+		}
+	}
+	f.mu.Unlock()
+
+	if f.store == nil {
+		return
+	}
+
+	n, err := f.store.DeleteExpired(ctx, time.Now().Add(-f.ttl))
+	if err != nil {
+		return
+	}
+	if n > 0 {
+		logger.Info(fmt.Sprintf("FSM: expired %d abandoned dialogs", n))
+	}
+}
+
+func (f *FSM) expired(updatedAt time.Time) bool {
+	return f.ttl > 0 && time.Since(updatedAt) > f.ttl
 }
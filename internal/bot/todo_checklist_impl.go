@@ -0,0 +1,251 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tg_bot_asist/internal/attachment"
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Подзадачи и чек-листы.
+// /sub <id> <текст> — подзадача (каждая строка — отдельная подзадача),
+// /check <id> <пункт> — пункт чек-листа (каждая строка — отдельный пункт), /check <id> — карточка задачи,
+// /autodone <id> on|off — выполнять задачу автоматически, когда выполнено всё остальное.
+
+// nestTodos упорядочивает список так, чтобы подзадачи шли сразу за своей родительской задачей.
+// Подзадачи, родителя которых нет в списке, остаются на своих местах.
+func nestTodos(items []todo.Item) []todo.Item {
+	children := make(map[int][]todo.Item)
+	present := make(map[int]bool, len(items))
+	for _, it := range items {
+		present[it.ID] = true
+	}
+	for _, it := range items {
+		if it.ParentID != nil && present[*it.ParentID] {
+			children[*it.ParentID] = append(children[*it.ParentID], it)
+		}
+	}
+
+	out := make([]todo.Item, 0, len(items))
+	for _, it := range items {
+		if it.ParentID != nil && present[*it.ParentID] {
+			continue
+		}
+		out = append(out, it)
+		out = append(out, children[it.ID]...)
+	}
+	return out
+}
+
+// isNested сообщает, выводится ли задача в списке под своей родительской задачей.
+func isNested(it todo.Item, items []todo.Item) bool {
+	if it.ParentID == nil {
+		return false
+	}
+	for _, p := range items {
+		if p.ID == *it.ParentID {
+			return true
+		}
+	}
+	return false
+}
+
+// showTodoCard отправляет карточку задачи с подзадачами и чек-листом.
+func (h *Handler) showTodoCard(userID int64, id int) {
+	text, kb, err := h.renderTodoCard(userID, id)
+	if err != nil {
+		h.sendTodoError(userID, "Todo card failed", err)
+		return
+	}
+	h.SendInline(userID, text, kb)
+}
+
+// renderTodoCard формирует карточку задачи: прогресс, подзадачи, чек-лист
+// с inline-кнопками для отметки и удаления пунктов и кнопку таймера.
+func (h *Handler) renderTodoCard(userID int64, id int) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	ctx := context.Background()
+	loc := h.userLocation(userID)
+
+	item, err := h.todo.Get(ctx, userID, id)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+	subtasks, err := h.todo.Subtasks(ctx, userID, id)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	var b strings.Builder
+	b.WriteString(formatTodoLine(*item, loc) + "\n")
+	if item.Description != "" {
+		b.WriteString(item.Description + "\n")
+	}
+	if item.AutoComplete {
+		b.WriteString("⚙️ Выполнится автоматически, когда будет выполнено всё остальное\n")
+	}
+
+	if len(subtasks) > 0 {
+		b.WriteString("\nПодзадачи:\n")
+		for _, st := range subtasks {
+			b.WriteString(formatTodoLine(st, loc) + "\n")
+		}
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if len(item.Checklist) > 0 {
+		b.WriteString("\nЧек-лист:\n")
+		for _, c := range item.Checklist {
+			mark := "⬜"
+			if c.Done {
+				mark = "☑️"
+			}
+			b.WriteString(mark + " " + c.Title + "\n")
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				callbackButton(mark+" "+truncate(c.Title, 28), cbChecklist, actDone, c.ID),
+				callbackButton("🗑", cbChecklist, actDelete, c.ID),
+			))
+		}
+	}
+
+	attachText, attachRows := h.todoAttachmentsSection(userID, item.ID)
+	if attachText != "" {
+		b.WriteString("\n" + attachText)
+		rows = append(rows, attachRows...)
+	}
+
+	timeLine, timerRow := h.timerCardRow(userID, item.ID)
+	if timeLine != "" {
+		b.WriteString("\n" + timeLine + "\n")
+	}
+	if timerRow != nil {
+		rows = append(rows, timerRow)
+	}
+
+	b.WriteString(fmt.Sprintf("\nДобавить: /sub %d <подзадача>, /check %d <пункт>", item.ID, item.ID))
+	b.WriteString("\n" + attachHint(attachment.OwnerTodo, item.ID))
+	return b.String(), tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// registerChecklistCallbacks регистрирует кнопки пунктов чек-листа в карточке задачи.
+func (h *Handler) registerChecklistCallbacks(r *callbackRouter) {
+	r.Handle(cbChecklist, actDone, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		c, item, err := h.todo.ToggleChecklistItem(context.Background(), cb.From.ID, data.Arg(0))
+		if err != nil {
+			h.afterChecklistCallback(cb, 0, "", err)
+			return
+		}
+		okText := "Отметка снята"
+		if c.Done {
+			okText = "☑️ Отмечено"
+		}
+		if c.Done && item != nil && item.Status == todo.StatusCompleted && item.AutoComplete {
+			okText = "✅ Чек-лист пройден, задача выполнена"
+		}
+		h.afterChecklistCallback(cb, c.TodoID, okText, nil)
+	})
+	r.Handle(cbChecklist, actDelete, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		item, err := h.todo.DeleteChecklistItem(context.Background(), cb.From.ID, data.Arg(0))
+		todoID := 0
+		if item != nil {
+			todoID = item.ID
+		}
+		h.afterChecklistCallback(cb, todoID, "🗑 Пункт удалён", err)
+	})
+}
+
+// afterChecklistCallback отвечает на нажатие кнопки и перерисовывает карточку задачи.
+func (h *Handler) afterChecklistCallback(cb *tgbotapi.CallbackQuery, todoID int, okText string, err error) {
+	if err != nil {
+		if errors.Is(err, todo.ErrChecklistItemNotFound) || errors.Is(err, todo.ErrNotFound) {
+			h.answerCallback(cb, "Пункт не найден")
+		} else if errors.Is(err, todo.ErrForbidden) {
+			h.answerCallback(cb, "Недостаточно прав")
+		} else {
+			logger.Error("Checklist callback failed: " + err.Error())
+			h.answerCallback(cb, "Ошибка, попробуйте позже")
+		}
+		return
+	}
+	h.answerCallback(cb, okText)
+
+	text, kb, err := h.renderTodoCard(cb.From.ID, todoID)
+	if err != nil {
+		logger.Error("Todo card error: " + err.Error())
+		return
+	}
+	h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, text, kb)
+}
+
+// addSubtasks — /sub <id> <текст>; каждая непустая строка становится подзадачей.
+func (h *Handler) addSubtasks(userID int64, parentID int, text string) {
+	titles := splitLines(text)
+	if len(titles) == 0 {
+		h.Send(userID, "Использование: /sub <id> <текст подзадачи>. Несколько подзадач — с новой строки.", TodoKeyboard())
+		return
+	}
+
+	for _, title := range titles {
+		if _, err := h.todo.AddSubtask(context.Background(), userID, parentID, title); err != nil {
+			if errors.Is(err, todo.ErrInvalidParent) {
+				h.Send(userID, "Подзадачи можно добавлять только к своей задаче верхнего уровня", TodoKeyboard())
+				return
+			}
+			h.sendTodoError(userID, "Todo subtask add failed", err)
+			return
+		}
+	}
+	h.showTodoCard(userID, parentID)
+}
+
+// addChecklistItems — /check <id> <пункт>; каждая непустая строка становится пунктом чек-листа.
+func (h *Handler) addChecklistItems(userID int64, todoID int, text string) {
+	for _, title := range splitLines(text) {
+		if _, err := h.todo.AddChecklistItem(context.Background(), userID, todoID, title); err != nil {
+			h.sendTodoError(userID, "Checklist add failed", err)
+			return
+		}
+	}
+	h.showTodoCard(userID, todoID)
+}
+
+// setTodoAutoComplete — /autodone <id> on|off.
+func (h *Handler) setTodoAutoComplete(userID int64, id int, arg string) {
+	var on bool
+	switch strings.ToLower(arg) {
+	case "on", "вкл":
+		on = true
+	case "off", "выкл":
+	default:
+		h.Send(userID, "Использование: /autodone <id> on|off — выполнять задачу автоматически, когда выполнены все пункты чек-листа и подзадачи", TodoKeyboard())
+		return
+	}
+
+	item, err := h.todo.SetAutoComplete(context.Background(), userID, id, on)
+	if err != nil {
+		h.sendTodoError(userID, "Todo auto-complete update failed", err)
+		return
+	}
+
+	msg := "⚙️ Автовыполнение выключено:\n"
+	if on {
+		msg = "⚙️ Автовыполнение включено:\n"
+	}
+	h.Send(userID, msg+formatTodoLine(*item, h.userLocation(userID)), TodoKeyboard())
+}
+
+// splitLines возвращает непустые строки текста без пробелов по краям.
+func splitLines(text string) []string {
+	var lines []string
+	for _, l := range strings.Split(text, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,164 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+)
+
+// Приоритеты и метки задач.
+// В тексте задачи метки пишутся как #work, приоритет — как !high, !срочно или !1..!4.
+// /todo #work !high today — отфильтрованный список, /tag и /priority — изменение задачи.
+
+// priorityMarks — значки приоритетов в списке задач (обычный приоритет без значка).
+var priorityMarks = map[todo.Priority]string{
+	todo.PriorityLow:    "🔵",
+	todo.PriorityHigh:   "🟠",
+	todo.PriorityUrgent: "🔴",
+}
+
+// priorityTitles — названия приоритетов для пользователя.
+var priorityTitles = map[todo.Priority]string{
+	todo.PriorityLow:    "низкий",
+	todo.PriorityNormal: "обычный",
+	todo.PriorityHigh:   "высокий",
+	todo.PriorityUrgent: "срочный",
+}
+
+// extractTodoMarks вынимает из текста метки (#tag) и приоритет (!high).
+// Слова, похожие на метки, но некорректные, остаются в тексте.
+func extractTodoMarks(text string) (rest string, tags []string, prio todo.Priority) {
+	words := strings.Fields(text)
+	kept := words[:0]
+	for _, w := range words {
+		if strings.HasPrefix(w, "#") {
+			if tag := todo.NormalizeTag(w); tag != "" {
+				tags = append(tags, tag)
+				continue
+			}
+		}
+		if strings.HasPrefix(w, "!") {
+			if p, ok := todo.ParsePriority(strings.TrimPrefix(w, "!")); ok {
+				prio = p
+				continue
+			}
+		}
+		kept = append(kept, w)
+	}
+	return strings.Join(kept, " "), todo.NormalizeTags(tags), prio
+}
+
+// parseTodoFilter разбирает аргументы /todo: #метка, !приоритет и overdue|today|week.
+func parseTodoFilter(args string) (todo.ListFilter, error) {
+	var f todo.ListFilter
+	for _, w := range strings.Fields(args) {
+		switch {
+		case strings.HasPrefix(w, "#"):
+			f.Tag = todo.NormalizeTag(w)
+			if f.Tag == "" {
+				return f, fmt.Errorf("некорректная метка %s", w)
+			}
+		case strings.HasPrefix(w, "!"):
+			p, ok := todo.ParsePriority(strings.TrimPrefix(w, "!"))
+			if !ok {
+				return f, fmt.Errorf("неизвестный приоритет %s", w)
+			}
+			f.Priority = p
+		default:
+			v, ok := todo.ParseView(w)
+			if !ok {
+				return f, fmt.Errorf("непонятный фильтр %s", w)
+			}
+			f.View = v
+		}
+	}
+	return f, nil
+}
+
+// describeTodoFilter — заголовок отфильтрованного списка.
+func describeTodoFilter(f todo.ListFilter) string {
+	var parts []string
+	switch f.View {
+	case todo.ViewOverdue:
+		parts = append(parts, "просроченные")
+	case todo.ViewToday:
+		parts = append(parts, "на сегодня")
+	case todo.ViewWeek:
+		parts = append(parts, "на неделю")
+	}
+	if f.Priority != 0 {
+		parts = append(parts, "приоритет "+priorityTitles[f.Priority])
+	}
+	if f.Tag != "" {
+		parts = append(parts, "#"+f.Tag)
+	}
+	if len(parts) == 0 {
+		return "Ваши задачи:"
+	}
+	return "Задачи (" + strings.Join(parts, ", ") + "):"
+}
+
+// showFilteredTodoList — /todo [#метка] [!приоритет] [overdue|today|week].
+func (h *Handler) showFilteredTodoList(userID int64, args string) {
+	f, err := parseTodoFilter(args)
+	if err != nil {
+		h.Send(userID, "Ошибка: "+err.Error()+"\n\nПример: /todo #work !high today\nПредставления: overdue, today, week", TodoKeyboard())
+		return
+	}
+
+	items, err := h.todo.ListFiltered(context.Background(), userID, f, h.userLocation(userID))
+	if err != nil {
+		logger.Error("Todo filtered list error: " + err.Error())
+		h.Send(userID, "Ошибка получения списка задач", TodoKeyboard())
+		return
+	}
+
+	if len(items) == 0 {
+		h.Send(userID, describeTodoFilter(f)+"\n\nНичего не найдено", TodoKeyboard())
+		return
+	}
+	text := formatTodoList(describeTodoFilter(f), items, h.userLocation(userID))
+	h.SendInline(userID, text, todoListInlineKeyboard(items))
+}
+
+// setTodoTags — /tag <id> #метка ...; "-" снимает все метки.
+func (h *Handler) setTodoTags(userID int64, id int, args string) {
+	if args == "" {
+		h.Send(userID, "Использование: /tag <id> #метка1 #метка2. Чтобы снять метки: /tag <id> -", TodoKeyboard())
+		return
+	}
+
+	var tags []string
+	if args != "-" {
+		tags = strings.Fields(args)
+	}
+
+	item, err := h.todo.SetTags(context.Background(), userID, id, tags)
+	if err != nil {
+		h.sendTodoError(userID, "Todo tags update failed", err)
+		return
+	}
+	h.Send(userID, "🏷 Метки обновлены:\n"+formatTodoLine(*item, h.userLocation(userID)), TodoKeyboard())
+}
+
+// setTodoPriority — /priority <id> <low|normal|high|urgent>.
+func (h *Handler) setTodoPriority(userID int64, id int, arg string) {
+	p, ok := todo.ParsePriority(arg)
+	if !ok {
+		h.Send(userID, "Использование: /priority <id> <low|normal|high|urgent> (или низкий, обычный, высокий, срочный, 1-4)", TodoKeyboard())
+		return
+	}
+
+	item, err := h.todo.SetPriority(context.Background(), userID, id, p)
+	if err != nil {
+		h.sendTodoError(userID, "Todo priority update failed", err)
+		return
+	}
+	h.Send(userID, "Приоритет обновлён:\n"+formatTodoLine(*item, h.userLocation(userID)), TodoKeyboard())
+}
+
+// todoListFooter — подсказка по командам под списком задач.
+const todoListFooter = "Команды: /done <id>, /reopen <id>, /edit_todo <id>, /due <id> <срок>, /delete_todo <id>, /tag <id> #метка, /priority <id> <уровень>, /repeat <id> <правило>\nПодзадачи и чек-лист: /sub <id> <текст>, /check <id> [пункт], /autodone <id> on|off\nПроекты: /projects, /move <id> <проект>\nФильтры: /todo #метка !high overdue|today|week"
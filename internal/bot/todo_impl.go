@@ -2,85 +2,480 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
+	"tg_bot_asist/internal/attachment"
+	"tg_bot_asist/internal/dateparse"
 	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-// startTodoAdd запускает FSM для добавления новой задачи.
+// wizTodoAdd — имя мастера (и состояния FSM) добавления задачи.
+const wizTodoAdd = "TODO_ADD"
+
+// todoAddWizard — мастер добавления задачи. Срок извлекается из текста,
+// на экране подтверждения пользователь видит, как была понята дата.
+func (h *Handler) todoAddWizard() *wizard {
+	return &wizard{
+		Name: wizTodoAdd,
+		Steps: []wizardStep{
+			{
+				Key:    "title",
+				Label:  "Задача",
+				Prompt: staticPrompt("Введи текст задачи.\nСрок можно указать словами: «завтра в 18:00 позвонить маме», «в пятницу сдать отчёт», «через 3 дня».\nМетки и приоритет: #работа !high"),
+				Parse: func(text string, data map[string]any) (any, error) {
+					text, tags, prio := extractTodoMarks(text)
+					if text == "" {
+						return nil, errors.New("Пустая задача — введите текст задачи ещё раз:")
+					}
+					data["tags"] = tags
+					data["priority"] = int(prio)
+
+					// срок извлекаем из текста, остаток становится заголовком задачи
+					title, due := parseTodoText(text, h.userLocation(wizardUser(data)))
+					if due != nil {
+						data["due"] = *due
+					} else {
+						delete(data, "due")
+					}
+					return title, nil
+				},
+				Format: func(v any, data map[string]any) string {
+					text := v.(string) + "\nСрок: не указан"
+					if due, ok := data["due"].(time.Time); ok {
+						text = v.(string) + "\nСрок: " + formatDue(due, h.userLocation(wizardUser(data)))
+					}
+					if p, _ := data["priority"].(int); p != 0 {
+						text += "\nПриоритет: " + priorityTitles[todo.Priority(p)]
+					}
+					if tags, _ := data["tags"].([]string); len(tags) > 0 {
+						text += "\nМетки: #" + strings.Join(tags, " #")
+					}
+					return text
+				},
+			},
+			h.projectStep(),
+		},
+		Submit:   h.saveTodoFromDraft,
+		Keyboard: TodoKeyboard,
+		Attach:   attachment.OwnerTodo,
+	}
+}
+
+// startTodoAdd запускает мастер добавления задачи.
 func (h *Handler) startTodoAdd(userID int64) {
-	h.fsm.Set(userID, "TODO_ADD", map[string]any{})
-	h.Send(userID, "Введи текст задачи:", BackKeyboard())
+	h.startWizard(userID, h.wizards[wizTodoAdd], nil)
 }
 
-// handleTodoAdd: обрабатывает ввод текста задачи, сохраняет и закрывает FSM.
-// Вызывается из Handler.handle (FSM) когда state.Name == "TODO_ADD".
-func (h *Handler) handleTodoAdd(update tgbotapi.Update) {
-	if update.Message == nil {
+// saveTodoFromDraft сохраняет задачу, собранную мастером.
+func (h *Handler) saveTodoFromDraft(userID int64, data map[string]any) (string, error) {
+	item := &todo.Item{
+		UserID: userID,
+		Title:  getString(data, "title"),
+	}
+	if t, ok := data["due"].(time.Time); ok {
+		item.DueDate = &t
+	}
+	if p, ok := data["priority"].(int); ok {
+		item.Priority = todo.Priority(p)
+	}
+	item.Tags, _ = data["tags"].([]string)
+	item.ProjectID, _ = data["project_id"].(int)
+
+	if err := h.todo.Create(context.Background(), item); err != nil {
+		return "", err
+	}
+	data[wizardCreatedKey] = item.ID
+
+	return "Задача сохранена:\n" + formatTodoLine(*item, h.userLocation(userID)) + "\n\n" + attachHint(attachment.OwnerTodo, item.ID), nil
+}
+
+// showTodoList: выводит список задач для пользователя.
+// Вызывается из Handler.showTodoList (кнопка / меню).
+func (h *Handler) showTodoList(userID int64) {
+	chatID := userID // в нашей архитектуре chatID == userID (private chat)
+
+	text, kb, err := h.renderTodoList(userID)
+	if err != nil {
+		logger.Error("Todo list error: " + err.Error())
+		h.Send(chatID, "Ошибка получения списка задач", TodoKeyboard())
+		return
+	}
+
+	if len(kb.InlineKeyboard) == 0 {
+		h.Send(chatID, text, TodoKeyboard())
+		return
+	}
+	h.SendInline(chatID, text, kb)
+}
+
+// renderTodoList формирует текст списка задач и inline-клавиатуру с действиями по каждой задаче.
+func (h *Handler) renderTodoList(userID int64) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	items, err := h.todo.List(context.Background(), userID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	if len(items) == 0 {
+		return "Список дел пуст", tgbotapi.InlineKeyboardMarkup{}, nil
+	}
+
+	return formatTodoList("Ваши задачи:", items, h.userLocation(userID)), todoListInlineKeyboard(items), nil
+}
+
+// formatTodoList — текст списка задач с заголовком и подсказкой по командам.
+// Подзадачи выводятся с отступом под родительской задачей.
+func formatTodoList(header string, items []todo.Item, loc *time.Location) string {
+	var b strings.Builder
+	b.WriteString(header + "\n\n")
+	for _, it := range nestTodos(items) {
+		if isNested(it, items) {
+			b.WriteString("    ↳ ")
+		}
+		b.WriteString(formatTodoLine(it, loc) + "\n")
+	}
+	b.WriteString("\n" + todoListFooter)
+	return b.String()
+}
+
+// formatTodoLine — одна строка списка задач: статус, ID, заголовок, срок и прогресс чек-листа и подзадач.
+func formatTodoLine(it todo.Item, loc *time.Location) string {
+	mark := "▫️"
+	switch it.Status {
+	case todo.StatusCompleted:
+		mark = "✅"
+	case todo.StatusInProgress:
+		mark = "🔄"
+	}
+	line := fmt.Sprintf("%s %d) %s", mark, it.ID, it.Title)
+	if pm := priorityMarks[it.Priority]; pm != "" {
+		line = fmt.Sprintf("%s %d) %s %s", mark, it.ID, pm, it.Title)
+	}
+	if it.DueDate != nil {
+		line += " — до " + formatDue(*it.DueDate, loc)
+	}
+	if it.Progress.Total > 0 {
+		line += " [" + it.Progress.String() + "]"
+	}
+	if it.RRule != "" {
+		line += " 🔁"
+	}
+	for _, tag := range it.Tags {
+		line += " #" + tag
+	}
+	return line
+}
+
+// todoListInlineKeyboard — по строке inline-кнопок на каждую задачу.
+func todoListInlineKeyboard(items []todo.Item) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(items))
+	for _, it := range nestTodos(items) {
+		toggle := callbackButton("✅ "+truncate(it.Title, 24), cbTodo, actDone, it.ID)
+		if it.Status == todo.StatusCompleted {
+			toggle = callbackButton("↩️ "+truncate(it.Title, 24), cbTodo, actReopen, it.ID)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			toggle,
+			callbackButton("📋", cbTodo, actOpen, it.ID),
+			callbackButton("✏️", cbTodo, actEdit, it.ID),
+			callbackButton("🗑", cbTodo, actDelete, it.ID),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// registerTodoCallbacks регистрирует inline-действия списка задач.
+func (h *Handler) registerTodoCallbacks(r *callbackRouter) {
+	r.Handle(cbTodo, actDone, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		_, next, err := h.todo.CompleteWithNext(context.Background(), cb.From.ID, data.Arg(0))
+		okText := "✅ Выполнено"
+		if next != nil && next.DueDate != nil {
+			okText += ", следующее: " + formatDue(*next.DueDate, h.userLocation(cb.From.ID))
+		}
+		h.afterTodoCallback(cb, okText, err)
+	})
+	r.Handle(cbTodo, actReopen, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		_, err := h.todo.Reopen(context.Background(), cb.From.ID, data.Arg(0))
+		h.afterTodoCallback(cb, "↩️ Снова в работе", err)
+	})
+	r.Handle(cbTodo, actDelete, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		err := h.todo.Delete(context.Background(), cb.From.ID, data.Arg(0))
+		h.afterTodoCallback(cb, "🗑 Удалено", err)
+	})
+	r.Handle(cbTodo, actEdit, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		h.answerCallback(cb, "")
+		h.startTodoEdit(cb.From.ID, data.Arg(0))
+	})
+	r.Handle(cbTodo, actOpen, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		h.answerCallback(cb, "")
+		h.showTodoCard(cb.From.ID, data.Arg(0))
+	})
+	h.registerChecklistCallbacks(r)
+}
+
+// afterTodoCallback отвечает на нажатие кнопки и перерисовывает список задач в том же сообщении.
+func (h *Handler) afterTodoCallback(cb *tgbotapi.CallbackQuery, okText string, err error) {
+	if err != nil {
+		if errors.Is(err, todo.ErrNotFound) {
+			h.answerCallback(cb, "Задача не найдена")
+		} else if errors.Is(err, todo.ErrForbidden) {
+			h.answerCallback(cb, "Недостаточно прав")
+			return
+		} else {
+			logger.Error("Todo callback failed: " + err.Error())
+			h.answerCallback(cb, "Ошибка, попробуйте позже")
+			return
+		}
+	} else {
+		h.answerCallback(cb, okText)
+	}
+
+	text, kb, err := h.renderTodoList(cb.From.ID)
+	if err != nil {
+		logger.Error("Todo list error: " + err.Error())
 		return
 	}
+	h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, text, kb)
+}
 
+// handleTodoCommand обрабатывает /todo, /done, /reopen, /delete_todo, /edit_todo, /due, /tag, /priority, /repeat,
+// /sub, /check, /autodone, /move, /projects и /project.
+func (h *Handler) handleTodoCommand(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
 	userID := update.Message.From.ID
 	chatID := update.Message.Chat.ID
+
+	switch cmd, args, _ := strings.Cut(strings.TrimSpace(update.Message.Text), " "); cmd {
+	case CmdTodoFilter:
+		h.showFilteredTodoList(userID, args)
+		return
+	case CmdProjects:
+		h.handleProjectsCommand(update)
+		return
+	case CmdProject:
+		h.handleProjectCommand(update)
+		return
+	}
+
+	cmd, id, rest, err := parseIDCommand(update.Message.Text)
+	if err != nil {
+		h.Send(chatID, "Использование: "+cmd+" <id>", TodoKeyboard())
+		return
+	}
+
+	switch cmd {
+	case CmdTodoDone:
+		h.completeTodo(userID, id)
+	case CmdTodoReopen:
+		h.reopenTodo(userID, id)
+	case CmdTodoDelete:
+		h.deleteTodo(userID, id)
+	case CmdTodoEdit:
+		if rest == "" {
+			h.startTodoEdit(userID, id)
+			return
+		}
+		h.applyTodoEdit(userID, id, rest)
+	case CmdTodoDue:
+		h.setTodoDue(userID, id, rest)
+	case CmdTodoTag:
+		h.setTodoTags(userID, id, rest)
+	case CmdTodoPriority:
+		h.setTodoPriority(userID, id, rest)
+	case CmdTodoRepeat:
+		h.setTodoRepeat(userID, id, rest)
+	case CmdTodoSub:
+		h.addSubtasks(userID, id, rest)
+	case CmdTodoCheck:
+		if rest == "" {
+			h.showTodoCard(userID, id)
+			return
+		}
+		h.addChecklistItems(userID, id, rest)
+	case CmdTodoAutoDone:
+		h.setTodoAutoComplete(userID, id, rest)
+	case CmdTodoMove:
+		h.moveTodo(userID, id, rest)
+	}
+}
+
+// parseIDCommand разбирает команду вида "/cmd <id> [текст]".
+func parseIDCommand(text string) (cmd string, id int, rest string, err error) {
+	cmd, args, _ := strings.Cut(strings.TrimSpace(text), " ")
+	idStr, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	id, err = strconv.Atoi(idStr)
+	return cmd, id, strings.TrimSpace(rest), err
+}
+
+func (h *Handler) completeTodo(userID int64, id int) {
+	item, next, err := h.todo.CompleteWithNext(context.Background(), userID, id)
+	if err != nil && item == nil {
+		h.sendTodoError(userID, "Todo complete failed", err)
+		return
+	}
+	if err != nil {
+		logger.Error("Todo next occurrence failed: " + err.Error())
+	}
+
+	msg := "✅ Задача выполнена: " + item.Title
+	if next != nil {
+		msg += "\n🔁 Следующее повторение:\n" + formatTodoLine(*next, h.userLocation(userID))
+	}
+	if item.ParentID != nil {
+		if parent, err := h.todo.Get(context.Background(), userID, *item.ParentID); err == nil {
+			if parent.AutoComplete && parent.Status == todo.StatusCompleted {
+				msg += "\n✅ Все подзадачи выполнены, задача «" + parent.Title + "» тоже выполнена"
+			} else {
+				msg += "\nПрогресс «" + parent.Title + "»: " + parent.Progress.String()
+			}
+		}
+	}
+	h.Send(userID, msg, TodoKeyboard())
+}
+
+func (h *Handler) reopenTodo(userID int64, id int) {
+	item, err := h.todo.Reopen(context.Background(), userID, id)
+	if err != nil {
+		h.sendTodoError(userID, "Todo reopen failed", err)
+		return
+	}
+	h.Send(userID, "↩️ Задача снова в работе: "+item.Title, TodoKeyboard())
+}
+
+func (h *Handler) deleteTodo(userID int64, id int) {
+	if err := h.todo.Delete(context.Background(), userID, id); err != nil {
+		h.sendTodoError(userID, "Todo delete failed", err)
+		return
+	}
+	h.Send(userID, "🗑 Задача удалена вместе с подзадачами и чек-листом", TodoKeyboard())
+}
+
+// startTodoEdit запускает FSM редактирования задачи.
+func (h *Handler) startTodoEdit(userID int64, id int) {
+	item, err := h.todo.Get(context.Background(), userID, id)
+	if err != nil {
+		h.sendTodoError(userID, "Todo get failed", err)
+		return
+	}
+
+	if !h.setState(userID, "TODO_EDIT", map[string]any{"id": id}) {
+		return
+	}
+	h.Send(userID, fmt.Sprintf(
+		"Редактирование задачи %d: %s\n\nОтправьте новый текст. Первая строка — заголовок (срок можно указать словами), следующие строки — описание.",
+		item.ID, item.Title,
+	), BackKeyboard())
+}
+
+// handleTodoEdit обрабатывает ввод нового текста в состоянии TODO_EDIT.
+func (h *Handler) handleTodoEdit(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
 	text := strings.TrimSpace(update.Message.Text)
 
-	// проверяем, что у пользователя действительно есть FSM-стейт TODO_ADD
 	state := h.fsm.Get(userID)
-	if state == nil || state.Name != "TODO_ADD" {
-		// нечего делать
-		h.Send(chatID, "Нет активного создания задачи. Нажми «Добавить задачу»", TodoKeyboard())
+	if state == nil || state.Name != "TODO_EDIT" {
 		return
 	}
+	id, _ := state.Data["id"].(int)
 
-	// простая логика: текст сообщения — это заголовок задачи
-	title := text
-	if title == "" {
-		h.Send(chatID, "Пустая задача — введите текст задачи ещё раз:", BackKeyboard())
+	if text == "" {
+		h.Send(userID, "Пустой текст — отправьте новый текст задачи:", BackKeyboard())
 		return
 	}
 
-	// сохраняем через сервис
-	if err := h.todo.Add(context.Background(), userID, title, "", nil); err != nil {
-		logger.Error("Todo add failed: " + err.Error())
-		h.Send(chatID, "Ошибка при сохранении задачи. Попробуйте позже.", BackKeyboard())
+	h.fsm.Clear(userID)
+	h.applyTodoEdit(userID, id, text)
+}
+
+// applyTodoEdit применяет новый текст задачи: первая строка — заголовок со сроком, остальное — описание.
+func (h *Handler) applyTodoEdit(userID int64, id int, text string) {
+	loc := h.userLocation(userID)
+
+	first, desc, hasDesc := strings.Cut(text, "\n")
+	title, due := parseTodoText(strings.TrimSpace(first), loc)
+
+	var descPtr *string
+	if hasDesc {
+		desc = strings.TrimSpace(desc)
+		descPtr = &desc
+	}
+
+	item, err := h.todo.Update(context.Background(), userID, id, &title, descPtr, due)
+	if err != nil {
+		h.sendTodoError(userID, "Todo update failed", err)
 		return
 	}
 
-	// уведомляем пользователя и очищаем FSM
-	h.Send(chatID, "Задача сохранена: "+title, HomeKeyboard())
-	h.fsm.Clear(userID)
+	h.Send(userID, "✏️ Задача обновлена:\n"+formatTodoLine(*item, loc), TodoKeyboard())
 }
 
-// showTodoList: выводит список задач для пользователя.
-// Вызывается из Handler.showTodoList (кнопка / меню).
-func (h *Handler) showTodoList(userID int64) {
-	chatID := userID // в нашей архитектуре chatID == userID (private chat)
+// setTodoDue — /due <id> <срок>; "-" снимает срок.
+func (h *Handler) setTodoDue(userID int64, id int, phrase string) {
 	ctx := context.Background()
+	loc := h.userLocation(userID)
+
+	var item *todo.Item
+	var err error
+
+	switch phrase {
+	case "":
+		h.Send(userID, "Использование: /due <id> <срок>, например /due 5 завтра в 10:00. Чтобы снять срок: /due <id> -", TodoKeyboard())
+		return
+	case "-":
+		item, err = h.todo.ClearDue(ctx, userID, id)
+	default:
+		res, ok := dateparse.Parse(phrase, time.Now().In(loc))
+		if !ok {
+			h.Send(userID, "Не удалось распознать срок. Пример: завтра в 18:00, в пятницу, 12.03", TodoKeyboard())
+			return
+		}
+		item, err = h.todo.Update(ctx, userID, id, nil, nil, &res.Time)
+	}
 
-	items, err := h.todo.List(ctx, userID)
 	if err != nil {
-		logger.Error("Todo list error: " + err.Error())
-		h.Send(chatID, "Ошибка получения списка задач", TodoKeyboard())
+		h.sendTodoError(userID, "Todo due update failed", err)
 		return
 	}
 
-	if len(items) == 0 {
-		h.Send(chatID, "Список дел пуст", TodoKeyboard())
+	h.Send(userID, "📅 Срок обновлён:\n"+formatTodoLine(*item, loc), TodoKeyboard())
+}
+
+// sendTodoError логирует ошибку и сообщает пользователю понятный текст.
+func (h *Handler) sendTodoError(userID int64, op string, err error) {
+	if errors.Is(err, todo.ErrNotFound) {
+		h.Send(userID, "Задача не найдена", TodoKeyboard())
+		return
+	}
+	if errors.Is(err, todo.ErrForbidden) {
+		h.Send(userID, "Недостаточно прав: в этом общем проекте у вас доступ только на просмотр", TodoKeyboard())
 		return
 	}
+	logger.Error(op + ": " + err.Error())
+	h.Send(userID, "Ошибка при обработке задачи. Попробуйте позже.", TodoKeyboard())
+}
 
-	// Формируем читаемое сообщение
-	var b strings.Builder
-	b.WriteString("Ваши задачи:\n\n")
-	for _, it := range items {
-		// ID и заголовок, можно добавить статус/дедлайн
-		line := fmt.Sprintf("%d) %s\n", it.ID, it.Title)
-		b.WriteString(line)
+// parseTodoText извлекает срок задачи из текста в часовом поясе пользователя.
+// Возвращает заголовок без фразы с датой; если кроме даты ничего нет, заголовком остаётся весь текст.
+func parseTodoText(text string, loc *time.Location) (string, *time.Time) {
+	res, ok := dateparse.Parse(text, time.Now().In(loc))
+	if !ok || res.Rest == "" {
+		return text, nil
 	}
-	b.WriteString("\nЧтобы удалить задачу — отправь команду /delete_todo <id>")
+	return res.Rest, &res.Time
+}
 
-	h.Send(chatID, b.String(), TodoKeyboard())
+// formatDue форматирует срок задачи в часовом поясе пользователя.
+func formatDue(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("02.01.2006 15:04")
 }
@@ -0,0 +1,169 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Массовый импорт задач.
+// /import [id проекта] — бот ждёт многострочный текст или чек-лист Markdown,
+// показывает, какие задачи будут созданы, и сохраняет их только после подтверждения.
+
+// stateTodoImport — состояние FSM ожидания текста импорта.
+const stateTodoImport = "TODO_IMPORT"
+
+const importUsage = "📥 Импорт задач\n\n" +
+	"Отправьте список одним сообщением — каждая строка станет задачей:\n" +
+	"- [ ] Собрать вещи\n" +
+	"  - [ ] Купить коробки\n" +
+	"- [x] Оплатить интернет\n" +
+	"Заказать грузовик завтра в 10:00\n\n" +
+	"Строки с отступом — подзадачи, [x] — уже выполнено, срок можно указать словами в строке.\n" +
+	"Перед сохранением покажу, что получилось."
+
+// handleImportCommand — /import [id проекта].
+func (h *Handler) handleImportCommand(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	arg := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, CmdTodoImport))
+
+	projectID := 0
+	header := importUsage
+	if arg != "" {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			h.Send(userID, "Использование: /import [id проекта]. Проекты: /projects", TodoKeyboard())
+			return
+		}
+		p, err := h.todo.Project(context.Background(), userID, id)
+		if err != nil {
+			h.sendProjectError(userID, "Todo import project failed", err)
+			return
+		}
+		if !p.Role.CanEdit() {
+			h.sendProjectError(userID, "Todo import project failed", todo.ErrForbidden)
+			return
+		}
+		projectID = p.ID
+		header += "\n\nЗадачи попадут в проект «" + p.Name + "»."
+	}
+
+	if !h.setState(userID, stateTodoImport, map[string]any{"project_id": projectID}) {
+		return
+	}
+	h.Send(userID, header, BackKeyboard())
+}
+
+// handleTodoImport принимает текст импорта в состоянии TODO_IMPORT и показывает предпросмотр.
+// Текст и момент разбора сохраняются в состоянии: при подтверждении список разбирается заново с тем же now,
+// поэтому сохраняется ровно то, что показано. Новое сообщение заменяет текст импорта.
+func (h *Handler) handleTodoImport(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	text := strings.TrimSpace(update.Message.Text)
+
+	state := h.fsm.Get(userID)
+	if state == nil || state.Name != stateTodoImport {
+		return
+	}
+	projectID, _ := state.Data["project_id"].(int)
+
+	loc := h.userLocation(userID)
+	now := time.Now().In(loc)
+	items := todo.ParseImport(text, now)
+	switch n := todo.CountImport(items); {
+	case n == 0:
+		h.Send(userID, "Не нашёл ни одной задачи — отправьте список, по строке на задачу:", BackKeyboard())
+		return
+	case n > todo.MaxImportItems:
+		h.Send(userID, fmt.Sprintf("В списке %d задач, за раз можно импортировать не больше %d. Разделите список:", n, todo.MaxImportItems), BackKeyboard())
+		return
+	}
+
+	if !h.setState(userID, stateTodoImport, map[string]any{"project_id": projectID, "text": text, "now": now}) {
+		return
+	}
+	kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		callbackButton(CmdConfirm, cbImport, actDone),
+		callbackButton("❌ Отмена", cbImport, actDelete),
+	))
+	h.SendInline(userID, formatImportPreview(items, loc), kb)
+}
+
+// registerImportCallbacks регистрирует кнопки подтверждения и отмены импорта.
+func (h *Handler) registerImportCallbacks(r *callbackRouter) {
+	r.Handle(cbImport, actDone, func(cb *tgbotapi.CallbackQuery, _ callbackData) {
+		userID := cb.From.ID
+		state := h.fsm.Get(userID)
+		if state == nil || state.Name != stateTodoImport || state.Data["text"] == nil {
+			h.answerCallback(cb, "Импорт устарел, начните заново: /import")
+			return
+		}
+		text, _ := state.Data["text"].(string)
+		projectID, _ := state.Data["project_id"].(int)
+		now, _ := state.Data["now"].(time.Time)
+		now = now.In(h.userLocation(userID))
+		h.fsm.Clear(userID)
+
+		created, err := h.todo.Import(context.Background(), userID, projectID, todo.ParseImport(text, now))
+		if err != nil {
+			logger.Error("Todo import failed: " + err.Error())
+			h.answerCallback(cb, "Ошибка импорта")
+			h.Edit(cb.Message.Chat.ID, cb.Message.MessageID,
+				fmt.Sprintf("⚠️ Импорт прерван: создано задач — %d. Попробуйте импортировать оставшиеся позже.", created),
+				tgbotapi.InlineKeyboardMarkup{})
+			return
+		}
+		h.answerCallback(cb, "📥 Задачи созданы")
+		h.Edit(cb.Message.Chat.ID, cb.Message.MessageID,
+			fmt.Sprintf("📥 Импортировано задач: %d. Список: %s", created, CmdTodoFilter), tgbotapi.InlineKeyboardMarkup{})
+		h.Send(userID, "Модуль задач", TodoKeyboard())
+	})
+	r.Handle(cbImport, actDelete, func(cb *tgbotapi.CallbackQuery, _ callbackData) {
+		if state := h.fsm.Get(cb.From.ID); state != nil && state.Name == stateTodoImport {
+			h.fsm.Clear(cb.From.ID)
+		}
+		h.answerCallback(cb, "Импорт отменён")
+		h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, "Импорт отменён", tgbotapi.InlineKeyboardMarkup{})
+		h.Send(cb.From.ID, "Модуль задач", TodoKeyboard())
+	})
+}
+
+// formatImportPreview — текст предпросмотра импорта: задачи, подзадачи, сроки и отметки выполнения.
+func formatImportPreview(items []todo.ImportItem, loc *time.Location) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("📥 Будет создано задач: %d\n\n", todo.CountImport(items)))
+	for _, it := range items {
+		b.WriteString(formatImportLine("", it, loc))
+		for _, ch := range it.Children {
+			b.WriteString(formatImportLine("    ↳ ", ch, loc))
+		}
+	}
+	b.WriteString("\nСохранить? Чтобы исправить список, отправьте его заново.")
+	return b.String()
+}
+
+// formatImportLine — строка предпросмотра одной задачи.
+func formatImportLine(prefix string, it todo.ImportItem, loc *time.Location) string {
+	mark := "▫️ "
+	if it.Done {
+		mark = "✅ "
+	}
+	line := prefix + mark + it.Title
+	if it.DueDate != nil {
+		line += " — " + formatDue(*it.DueDate, loc)
+	}
+	return line + "\n"
+}
@@ -0,0 +1,269 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Проекты задач.
+// /projects — список проектов, /project <id> — задачи проекта,
+// /project new|rename|archive|restore|delete|sort — управление проектами, /move <id> <проект> — перенос задачи.
+// Подкоманды общих проектов реализованы в todo_share_impl.go.
+
+const projectUsage = "Проекты:\n" +
+	"/projects — список проектов (/projects all — вместе с архивом)\n" +
+	"/project <id> — задачи проекта\n" +
+	"/project new <название> — новый проект\n" +
+	"/project rename <id> <название>\n" +
+	"/project sort <id> created|due|priority|title — порядок задач\n" +
+	"/project archive <id>, /project restore <id>\n" +
+	"/project delete <id> — задачи перейдут в Inbox\n" +
+	"/move <id задачи> <id проекта> — перенести задачу\n\n" + shareUsage
+
+// sortOrderTitles — названия порядков сортировки для пользователя.
+var sortOrderTitles = map[todo.SortOrder]string{
+	todo.SortCreated:  "сначала новые",
+	todo.SortDue:      "по сроку",
+	todo.SortPriority: "по приоритету",
+	todo.SortTitle:    "по алфавиту",
+}
+
+// projectStep — шаг выбора проекта в мастере добавления задачи.
+// Показывается, только если у пользователя есть проекты кроме Inbox; общие проекты только для просмотра не предлагаются.
+func (h *Handler) projectStep() wizardStep {
+	projects := func(data map[string]any) []todo.Project {
+		list, err := h.todo.Projects(context.Background(), wizardUser(data), false)
+		if err != nil {
+			logger.Error("Todo projects error: " + err.Error())
+		}
+		editable := list[:0]
+		for _, p := range list {
+			if p.Role.CanEdit() {
+				editable = append(editable, p)
+			}
+		}
+		return editable
+	}
+
+	return wizardStep{
+		Key:    "project",
+		Label:  "Проект",
+		Prompt: staticPrompt("Выберите проект:"),
+		Options: func(data map[string]any) []string {
+			var names []string
+			for _, p := range projects(data) {
+				names = append(names, p.Name)
+			}
+			return names
+		},
+		When: func(data map[string]any) bool {
+			return len(projects(data)) > 1
+		},
+		Parse: func(text string, data map[string]any) (any, error) {
+			for _, p := range projects(data) {
+				if strings.EqualFold(p.Name, text) {
+					data["project_id"] = p.ID
+					return p.Name, nil
+				}
+			}
+			return nil, errors.New("Нет такого проекта — выберите проект на клавиатуре:")
+		},
+	}
+}
+
+// handleProjectsCommand — /projects [all].
+func (h *Handler) handleProjectsCommand(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	_, arg, _ := strings.Cut(strings.TrimSpace(update.Message.Text), " ")
+	h.showProjects(userID, strings.TrimSpace(arg) == "all")
+}
+
+// showProjects выводит проекты пользователя с кнопками открытия.
+func (h *Handler) showProjects(userID int64, includeArchived bool) {
+	list, err := h.todo.Projects(context.Background(), userID, includeArchived)
+	if err != nil {
+		logger.Error("Todo projects error: " + err.Error())
+		h.Send(userID, "Ошибка получения проектов", TodoKeyboard())
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("📁 Проекты:\n\n")
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(list))
+	for _, p := range list {
+		b.WriteString(formatProjectLine(p) + "\n")
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			callbackButton(fmt.Sprintf("📂 %s (%d)", truncate(p.Name, 24), p.Open), cbProject, actOpen, p.ID),
+		))
+	}
+	b.WriteString("\n" + projectUsage)
+
+	h.SendInline(userID, b.String(), tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// formatProjectLine — строка списка проектов.
+func formatProjectLine(p todo.Project) string {
+	line := fmt.Sprintf("%d) %s — открытых задач: %d, %s", p.ID, p.Name, p.Open, sortOrderTitles[p.SortOrder])
+	if p.Role != todo.RoleOwner {
+		line += ", общий (" + roleTitles[p.Role] + ")"
+	} else if p.Members > 0 {
+		line += fmt.Sprintf(", участников: %d", p.Members)
+	}
+	if p.Shared() {
+		line = "👥 " + line
+	}
+	if p.Archived {
+		line = "🗄 " + line
+	}
+	return line
+}
+
+// registerProjectCallbacks регистрирует кнопки списка проектов.
+func (h *Handler) registerProjectCallbacks(r *callbackRouter) {
+	r.Handle(cbProject, actOpen, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		h.answerCallback(cb, "")
+		h.showProjectTodos(cb.From.ID, data.Arg(0))
+	})
+}
+
+// showProjectTodos выводит задачи проекта в заданном в проекте порядке.
+func (h *Handler) showProjectTodos(userID int64, projectID int) {
+	p, items, err := h.todo.ListProject(context.Background(), userID, projectID)
+	if err != nil {
+		h.sendProjectError(userID, "Todo project list failed", err)
+		return
+	}
+
+	header := "📁 " + p.Name + ":"
+	if len(items) == 0 {
+		h.Send(userID, header+"\n\nЗадач нет", TodoKeyboard())
+		return
+	}
+	h.SendInline(userID, formatTodoList(header, items, h.userLocation(userID)), todoListInlineKeyboard(items))
+}
+
+// handleProjectCommand — /project <id> | new | rename | sort | archive | restore | delete | share | members | kick | leave.
+func (h *Handler) handleProjectCommand(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	ctx := context.Background()
+
+	_, args, _ := strings.Cut(strings.TrimSpace(update.Message.Text), " ")
+	sub, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	rest = strings.TrimSpace(rest)
+
+	if id, err := strconv.Atoi(sub); err == nil {
+		h.showProjectTodos(userID, id)
+		return
+	}
+
+	if h.handleProjectShareCommand(userID, sub, rest) {
+		return
+	}
+
+	if sub == "new" {
+		p, err := h.todo.CreateProject(ctx, userID, rest)
+		if err != nil {
+			h.sendProjectError(userID, "Todo project create failed", err)
+			return
+		}
+		h.Send(userID, "📁 Проект создан:\n"+formatProjectLine(*p), TodoKeyboard())
+		return
+	}
+
+	idStr, value, _ := strings.Cut(rest, " ")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.Send(userID, projectUsage, TodoKeyboard())
+		return
+	}
+	value = strings.TrimSpace(value)
+
+	var u todo.ProjectUpdate
+	switch sub {
+	case "rename":
+		u.Name = &value
+	case "sort":
+		order, ok := todo.ParseSortOrder(value)
+		if !ok || value == "" {
+			h.Send(userID, "Порядок: created, due, priority или title", TodoKeyboard())
+			return
+		}
+		u.SortOrder = &order
+	case "archive", "restore":
+		archived := sub == "archive"
+		u.Archived = &archived
+	case "delete":
+		if err := h.todo.DeleteProject(ctx, userID, id); err != nil {
+			h.sendProjectError(userID, "Todo project delete failed", err)
+			return
+		}
+		h.Send(userID, "🗑 Проект удалён, его задачи перенесены в "+todo.InboxName, TodoKeyboard())
+		return
+	default:
+		h.Send(userID, projectUsage, TodoKeyboard())
+		return
+	}
+
+	p, err := h.todo.UpdateProject(ctx, userID, id, u)
+	if err != nil {
+		h.sendProjectError(userID, "Todo project update failed", err)
+		return
+	}
+	h.Send(userID, "📁 Проект обновлён:\n"+formatProjectLine(*p), TodoKeyboard())
+}
+
+// moveTodo — /move <id> <id проекта>.
+func (h *Handler) moveTodo(userID int64, id int, arg string) {
+	projectID, err := strconv.Atoi(arg)
+	if err != nil {
+		h.Send(userID, "Использование: /move <id задачи> <id проекта>. Проекты: /projects", TodoKeyboard())
+		return
+	}
+
+	item, err := h.todo.MoveToProject(context.Background(), userID, id, projectID)
+	if err != nil {
+		if errors.Is(err, todo.ErrInvalidParent) {
+			h.Send(userID, "Подзадача переносится вместе с родительской задачей", TodoKeyboard())
+			return
+		}
+		h.sendProjectError(userID, "Todo move failed", err)
+		return
+	}
+	h.Send(userID, "📁 Задача перенесена:\n"+formatTodoLine(*item, h.userLocation(userID)), TodoKeyboard())
+}
+
+// sendProjectError сообщает пользователю понятный текст ошибки проекта.
+func (h *Handler) sendProjectError(userID int64, op string, err error) {
+	switch {
+	case errors.Is(err, todo.ErrProjectNotFound):
+		h.Send(userID, "Проект не найден", TodoKeyboard())
+	case errors.Is(err, todo.ErrProjectExists):
+		h.Send(userID, "Проект с таким названием уже есть", TodoKeyboard())
+	case errors.Is(err, todo.ErrProjectName):
+		h.Send(userID, "Укажите название проекта (до 64 символов)", TodoKeyboard())
+	case errors.Is(err, todo.ErrInboxProject):
+		h.Send(userID, "Проект "+todo.InboxName+" нельзя переименовать, архивировать или удалить", TodoKeyboard())
+	case errors.Is(err, todo.ErrForbidden):
+		h.Send(userID, "Недостаточно прав: проектом и участниками управляет владелец, задачи изменяют участники с правом edit", TodoKeyboard())
+	case errors.Is(err, todo.ErrInboxShared):
+		h.Send(userID, "Проект "+todo.InboxName+" нельзя сделать общим — создайте отдельный проект: /project new <название>", TodoKeyboard())
+	case errors.Is(err, todo.ErrNotMember):
+		h.Send(userID, "Такого участника в проекте нет", TodoKeyboard())
+	default:
+		h.sendTodoError(userID, op, err)
+	}
+}
@@ -0,0 +1,270 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/dateparse"
+	"tg_bot_asist/internal/digest"
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Действия с напоминанием прямо из уведомления: отложить на 15 минут, на час,
+// до завтрашнего утра или до произвольного срока, выполнить или принять
+// (прекратить повторные напоминания о просроченной задаче).
+// /nudge — политика повторных напоминаний пользователя.
+
+// Особые значения аргумента «на сколько минут» кнопки «Отложить».
+const (
+	snoozeCustom          = 0  // спросить срок у пользователя
+	snoozeTomorrowMorning = -1 // завтра утром по политике пользователя
+)
+
+// stateTodoSnooze — состояние FSM ввода произвольного срока откладывания.
+const stateTodoSnooze = "TODO_SNOOZE"
+
+// reminderKeyboard — кнопки под напоминанием о задаче.
+func reminderKeyboard(todoID int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			callbackButton("⏱ 15 мин", cbReminder, actSnooze, todoID, 15),
+			callbackButton("⏱ 1 ч", cbReminder, actSnooze, todoID, 60),
+			callbackButton("🌅 Завтра утром", cbReminder, actSnooze, todoID, snoozeTomorrowMorning),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			callbackButton("🗓 Другое время", cbReminder, actSnooze, todoID, snoozeCustom),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			callbackButton("✅ Выполнено", cbReminder, actDone, todoID),
+			callbackButton("👌 Принято", cbReminder, actAck, todoID),
+		),
+	)
+}
+
+// registerReminderCallbacks регистрирует кнопки под напоминаниями.
+func (h *Handler) registerReminderCallbacks(r *callbackRouter) {
+	r.Handle(cbReminder, actSnooze, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		userID := cb.From.ID
+		id, minutes := data.Arg(0), data.Arg(1)
+		ctx := context.Background()
+
+		var until time.Time
+		switch {
+		case minutes == snoozeCustom:
+			h.answerCallback(cb, "")
+			if !h.setState(userID, stateTodoSnooze, map[string]any{"id": id}) {
+				return
+			}
+			h.Send(userID, "До какого времени отложить? Например: через 3 часа, завтра в 18:00, в пятницу, 12.03", BackKeyboard())
+			return
+		case minutes == snoozeTomorrowMorning:
+			p, err := h.todo.EscalationPolicy(ctx, userID)
+			if err != nil {
+				h.afterReminderCallback(cb, "", err)
+				return
+			}
+			until = p.TomorrowMorning(time.Now(), h.userLocation(userID))
+		default:
+			until = time.Now().Add(time.Duration(minutes) * time.Minute)
+		}
+
+		_, err := h.todo.Snooze(ctx, userID, id, until)
+		h.afterReminderCallback(cb, "⏱ Отложено до "+formatDue(until, h.userLocation(userID)), err)
+	})
+	r.Handle(cbReminder, actDone, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		_, next, err := h.todo.CompleteWithNext(context.Background(), cb.From.ID, data.Arg(0))
+		okText := "✅ Выполнено"
+		if next != nil && next.DueDate != nil {
+			okText += ", следующее: " + formatDue(*next.DueDate, h.userLocation(cb.From.ID))
+		}
+		h.afterReminderCallback(cb, okText, err)
+	})
+	r.Handle(cbReminder, actAck, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		err := h.todo.Acknowledge(context.Background(), cb.From.ID, data.Arg(0))
+		h.afterReminderCallback(cb, "👌 Принято, больше не напомню", err)
+	})
+}
+
+// afterReminderCallback отвечает на нажатие кнопки и дописывает результат к напоминанию, убирая кнопки.
+func (h *Handler) afterReminderCallback(cb *tgbotapi.CallbackQuery, okText string, err error) {
+	if err != nil {
+		if errors.Is(err, todo.ErrNotFound) {
+			h.answerCallback(cb, "Задача не найдена")
+			h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, cb.Message.Text+"\n\nЗадача удалена", tgbotapi.InlineKeyboardMarkup{})
+			return
+		}
+		if errors.Is(err, todo.ErrForbidden) {
+			h.answerCallback(cb, "Недостаточно прав")
+			return
+		}
+		logger.Error("Reminder callback failed: " + err.Error())
+		h.answerCallback(cb, "Ошибка, попробуйте позже")
+		return
+	}
+
+	h.answerCallback(cb, okText)
+	h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, cb.Message.Text+"\n\n"+okText, tgbotapi.InlineKeyboardMarkup{})
+}
+
+// handleTodoSnooze обрабатывает ввод срока в состоянии TODO_SNOOZE.
+func (h *Handler) handleTodoSnooze(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	text := strings.TrimSpace(update.Message.Text)
+
+	state := h.fsm.Get(userID)
+	if state == nil || state.Name != stateTodoSnooze {
+		return
+	}
+	id, _ := state.Data["id"].(int)
+
+	loc := h.userLocation(userID)
+	now := time.Now().In(loc)
+	res, ok := dateparse.Parse(text, now)
+	if !ok || !res.Time.After(now) {
+		h.Send(userID, "Не удалось распознать срок в будущем. Пример: через 3 часа, завтра в 18:00, 12.03", BackKeyboard())
+		return
+	}
+
+	h.fsm.Clear(userID)
+	item, err := h.todo.Snooze(context.Background(), userID, id, res.Time)
+	if err != nil {
+		h.sendTodoError(userID, "Todo snooze failed", err)
+		return
+	}
+	h.Send(userID, "⏱ Отложено:\n"+formatTodoLine(*item, loc), TodoKeyboard())
+}
+
+const nudgeHelp = "Команды:\n" +
+	"/nudge on | off — повторять напоминания о просроченных задачах или нет\n" +
+	"/nudge first 30m — через сколько после срока первое повторное напоминание\n" +
+	"/nudge x2 — во сколько раз растёт интервал\n" +
+	"/nudge max 1d — наибольший интервал\n" +
+	"/nudge count 5 — сколько раз напоминать (0 — не напоминать)\n" +
+	"/nudge morning 09:00 — время для кнопки «Завтра утром»\n" +
+	"Можно сразу несколько: /nudge first 15m x3 max 12h count 4"
+
+// handleNudgeCommand — /nudge [параметры], политика повторных напоминаний.
+func (h *Handler) handleNudgeCommand(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+	ctx := context.Background()
+
+	p, err := h.todo.EscalationPolicy(ctx, userID)
+	if err != nil {
+		h.Send(chatID, "Ошибка загрузки настроек напоминаний", TodoKeyboard())
+		return
+	}
+
+	args := strings.Fields(update.Message.Text)[1:]
+	if len(args) == 0 {
+		h.Send(chatID, describeNudgePolicy(p)+"\n\n"+nudgeHelp, TodoKeyboard())
+		return
+	}
+
+	if err := applyNudgeArgs(&p, args); err != nil {
+		h.Send(chatID, err.Error()+"\n\n"+nudgeHelp, TodoKeyboard())
+		return
+	}
+	if err := h.todo.SaveEscalationPolicy(ctx, p); err != nil {
+		if errors.Is(err, todo.ErrInvalidPolicy) {
+			h.Send(chatID, "Ошибка: "+err.Error(), TodoKeyboard())
+			return
+		}
+		logger.Error("SaveEscalationPolicy failed: " + err.Error())
+		h.Send(chatID, "Ошибка сохранения настроек напоминаний", TodoKeyboard())
+		return
+	}
+	h.Send(chatID, "Сохранено.\n\n"+describeNudgePolicy(p), TodoKeyboard())
+}
+
+// applyNudgeArgs применяет аргументы /nudge к политике.
+func applyNudgeArgs(p *todo.EscalationPolicy, args []string) error {
+	for i := 0; i < len(args); i++ {
+		arg := strings.ToLower(args[i])
+		value := func() (string, error) {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("не указано значение для %s", arg)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch {
+		case arg == "on":
+			p.Enabled = true
+		case arg == "off":
+			p.Enabled = false
+		case arg == "first" || arg == "max":
+			v, err := value()
+			if err != nil {
+				return err
+			}
+			d, err := todo.ParseLeads(v)
+			if err != nil || len(d) != 1 {
+				return fmt.Errorf("неверный интервал %q, пример: 30m, 2h, 1d", v)
+			}
+			if arg == "first" {
+				p.FirstDelay = d[0]
+			} else {
+				p.MaxInterval = d[0]
+			}
+		case strings.HasPrefix(arg, "x"):
+			f, err := strconv.ParseFloat(strings.ReplaceAll(arg[1:], ",", "."), 64)
+			if err != nil {
+				return fmt.Errorf("неверный множитель %q, пример: x2", arg)
+			}
+			p.Factor = f
+		case arg == "count":
+			v, err := value()
+			if err != nil {
+				return err
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("неверное число напоминаний %q", v)
+			}
+			p.MaxNudges = n
+		case arg == "morning":
+			v, err := value()
+			if err != nil {
+				return err
+			}
+			m, err := digest.ParseSendTime(v)
+			if err != nil {
+				return err
+			}
+			p.MorningMinute = m
+		default:
+			return fmt.Errorf("непонятный параметр %q", args[i])
+		}
+	}
+	return nil
+}
+
+// describeNudgePolicy — текущая политика повторных напоминаний для пользователя.
+func describeNudgePolicy(p todo.EscalationPolicy) string {
+	morning := fmt.Sprintf("%02d:%02d", p.MorningMinute/60, p.MorningMinute%60)
+	if !p.Enabled || p.MaxNudges == 0 {
+		return "🔕 Повторные напоминания о просроченных задачах выключены\n«Завтра утром» — " + morning
+	}
+
+	intervals := make([]string, 0, p.MaxNudges)
+	for n := 1; n <= p.MaxNudges; n++ {
+		intervals = append(intervals, todo.FormatLead(p.Interval(n)))
+	}
+	return fmt.Sprintf("🔔 Повторные напоминания о просроченных задачах: %d раз(а)\nИнтервалы: %s\n«Завтра утром» — %s",
+		p.MaxNudges, strings.Join(intervals, " → "), morning)
+}
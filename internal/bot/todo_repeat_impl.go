@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"tg_bot_asist/internal/todo"
+)
+
+// Повторяющиеся задачи: /repeat <id> <правило>.
+// Правило — RRULE (RFC 5545) или короткое название из repeatPresets; "-" отключает повтор.
+
+// repeatPresets — короткие названия часто используемых правил.
+var repeatPresets = map[string]string{
+	"ежедневно":      "FREQ=DAILY",
+	"daily":          "FREQ=DAILY",
+	"по будням":      "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+	"weekdays":       "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+	"еженедельно":    "FREQ=WEEKLY",
+	"weekly":         "FREQ=WEEKLY",
+	"раз в 2 недели": "FREQ=WEEKLY;INTERVAL=2",
+	"ежемесячно":     "FREQ=MONTHLY",
+	"monthly":        "FREQ=MONTHLY",
+	"в конце месяца": "FREQ=MONTHLY;BYMONTHDAY=-1",
+	"ежегодно":       "FREQ=YEARLY",
+	"yearly":         "FREQ=YEARLY",
+}
+
+const repeatUsage = "Использование: /repeat <id> <правило>\n\n" +
+	"Коротко: ежедневно, по будням, еженедельно, раз в 2 недели, ежемесячно, в конце месяца, ежегодно.\n" +
+	"RRULE: FREQ=MONTHLY;BYDAY=2TU — каждый второй вторник месяца, FREQ=WEEKLY;INTERVAL=2;BYDAY=TU — вторник раз в две недели.\n" +
+	"Отключить повтор: /repeat <id> -\n\n" +
+	"Следующее повторение создаётся, когда текущее отмечено выполненным."
+
+// setTodoRepeat — /repeat <id> <правило|->.
+func (h *Handler) setTodoRepeat(userID int64, id int, arg string) {
+	if arg == "" {
+		h.Send(userID, repeatUsage, TodoKeyboard())
+		return
+	}
+
+	rule := arg
+	if preset, ok := repeatPresets[strings.ToLower(arg)]; ok {
+		rule = preset
+	}
+	if arg == "-" {
+		rule = ""
+	}
+
+	item, err := h.todo.SetRecurrence(context.Background(), userID, id, rule)
+	if err != nil {
+		if errors.Is(err, todo.ErrInvalidRecurrence) {
+			h.Send(userID, "Ошибка: "+err.Error()+"\n\n"+repeatUsage, TodoKeyboard())
+			return
+		}
+		h.sendTodoError(userID, "Todo recurrence update failed", err)
+		return
+	}
+
+	msg := "🔁 Повтор отключён:\n"
+	if item.RRule != "" {
+		msg = "🔁 Повтор: " + item.RRule + "\n"
+	}
+	h.Send(userID, msg+formatTodoLine(*item, h.userLocation(userID)), TodoKeyboard())
+}
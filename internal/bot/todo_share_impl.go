@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tg_bot_asist/internal/todo"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Общие проекты задач.
+// /project share <id> view|edit — ссылка-приглашение, /project members <id> — участники,
+// /project kick <id> <user_id> — исключить участника, /project leave <id> — выйти из проекта.
+// Приглашение принимается по ссылке t.me/<бот>?start=join_<токен>.
+
+const shareUsage = "Общие проекты:\n" +
+	"/project share <id> view|edit — ссылка-приглашение (просмотр или изменение)\n" +
+	"/project members <id> — участники\n" +
+	"/project kick <id> <user_id> — исключить участника\n" +
+	"/project leave <id> — выйти из общего проекта"
+
+// joinPrefix — префикс параметра /start для приглашения в проект.
+const joinPrefix = "join_"
+
+// roleTitles — названия ролей для пользователя.
+var roleTitles = map[todo.Role]string{
+	todo.RoleOwner: "владелец",
+	todo.RoleEdit:  "изменение",
+	todo.RoleView:  "просмотр",
+}
+
+// handleProjectShareCommand обрабатывает подкоманды share, members, kick и leave команды /project.
+// Возвращает false, если подкоманда не относится к общим проектам.
+func (h *Handler) handleProjectShareCommand(userID int64, sub, rest string) bool {
+	switch sub {
+	case "share", "members", "kick", "leave":
+	default:
+		return false
+	}
+
+	idStr, value, _ := strings.Cut(rest, " ")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.Send(userID, shareUsage, TodoKeyboard())
+		return true
+	}
+	value = strings.TrimSpace(value)
+	ctx := context.Background()
+
+	switch sub {
+	case "share":
+		h.shareProject(userID, id, value)
+	case "members":
+		h.showProjectMembers(userID, id)
+	case "kick":
+		memberID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			h.Send(userID, "Использование: /project kick <id проекта> <user_id>. Участники: /project members <id>", TodoKeyboard())
+			return true
+		}
+		if err := h.todo.RemoveMember(ctx, userID, id, memberID); err != nil {
+			h.sendProjectError(userID, "Todo project kick failed", err)
+			return true
+		}
+		h.Send(userID, "👋 Участник исключён, его задачи остались в проекте", TodoKeyboard())
+		if p, err := h.todo.Project(ctx, userID, id); err == nil {
+			h.SendInline(memberID, "👋 Вас исключили из проекта «"+p.Name+"»", tgbotapi.InlineKeyboardMarkup{})
+		}
+	case "leave":
+		p, err := h.todo.Project(ctx, userID, id)
+		if err != nil {
+			h.sendProjectError(userID, "Todo project leave failed", err)
+			return true
+		}
+		if p.Role == todo.RoleOwner {
+			h.Send(userID, "Владелец не может выйти из своего проекта — удалите его: /project delete "+idStr, TodoKeyboard())
+			return true
+		}
+		if err := h.todo.RemoveMember(ctx, userID, id, userID); err != nil {
+			h.sendProjectError(userID, "Todo project leave failed", err)
+			return true
+		}
+		h.Send(userID, "👋 Вы вышли из проекта «"+p.Name+"»", TodoKeyboard())
+		h.SendInline(p.UserID, fmt.Sprintf("👋 %s вышел(а) из проекта «%s»", h.userName(userID), p.Name), tgbotapi.InlineKeyboardMarkup{})
+	}
+	return true
+}
+
+// shareProject создаёт ссылку-приглашение в проект.
+func (h *Handler) shareProject(userID int64, projectID int, roleArg string) {
+	if roleArg == "" {
+		roleArg = string(todo.RoleView)
+	}
+	role, ok := todo.ParseRole(roleArg)
+	if !ok {
+		h.Send(userID, "Права: view (просмотр) или edit (изменение)", TodoKeyboard())
+		return
+	}
+
+	inv, err := h.todo.Invite(context.Background(), userID, projectID, role)
+	if err != nil {
+		h.sendProjectError(userID, "Todo project invite failed", err)
+		return
+	}
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s%s", h.bot.Self.UserName, joinPrefix, inv.Token)
+	h.Send(userID, fmt.Sprintf(
+		"🔗 Приглашение в проект (права: %s).\nСсылка одноразовая, действует до %s:\n%s",
+		roleTitles[role], inv.ExpiresAt.In(h.userLocation(userID)).Format("02.01.2006 15:04"), link,
+	), TodoKeyboard())
+}
+
+// showProjectMembers выводит владельца и участников проекта.
+func (h *Handler) showProjectMembers(userID int64, projectID int) {
+	ctx := context.Background()
+	p, err := h.todo.Project(ctx, userID, projectID)
+	if err != nil {
+		h.sendProjectError(userID, "Todo project members failed", err)
+		return
+	}
+	members, err := h.todo.Members(ctx, userID, projectID)
+	if err != nil {
+		h.sendProjectError(userID, "Todo project members failed", err)
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("👥 Участники проекта «" + p.Name + "»:\n\n")
+	b.WriteString(fmt.Sprintf("👑 %s (%d) — %s\n", h.userName(p.UserID), p.UserID, roleTitles[todo.RoleOwner]))
+	for _, m := range members {
+		b.WriteString(fmt.Sprintf("▫️ %s (%d) — %s\n", h.userName(m.UserID), m.UserID, roleTitles[m.Role]))
+	}
+	if len(members) == 0 {
+		b.WriteString("\nУчастников нет. Пригласить: /project share " + strconv.Itoa(p.ID) + " view|edit")
+	}
+	h.Send(userID, b.String(), TodoKeyboard())
+}
+
+// handleStartPayload обрабатывает /start с параметром deep link (t.me/<бот>?start=...).
+func (h *Handler) handleStartPayload(update tgbotapi.Update) {
+	userID := update.Message.From.ID
+	payload := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, CmdStart))
+
+	token, ok := strings.CutPrefix(payload, joinPrefix)
+	if !ok {
+		h.Send(userID, startGreeting, HomeKeyboard())
+		return
+	}
+
+	p, err := h.todo.Join(context.Background(), userID, token)
+	if err != nil {
+		if errors.Is(err, todo.ErrInviteNotFound) {
+			h.Send(userID, "Приглашение не найдено или устарело. Попросите владельца проекта прислать новую ссылку.", HomeKeyboard())
+			return
+		}
+		if errors.Is(err, todo.ErrAlreadyMember) {
+			h.Send(userID, "Вы уже участник этого проекта — приглашение не использовано, его можно передать другому.", TodoKeyboard())
+			return
+		}
+		h.sendProjectError(userID, "Todo project join failed", err)
+		return
+	}
+
+	h.Send(userID, fmt.Sprintf("👥 Вы присоединились к проекту «%s» (права: %s).\nЗадачи проекта: /project %d",
+		p.Name, roleTitles[p.Role], p.ID), TodoKeyboard())
+	if p.Role != todo.RoleOwner {
+		h.SendInline(p.UserID, fmt.Sprintf("👥 %s присоединился(ась) к проекту «%s» (права: %s)",
+			h.userName(userID), p.Name, roleTitles[p.Role]), tgbotapi.InlineKeyboardMarkup{})
+	}
+}
+
+// userName возвращает имя пользователя Telegram или его ID, если имя получить не удалось.
+func (h *Handler) userName(userID int64) string {
+	chat, err := h.bot.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: userID}})
+	if err != nil {
+		return strconv.FormatInt(userID, 10)
+	}
+	if chat.UserName != "" {
+		return "@" + chat.UserName
+	}
+	if name := strings.TrimSpace(chat.FirstName + " " + chat.LastName); name != "" {
+		return name
+	}
+	return strconv.FormatInt(userID, 10)
+}
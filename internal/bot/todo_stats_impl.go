@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/todo"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Статистика выполнения задач: /stats [дней] — создано и выполнено по неделям и дням,
+// медианное время выполнения, доля просроченных задач и серии дней с выполненными задачами.
+
+const statsUsage = "Использование: /stats [дней] — статистика задач за последние дни (по умолчанию 28, не больше 365)"
+
+// statsRecentDays — сколько последних дней периода выводится по отдельности.
+const statsRecentDays = 7
+
+// weekdayShort — сокращённые названия дней недели.
+var weekdayShort = [...]string{"Вс", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"}
+
+// handleStatsCommand — /stats [дней].
+func (h *Handler) handleStatsCommand(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	args := strings.Fields(strings.TrimPrefix(update.Message.Text, CmdTodoStats))
+
+	days := todo.DefaultStatsDays
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || len(args) > 1 || n < 1 || n > todo.MaxStatsDays {
+			h.Send(userID, statsUsage, TodoKeyboard())
+			return
+		}
+		days = n
+	}
+
+	loc := h.userLocation(userID)
+	stats, err := h.todo.Stats(context.Background(), userID, days, loc)
+	if err != nil {
+		h.sendTodoError(userID, "Todo stats failed", err)
+		return
+	}
+	h.Send(userID, formatStats(stats, days, loc), TodoKeyboard())
+}
+
+// formatStats — текст статистики задач.
+func formatStats(s todo.Stats, days int, loc *time.Location) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("📈 Статистика задач за %d дн. (%s — %s)\n", days,
+		s.From.In(loc).Format("02.01"), s.To.In(loc).AddDate(0, 0, -1).Format("02.01.2006")))
+	b.WriteString(fmt.Sprintf("Создано: %d, выполнено: %d\n", s.Created, s.Completed))
+	if s.Completed > 0 {
+		b.WriteString("⏳ Медиана времени выполнения: " + formatLongDuration(s.MedianCompletion) + "\n")
+	}
+	if s.Due > 0 {
+		b.WriteString(fmt.Sprintf("⚠️ Просрочено: %d из %d задач со сроком (%.0f%%)\n", s.Overdue, s.Due, s.OverdueRate()*100))
+	}
+	b.WriteString(fmt.Sprintf("🔥 Серия: %d дн. подряд, рекорд — %d\n", s.CurrentStreak, s.LongestStreak))
+
+	if len(s.Weeks) > 1 {
+		b.WriteString("\nПо неделям (создано / выполнено):\n")
+		for _, w := range s.Weeks {
+			start := w.Start
+			if start.Before(s.From) {
+				start = s.From
+			}
+			end := w.Start.AddDate(0, 0, 7)
+			if end.After(s.To) {
+				end = s.To
+			}
+			b.WriteString(fmt.Sprintf("▫️ %s–%s: %d / %d\n", start.In(loc).Format("02.01"),
+				end.AddDate(0, 0, -1).In(loc).Format("02.01"), w.Created, w.Completed))
+		}
+	}
+
+	recent := s.Days[max(0, len(s.Days)-statsRecentDays):]
+	b.WriteString("\nПо дням (создано / выполнено):\n")
+	for _, d := range recent {
+		day := d.Start.In(loc)
+		b.WriteString(fmt.Sprintf("▫️ %s %s: %d / %d\n", weekdayShort[day.Weekday()], day.Format("02.01"), d.Created, d.Completed))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatLongDuration форматирует длительность, которая может длиться днями: «2 д 3 ч», «5 ч 10 мин».
+func formatLongDuration(d time.Duration) string {
+	if d < 24*time.Hour {
+		return formatDuration(d)
+	}
+	hours := int(d.Round(time.Hour) / time.Hour)
+	return fmt.Sprintf("%d д %d ч", hours/24, hours%24)
+}
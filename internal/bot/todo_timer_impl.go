@@ -0,0 +1,200 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Учёт времени по задачам.
+// Таймер запускается и останавливается кнопками в карточке задачи (📋) и в сообщении /timer;
+// /timer report [last] — отчёт за текущую (прошлую) неделю по проектам и меткам.
+
+const timerUsage = "Учёт времени:\n" +
+	"▶️ запуск и ⏹ остановка — кнопками в карточке задачи (📋)\n" +
+	"/timer — запущенный таймер\n" +
+	"/timer report — отчёт за эту неделю, /timer report last — за прошлую"
+
+// handleTimerCommand — /timer [report [last]].
+func (h *Handler) handleTimerCommand(update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	args := strings.Fields(strings.TrimPrefix(update.Message.Text, CmdTimer))
+
+	switch {
+	case len(args) == 0:
+		text, kb, err := h.renderTimer(userID)
+		if err != nil {
+			h.sendTodoError(userID, "Timer status failed", err)
+			return
+		}
+		h.SendInline(userID, text, kb)
+	case args[0] == "report" && len(args) == 1:
+		h.showTimeReport(userID, 0)
+	case args[0] == "report" && len(args) == 2 && args[1] == "last":
+		h.showTimeReport(userID, -1)
+	default:
+		h.Send(userID, timerUsage, TodoKeyboard())
+	}
+}
+
+// renderTimer формирует сообщение о запущенном таймере с кнопкой остановки.
+func (h *Handler) renderTimer(userID int64) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	running, err := h.todo.RunningTimer(context.Background(), userID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+	if running == nil {
+		return "⏱ Таймер не запущен.\n\n" + timerUsage, tgbotapi.InlineKeyboardMarkup{}, nil
+	}
+
+	text := fmt.Sprintf("⏱ Идёт таймер: %d) %s\nЗапущен в %s, прошло %s",
+		running.TodoID, running.Title,
+		running.StartedAt.In(h.userLocation(userID)).Format("15:04"),
+		formatDuration(running.Duration(time.Now())))
+	kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		callbackButton("⏹ Остановить", cbTimer, actStop),
+		callbackButton("📋 Задача", cbTodo, actOpen, running.TodoID),
+	))
+	return text, kb, nil
+}
+
+// timerCardRow возвращает строку карточки о затраченном времени и кнопку запуска или остановки таймера задачи.
+func (h *Handler) timerCardRow(userID int64, todoID int) (string, []tgbotapi.InlineKeyboardButton) {
+	ctx := context.Background()
+	running, err := h.todo.RunningTimer(ctx, userID)
+	if err != nil {
+		logger.Error("Timer status error: " + err.Error())
+		return "", nil
+	}
+	tracked, err := h.todo.TrackedTime(ctx, userID, todoID)
+	if err != nil {
+		logger.Error("Timer tracked time error: " + err.Error())
+		return "", nil
+	}
+
+	line := ""
+	if tracked > 0 {
+		line = "⏱ Затрачено: " + formatDuration(tracked)
+	}
+	if running != nil && running.TodoID == todoID {
+		line += " (таймер идёт)"
+		return strings.TrimSpace(line), tgbotapi.NewInlineKeyboardRow(callbackButton("⏹ Остановить таймер", cbTimer, actStop, todoID))
+	}
+	return line, tgbotapi.NewInlineKeyboardRow(callbackButton("▶️ Запустить таймер", cbTimer, actStart, todoID))
+}
+
+// registerTimerCallbacks регистрирует кнопки таймера.
+// Кнопка с ID задачи находится в карточке задачи, и карточка перерисовывается; без ID — в сообщении /timer.
+func (h *Handler) registerTimerCallbacks(r *callbackRouter) {
+	r.Handle(cbTimer, actStart, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		_, stopped, err := h.todo.StartTimer(context.Background(), cb.From.ID, data.Arg(0))
+		okText := "▶️ Таймер запущен"
+		if stopped != nil {
+			okText += fmt.Sprintf(", остановлен «%s» (%s)", truncate(stopped.Title, 24), formatDuration(stopped.Duration(time.Now())))
+		}
+		h.afterTimerCallback(cb, data.Arg(0), okText, err)
+	})
+	r.Handle(cbTimer, actStop, func(cb *tgbotapi.CallbackQuery, data callbackData) {
+		stopped, err := h.todo.StopTimer(context.Background(), cb.From.ID)
+		okText := ""
+		if stopped != nil {
+			okText = "⏹ Остановлен, " + formatDuration(stopped.Duration(time.Now()))
+		}
+		h.afterTimerCallback(cb, data.Arg(0), okText, err)
+	})
+}
+
+// afterTimerCallback отвечает на нажатие кнопки таймера и перерисовывает сообщение с кнопкой.
+func (h *Handler) afterTimerCallback(cb *tgbotapi.CallbackQuery, todoID int, okText string, err error) {
+	switch {
+	case errors.Is(err, todo.ErrNoTimer):
+		h.answerCallback(cb, "Таймер не запущен")
+	case errors.Is(err, todo.ErrTimerRunning):
+		h.answerCallback(cb, "Таймер уже запущен, попробуйте ещё раз")
+	case errors.Is(err, todo.ErrNotFound):
+		h.answerCallback(cb, "Задача не найдена")
+		return
+	case err != nil:
+		logger.Error("Timer callback failed: " + err.Error())
+		h.answerCallback(cb, "Ошибка, попробуйте позже")
+		return
+	default:
+		h.answerCallback(cb, okText)
+	}
+
+	var (
+		text string
+		kb   tgbotapi.InlineKeyboardMarkup
+	)
+	if todoID != 0 {
+		text, kb, err = h.renderTodoCard(cb.From.ID, todoID)
+	} else {
+		text, kb, err = h.renderTimer(cb.From.ID)
+	}
+	if err != nil {
+		logger.Error("Timer render error: " + err.Error())
+		return
+	}
+	h.Edit(cb.Message.Chat.ID, cb.Message.MessageID, text, kb)
+}
+
+// showTimeReport отправляет недельный отчёт о затраченном времени; offset -1 — прошлая неделя.
+func (h *Handler) showTimeReport(userID int64, offset int) {
+	loc := h.userLocation(userID)
+	from, to := todo.WeekRange(time.Now(), loc, offset)
+
+	report, err := h.todo.TimeReport(context.Background(), userID, from, to)
+	if err != nil {
+		h.sendTodoError(userID, "Time report failed", err)
+		return
+	}
+	h.Send(userID, formatTimeReport(report, loc), TodoKeyboard())
+}
+
+// formatTimeReport — текст отчёта о затраченном времени.
+func formatTimeReport(r todo.TimeReport, loc *time.Location) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("⏱ Время за %s — %s\n", r.From.In(loc).Format("02.01"), r.To.In(loc).AddDate(0, 0, -1).Format("02.01.2006")))
+	if r.Total == 0 {
+		b.WriteString("\nЗа этот период время не учтено.")
+		return b.String()
+	}
+	b.WriteString("Всего: " + formatDuration(r.Total) + "\n")
+
+	b.WriteString("\n📁 По проектам:\n")
+	for _, g := range r.ByProject {
+		b.WriteString(fmt.Sprintf("▫️ %s — %s\n", g.Name, formatDuration(g.Duration)))
+	}
+	b.WriteString("\n🏷 По меткам:\n")
+	for _, g := range r.ByTag {
+		name := g.Name
+		if name != todo.NoTag {
+			name = "#" + name
+		}
+		b.WriteString(fmt.Sprintf("▫️ %s — %s\n", name, formatDuration(g.Duration)))
+	}
+	b.WriteString("\n📝 По задачам:\n")
+	for _, row := range r.Todos {
+		b.WriteString(fmt.Sprintf("▫️ %d) %s — %s\n", row.TodoID, row.Title, formatDuration(row.Duration)))
+	}
+	return b.String()
+}
+
+// formatDuration форматирует длительность с точностью до минуты: «2 ч 05 мин», «15 мин».
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes < 60 {
+		return fmt.Sprintf("%d мин", minutes)
+	}
+	return fmt.Sprintf("%d ч %02d мин", minutes/60, minutes%60)
+}
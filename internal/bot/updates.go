@@ -8,9 +8,14 @@ import (
 	"runtime"
 	"time"
 
+	"tg_bot_asist/internal/attachment"
+	"tg_bot_asist/internal/calendar"
+	"tg_bot_asist/internal/config"
 	"tg_bot_asist/internal/credits"
+	"tg_bot_asist/internal/digest"
 	"tg_bot_asist/internal/finance"
 	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/search"
 	"tg_bot_asist/internal/storage"
 	"tg_bot_asist/internal/todo"
 
@@ -24,15 +29,24 @@ import (
 func HandleUpdates(
 	bot *tgbotapi.BotAPI,
 	updates tgbotapi.UpdatesChannel,
-	userRepo *storage.UserRepo,
-	todoService *todo.Service,
-	stateRepo *storage.StateRepo,
-	creditService *credits.Service,
-	financeService *finance.Service,
-	recurringRepo *finance.RecurringRepo,
+	deps Deps,
 ) {
 	ctx := context.Background()
-	HandleUpdatesWithContext(ctx, bot, updates, userRepo, todoService, stateRepo, creditService, financeService, recurringRepo)
+	HandleUpdatesWithContext(ctx, bot, updates, deps)
+}
+
+// Deps — сервисы и репозитории, которые использует бот.
+type Deps struct {
+	Users       *storage.UserRepo
+	States      *storage.StateRepo
+	Todo        *todo.Service
+	Finance     *finance.Service
+	Recurring   *finance.RecurringRepo
+	Credits     *credits.Service
+	Calendar    *calendar.Service
+	Digest      *digest.Service
+	Search      *search.Service
+	Attachments *attachment.Service
 }
 
 // HandleUpdatesWithContext обрабатывает входящие обновления от Telegram API с поддержкой контекста.
@@ -42,19 +56,15 @@ func HandleUpdatesWithContext(
 	ctx context.Context,
 	bot *tgbotapi.BotAPI,
 	updates tgbotapi.UpdatesChannel,
-	userRepo *storage.UserRepo,
-	todoService *todo.Service,
-	stateRepo *storage.StateRepo,
-	creditService *credits.Service,
-	financeService *finance.Service,
-	recurringRepo *finance.RecurringRepo,
+	deps Deps,
 ) {
 	// Настройка Menu Button для WebApp (если нужно, настройте через BotFather или используйте команду)
 	// Для настройки через код нужна поддержка в библиотеке telegram-bot-api
 	// Пока настраивается вручную через BotFather: /mybots → Bot Settings → Menu Button
-	// Создаём простой FSM (in-memory) и Handler
-	fsm := NewFSM()
-	handler := NewHandler(bot, fsm, todoService, financeService, creditService)
+	// Создаём FSM с хранением состояний в Postgres и Handler
+	fsm := NewFSM(deps.States, stateTTL())
+	go runStateCleanup(ctx, fsm)
+	handler := NewHandler(bot, fsm, deps)
 
 	// Основной цикл обработки обновлений
 	for {
@@ -88,7 +98,7 @@ func HandleUpdatesWithContext(
 			go func(update tgbotapi.Update) {
 				defer func() {
 					if r := recover(); r != nil {
-						logger.Error("Panic in update handler: " + string(r.(string)))
+						logger.Error(fmt.Sprintf("Panic in update handler: %v", r))
 					}
 				}()
 
@@ -102,7 +112,7 @@ func HandleUpdatesWithContext(
 					if u.Message.Text != "" {
 						lastMessage = u.Message.Text
 					}
-					if err := userRepo.RegisterUser(userCtx, u.Message.From.ID, chatID, lastMessage); err != nil {
+					if err := deps.Users.RegisterUser(userCtx, u.Message.From.ID, chatID, lastMessage); err != nil {
 						logger.Error("Failed to register user: " + err.Error())
 					}
 				}
@@ -114,6 +124,34 @@ func HandleUpdatesWithContext(
 	}
 }
 
+// stateTTL возвращает время жизни незавершённого диалога из FSM_STATE_TTL (по умолчанию 24 часа).
+func stateTTL() time.Duration {
+	raw := config.GetWithDefault("FSM_STATE_TTL", "24h")
+	ttl, err := time.ParseDuration(raw)
+	if err != nil {
+		logger.Warn("Invalid FSM_STATE_TTL " + raw + ", using 24h")
+		return 24 * time.Hour
+	}
+	return ttl
+}
+
+// runStateCleanup периодически удаляет брошенные диалоги, пока не отменён контекст.
+func runStateCleanup(ctx context.Context, fsm *FSM) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	fsm.CleanupExpired(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fsm.CleanupExpired(ctx)
+		}
+	}
+}
+
 // killConflictingProcesses останавливает возможные конфликтующие процессы бота и зомби-процессы.
 // Вызывается при обнаружении конфликта getUpdates.
 // ВАЖНО: Не останавливает текущий процесс (использует PID для исключения).
@@ -0,0 +1,283 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tg_bot_asist/internal/attachment"
+	"tg_bot_asist/internal/logger"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Пошаговые диалоги (мастера) описываются декларативно: список шагов с подсказкой,
+// разбором ввода, вариантами ответа и условием показа. Движок хранит прогресс в FSM
+// (служебные ключи с префиксом "_" в State.Data), умеет возвращаться
+// на шаг назад и показывает экран подтверждения перед сохранением.
+
+// Служебные ключи мастера в State.Data.
+const (
+	wizardStepKey    = "_step"    // индекс текущего шага; len(Steps) — экран подтверждения
+	wizardHistoryKey = "_history" // индексы пройденных шагов для возврата назад
+	wizardUserKey    = "_user"    // ID пользователя, проходящего мастер
+	wizardFilesKey   = "_files"   // файлы, присланные во время мастера (см. attachment_impl.go)
+	wizardCreatedKey = "_created" // ID созданной записи; Submit записывает его, чтобы прикрепить файлы
+)
+
+// wizardStep — один шаг мастера.
+type wizardStep struct {
+	// Key — ключ, под которым результат Parse сохраняется в State.Data.
+	Key string
+	// Label — название поля на экране подтверждения; пустое — поле не показывается.
+	Label string
+	// Prompt — текст вопроса.
+	Prompt func(data map[string]any) string
+	// Parse разбирает ввод пользователя. Ошибка показывается пользователю как есть,
+	// после чего шаг повторяется. Parse может дополнительно записать связанные значения в data.
+	Parse func(text string, data map[string]any) (any, error)
+	// Options — варианты ответа для клавиатуры (необязательно).
+	Options func(data map[string]any) []string
+	// When — условие, при котором шаг показывается (необязательно).
+	When func(data map[string]any) bool
+	// Format — представление значения на экране подтверждения (по умолчанию fmt.Sprint).
+	Format func(v any, data map[string]any) string
+}
+
+// wizard — описание пошагового диалога.
+type wizard struct {
+	// Name — имя состояния FSM.
+	Name string
+	// Steps — шаги в порядке прохождения.
+	Steps []wizardStep
+	// Submit сохраняет результат и возвращает текст для пользователя.
+	Submit func(userID int64, data map[string]any) (string, error)
+	// Keyboard — клавиатура модуля, показываемая после завершения или отмены.
+	Keyboard func() tgbotapi.ReplyKeyboardMarkup
+	// Attach — тип записи, к которой прикрепляются файлы, присланные во время мастера;
+	// пустой — мастер файлы не принимает.
+	Attach attachment.Owner
+}
+
+// registerWizard добавляет мастер в обработчик; сообщения в его состоянии будут направляться в handleWizard.
+func (h *Handler) registerWizard(w *wizard) {
+	h.wizards[w.Name] = w
+}
+
+// startWizard запускает мастер с начальными данными и задаёт первый вопрос.
+func (h *Handler) startWizard(userID int64, w *wizard, initial map[string]any) {
+	data := map[string]any{}
+	for k, v := range initial {
+		data[k] = v
+	}
+	data[wizardHistoryKey] = []int{}
+	data[wizardUserKey] = userID
+
+	h.gotoStep(userID, w, data, w.nextStep(-1, data))
+}
+
+// handleWizard обрабатывает ответ пользователя на текущем шаге мастера.
+func (h *Handler) handleWizard(w *wizard, update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	text := strings.TrimSpace(update.Message.Text)
+
+	state := h.fsm.Get(userID)
+	if state == nil || state.Name != w.Name {
+		return
+	}
+	data := state.Data
+	if data == nil {
+		data = map[string]any{}
+	}
+	step, _ := data[wizardStepKey].(int)
+
+	if text == CmdStepBack {
+		h.wizardBack(userID, w, data)
+		return
+	}
+
+	// экран подтверждения
+	if step >= len(w.Steps) {
+		if text != CmdConfirm {
+			h.Send(userID, w.summary(data)+"\n\nНажмите «"+CmdConfirm+"» или вернитесь на шаг назад.", confirmKeyboard())
+			return
+		}
+		h.fsm.Clear(userID)
+		h.submitWizard(userID, w, data)
+		return
+	}
+
+	s := w.Steps[step]
+	value, err := s.Parse(text, data)
+	if err != nil {
+		h.Send(userID, err.Error(), stepKeyboard(s, data))
+		return
+	}
+	data[s.Key] = value
+
+	history, _ := data[wizardHistoryKey].([]int)
+	data[wizardHistoryKey] = append(history, step)
+
+	h.gotoStep(userID, w, data, w.nextStep(step, data))
+}
+
+// wizardBack возвращает пользователя на предыдущий пройденный шаг.
+// На первом шаге мастер отменяется.
+func (h *Handler) wizardBack(userID int64, w *wizard, data map[string]any) {
+	history, _ := data[wizardHistoryKey].([]int)
+	if len(history) == 0 {
+		h.fsm.Clear(userID)
+		h.Send(userID, "Отменено", w.Keyboard())
+		return
+	}
+
+	prev := history[len(history)-1]
+	data[wizardHistoryKey] = history[:len(history)-1]
+	h.gotoStep(userID, w, data, prev)
+}
+
+// gotoStep сохраняет прогресс и показывает вопрос шага (или экран подтверждения).
+func (h *Handler) gotoStep(userID int64, w *wizard, data map[string]any, step int) {
+	data[wizardStepKey] = step
+	if !h.setState(userID, w.Name, data) {
+		return
+	}
+
+	if step >= len(w.Steps) {
+		h.Send(userID, w.summary(data), confirmKeyboard())
+		return
+	}
+
+	s := w.Steps[step]
+	h.Send(userID, s.Prompt(data), stepKeyboard(s, data))
+}
+
+func (h *Handler) submitWizard(userID int64, w *wizard, data map[string]any) {
+	msg, err := w.Submit(userID, data)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Wizard %s submit failed: %s", w.Name, err.Error()))
+		h.Send(userID, "Ошибка при сохранении. Попробуйте позже.", w.Keyboard())
+		return
+	}
+	if id, ok := data[wizardCreatedKey].(int); ok && w.Attach != "" {
+		msg += h.attachWizardFiles(userID, w.Attach, id, data)
+	}
+	h.Send(userID, msg, w.Keyboard())
+}
+
+// nextStep возвращает индекс следующего шага после from, для которого выполнено условие When.
+func (w *wizard) nextStep(from int, data map[string]any) int {
+	for i := from + 1; i < len(w.Steps); i++ {
+		if w.Steps[i].When == nil || w.Steps[i].When(data) {
+			return i
+		}
+	}
+	return len(w.Steps)
+}
+
+// summary формирует экран подтверждения по заполненным шагам.
+func (w *wizard) summary(data map[string]any) string {
+	var b strings.Builder
+	b.WriteString("Проверьте данные:\n\n")
+	for _, s := range w.Steps {
+		if s.Label == "" || (s.When != nil && !s.When(data)) {
+			continue
+		}
+		v, ok := data[s.Key]
+		if !ok {
+			continue
+		}
+		text := fmt.Sprint(v)
+		if s.Format != nil {
+			text = s.Format(v, data)
+		}
+		b.WriteString(s.Label + ": " + text + "\n")
+	}
+	if files, _ := data[wizardFilesKey].([]string); len(files) > 0 {
+		b.WriteString(fmt.Sprintf("Файлы: %d\n", len(files)))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// wizardUser возвращает ID пользователя, проходящего мастер (для Parse и Options).
+func wizardUser(data map[string]any) int64 {
+	id, _ := data[wizardUserKey].(int64)
+	return id
+}
+
+// parseRequired — Parse для непустой строки.
+func parseRequired(errText string) func(string, map[string]any) (any, error) {
+	return func(text string, _ map[string]any) (any, error) {
+		if text == "" {
+			return nil, errors.New(errText)
+		}
+		return text, nil
+	}
+}
+
+// parsePositiveFloat — Parse для положительного числа; допускается запятая как разделитель.
+func parsePositiveFloat(errText string) func(string, map[string]any) (any, error) {
+	return func(text string, _ map[string]any) (any, error) {
+		v, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64)
+		if err != nil || v <= 0 {
+			return nil, errors.New(errText)
+		}
+		return v, nil
+	}
+}
+
+// formatRubles — Format для денежных сумм.
+func formatRubles(v any, _ map[string]any) string {
+	return fmt.Sprintf("%.2f ₽", v)
+}
+
+// stepKeyboard — варианты ответа шага плюс навигация мастера.
+func stepKeyboard(s wizardStep, data map[string]any) tgbotapi.ReplyKeyboardMarkup {
+	var rows [][]tgbotapi.KeyboardButton
+	if s.Options != nil {
+		var row []tgbotapi.KeyboardButton
+		for _, opt := range s.Options(data) {
+			row = append(row, tgbotapi.NewKeyboardButton(opt))
+			if len(row) == 2 {
+				rows = append(rows, row)
+				row = nil
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	rows = append(rows,
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(CmdStepBack)),
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(CmdBack), tgbotapi.NewKeyboardButton(CmdHome)),
+	)
+	return tgbotapi.NewReplyKeyboard(rows...)
+}
+
+// confirmKeyboard — клавиатура экрана подтверждения мастера.
+func confirmKeyboard() tgbotapi.ReplyKeyboardMarkup {
+	return tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(CmdConfirm),
+			tgbotapi.NewKeyboardButton(CmdStepBack),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(CmdBack),
+			tgbotapi.NewKeyboardButton(CmdHome),
+		),
+	)
+}
+
+// staticPrompt — Prompt с неизменным текстом.
+func staticPrompt(text string) func(map[string]any) string {
+	return func(map[string]any) string { return text }
+}
+
+// staticOptions — Options с неизменным набором вариантов.
+func staticOptions(opts ...string) func(map[string]any) []string {
+	return func(map[string]any) []string { return opts }
+}
@@ -0,0 +1,93 @@
+package calendar
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event — событие календаря (VEVENT).
+type Event struct {
+	UID         string // стабильный идентификатор: клиент обновляет событие, а не дублирует его
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time // для AllDay не используется
+	AllDay      bool
+}
+
+// prodID — идентификатор приложения в заголовке календаря.
+const prodID = "-//tg_bot_asist//Calendar//RU"
+
+// WriteICS записывает события в формате iCalendar (RFC 5545).
+func WriteICS(w io.Writer, name string, events []Event, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	stamp := now.UTC().Format("20060102T150405Z")
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+prodID)
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	writeLine(bw, "METHOD:PUBLISH")
+	writeLine(bw, "X-WR-CALNAME:"+escapeText(name))
+
+	for _, e := range events {
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+e.UID)
+		writeLine(bw, "DTSTAMP:"+stamp)
+		if e.AllDay {
+			day := time.Date(e.Start.Year(), e.Start.Month(), e.Start.Day(), 0, 0, 0, 0, time.UTC)
+			writeLine(bw, "DTSTART;VALUE=DATE:"+day.Format("20060102"))
+			writeLine(bw, "DTEND;VALUE=DATE:"+day.AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			end := e.End
+			if !end.After(e.Start) {
+				end = e.Start.Add(30 * time.Minute)
+			}
+			writeLine(bw, "DTSTART:"+e.Start.UTC().Format("20060102T150405Z"))
+			writeLine(bw, "DTEND:"+end.UTC().Format("20060102T150405Z"))
+		}
+		writeLine(bw, "SUMMARY:"+escapeText(e.Summary))
+		if e.Description != "" {
+			writeLine(bw, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		writeLine(bw, "END:VEVENT")
+	}
+
+	writeLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+// escapeText экранирует спецсимволы значения TEXT.
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// writeLine записывает строку с переносом по 75 октетов (не разрывая UTF-8 символы) и CRLF.
+func writeLine(w *bufio.Writer, line string) {
+	const limit = 75
+	width := limit
+	for len(line) > width {
+		cut := width
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		width = limit - 1 // строка продолжения начинается с пробела
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
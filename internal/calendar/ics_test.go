@@ -0,0 +1,168 @@
+package calendar
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"tg_bot_asist/internal/finance"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "Обед", want: "Обед"},
+		{in: "молоко, хлеб; сыр", want: `молоко\, хлеб\; сыр`},
+		{in: `C:\temp`, want: `C:\\temp`},
+		{in: "строка 1\nстрока 2\r\nстрока 3", want: `строка 1\nстрока 2\nстрока 3`},
+		{in: `\,`, want: `\\\,`},
+	}
+	for _, tt := range tests {
+		if got := escapeText(tt.in); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteLineFolding(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "короткая", line: "SUMMARY:Обед"},
+		{name: "ровно 75 октетов", line: "SUMMARY:" + strings.Repeat("a", 67)},
+		{name: "латиница", line: "DESCRIPTION:" + strings.Repeat("abcdefghij", 20)},
+		{name: "кириллица", line: "SUMMARY:" + strings.Repeat("Платёж по ипотеке ", 10)},
+		{name: "кириллица со смещением на байт", line: "SUMMARY:x" + strings.Repeat("ж", 100)},
+		{name: "эмодзи", line: "SUMMARY:" + strings.Repeat("🔁", 40)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			bw := bufio.NewWriter(&buf)
+			writeLine(bw, tt.line)
+			bw.Flush()
+
+			out := buf.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("line does not end with CRLF: %q", out)
+			}
+			physical := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, l := range physical {
+				if len(l) > 75 {
+					t.Errorf("line %d is %d octets, want <= 75", i, len(l))
+				}
+				if !utf8.ValidString(l) {
+					t.Errorf("line %d splits a UTF-8 character: %q", i, l)
+				}
+				if i > 0 && !strings.HasPrefix(l, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, l)
+				}
+			}
+			if len(tt.line) <= 75 && len(physical) != 1 {
+				t.Errorf("short line folded into %d lines", len(physical))
+			}
+
+			// снятие переноса (RFC 5545, 3.1) восстанавливает исходную строку
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("unfolded = %q, want %q", unfolded, tt.line)
+			}
+		})
+	}
+}
+
+func TestWriteICSGolden(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	now := time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		{
+			UID:         "todo-42@tg-bot-asist",
+			Summary:     "📝 Позвонить в банк, уточнить ставку; взять справку",
+			Description: "Номер: 8-800\nКабинет 5\\6",
+			Start:       time.Date(2026, time.October, 20, 18, 0, 0, 0, msk),
+		},
+		{
+			UID:     "recurring-7-20261031@tg-bot-asist",
+			Summary: "🔁 Аренда квартиры на Профсоюзной улице — ежемесячный платёж 45000.00 ₽",
+			Start:   time.Date(2026, time.October, 31, 0, 0, 0, 0, msk),
+			AllDay:  true,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, "Мои дела, финансы", events, now); err != nil {
+		t.Fatalf("WriteICS: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//tg_bot_asist//Calendar//RU",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		`X-WR-CALNAME:Мои дела\, финансы`,
+		"BEGIN:VEVENT",
+		"UID:todo-42@tg-bot-asist",
+		"DTSTAMP:20261017T120000Z",
+		"DTSTART:20261020T150000Z",
+		"DTEND:20261020T153000Z",
+		`SUMMARY:📝 Позвонить в банк\, уточнить ставку`,
+		` \; взять справку`,
+		`DESCRIPTION:Номер: 8-800\nКабинет 5\\6`,
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:recurring-7-20261031@tg-bot-asist",
+		"DTSTAMP:20261017T120000Z",
+		"DTSTART;VALUE=DATE:20261031",
+		"DTEND;VALUE=DATE:20261101",
+		"SUMMARY:🔁 Аренда квартиры на Профсоюзной ул",
+		" ице — ежемесячный платёж 45000.00 ₽",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	if got := buf.String(); got != want {
+		t.Errorf("WriteICS output mismatch\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestRecurringEventsStableUIDs(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+	p := &finance.RecurringPayment{ID: 7, Title: "Аренда", Amount: 45000, Period: "monthly", NextPayment: day(time.January, 31), PayDay: 31}
+
+	before := recurringEvents(p, day(time.January, 20))
+
+	// после списания следующий платёж сдвигается, события остальных дат сохраняют UID
+	paid := *p
+	paid.NextPayment = finance.NextRecurringDate(p)
+	after := recurringEvents(&paid, day(time.February, 5))
+
+	uids := make(map[string]time.Time, len(before))
+	for _, e := range before {
+		uids[e.UID] = e.Start
+	}
+	if len(after) == 0 {
+		t.Fatal("no events after payment")
+	}
+	for _, e := range after {
+		start, ok := uids[e.UID]
+		if !ok {
+			if e.Start.Before(day(time.December, 1)) {
+				t.Errorf("event %s on %v has a new UID", e.UID, e.Start)
+			}
+			continue
+		}
+		if !start.Equal(e.Start) {
+			t.Errorf("UID %s moved from %v to %v", e.UID, start, e.Start)
+		}
+	}
+
+	if before[1].UID != "recurring-7-20260228@tg-bot-asist" {
+		t.Errorf("February UID = %s, want payment clamped to 28th", before[1].UID)
+	}
+}
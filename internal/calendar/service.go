@@ -0,0 +1,168 @@
+// Package calendar собирает сроки задач, регулярных платежей и платежей по кредитам
+// в календарь iCalendar для подписки из календарных приложений.
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/credits"
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/todo"
+)
+
+// ErrInvalidToken возвращается для неизвестного токена подписки.
+var ErrInvalidToken = errors.New("неверный токен календаря")
+
+// TokenRepository хранит токены подписки на календарь.
+type TokenRepository interface {
+	// GetToken возвращает токен пользователя или "" если его нет.
+	GetToken(ctx context.Context, userID int64) (string, error)
+	// SetToken сохраняет (заменяет) токен пользователя.
+	SetToken(ctx context.Context, userID int64, token string) error
+	// UserByToken возвращает владельца токена или ErrInvalidToken.
+	UserByToken(ctx context.Context, token string) (int64, error)
+}
+
+// uidDomain — правая часть UID событий.
+const uidDomain = "tg-bot-asist"
+
+// recurringHorizon — на сколько вперёд разворачиваются регулярные платежи.
+const recurringHorizon = 365 * 24 * time.Hour
+
+// Service формирует календарь пользователя.
+type Service struct {
+	todos   *todo.Service
+	finance *finance.Service
+	credits *credits.Service
+	tokens  TokenRepository
+}
+
+func NewService(todos *todo.Service, fin *finance.Service, cred *credits.Service, tokens TokenRepository) *Service {
+	return &Service{todos: todos, finance: fin, credits: cred, tokens: tokens}
+}
+
+// Token возвращает токен подписки пользователя, создавая его при первом обращении.
+func (s *Service) Token(ctx context.Context, userID int64) (string, error) {
+	token, err := s.tokens.GetToken(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		return token, nil
+	}
+	return s.RotateToken(ctx, userID)
+}
+
+// RotateToken выдаёт новый токен; старая ссылка подписки перестаёт работать.
+func (s *Service) RotateToken(ctx context.Context, userID int64) (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	if err := s.tokens.SetToken(ctx, userID, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// UserByToken возвращает пользователя по токену подписки.
+func (s *Service) UserByToken(ctx context.Context, token string) (int64, error) {
+	if token == "" {
+		return 0, ErrInvalidToken
+	}
+	return s.tokens.UserByToken(ctx, token)
+}
+
+// Events собирает события календаря пользователя:
+// сроки незавершённых задач, ближайшие регулярные платежи и платежи по кредитам.
+func (s *Service) Events(ctx context.Context, userID int64, now time.Time) ([]Event, error) {
+	var events []Event
+
+	items, err := s.todos.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("todos: %w", err)
+	}
+	for _, it := range items {
+		if it.DueDate == nil || it.Status == todo.StatusCompleted {
+			continue
+		}
+		events = append(events, Event{
+			UID:         fmt.Sprintf("todo-%d@%s", it.ID, uidDomain),
+			Summary:     "📝 " + it.Title,
+			Description: it.Description,
+			Start:       *it.DueDate,
+		})
+	}
+
+	payments, err := s.finance.GetRecurringList(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("recurring: %w", err)
+	}
+	for _, p := range payments {
+		events = append(events, recurringEvents(p, now)...)
+	}
+
+	list, err := s.credits.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("credits: %w", err)
+	}
+	for _, c := range list {
+		events = append(events, creditEvents(c)...)
+	}
+
+	return events, nil
+}
+
+// recurringEvents разворачивает регулярный платёж на recurringHorizon вперёд по тем же датам,
+// по которым его списывает планировщик.
+// UID включает дату платежа: после списания событие остаётся, а следующее добавляется.
+func recurringEvents(p *finance.RecurringPayment, now time.Time) []Event {
+	var events []Event
+	until := now.Add(recurringHorizon)
+	next := *p
+	for !next.NextPayment.After(until) {
+		date := next.NextPayment
+		events = append(events, Event{
+			UID:     fmt.Sprintf("recurring-%d-%s@%s", p.ID, date.Format("20060102"), uidDomain),
+			Summary: fmt.Sprintf("🔁 %s — %.2f ₽", p.Title, p.Amount),
+			Start:   date,
+			AllDay:  true,
+		})
+		next.NextPayment = finance.NextRecurringDate(&next)
+	}
+	return events
+}
+
+// creditEvents — платежи по аннуитетному графику кредита.
+// UID включает номер платежа, поэтому изменение даты обновляет существующее событие.
+func creditEvents(c credits.Credit) []Event {
+	schedule := credits.Schedule(c)
+
+	events := make([]Event, 0, len(schedule))
+	for i, p := range schedule {
+		events = append(events, Event{
+			UID:         fmt.Sprintf("credit-%d-%d@%s", c.ID, i+1, uidDomain),
+			Summary:     fmt.Sprintf("🏦 %s — %.2f ₽", c.Title, p.Total),
+			Description: fmt.Sprintf("Платёж %d из %d\nОсновной долг: %.2f ₽\nПроценты: %.2f ₽", i+1, len(schedule), p.Principal, p.Interest),
+			Start:       p.DueDate,
+			AllDay:      true,
+		})
+	}
+	return events
+}
+
+// FeedPath — путь подписки на календарь в API.
+const FeedPath = "/api/calendar.ics"
+
+// FeedURL возвращает ссылку подписки; если baseURL пуст — только путь.
+func FeedURL(baseURL, token string) string {
+	return strings.TrimRight(baseURL, "/") + FeedPath + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"time"
+	_ "time/tzdata" // база часовых поясов на случай минимального образа без /usr/share/zoneinfo
+
+	"tg_bot_asist/internal/logger"
+)
+
+// DefaultLocation возвращает часовой пояс по умолчанию из DEFAULT_TIMEZONE (Europe/Moscow, если не задан).
+func DefaultLocation() *time.Location {
+	name := GetWithDefault("DEFAULT_TIMEZONE", "Europe/Moscow")
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		logger.Warn("Invalid DEFAULT_TIMEZONE " + name + ", using UTC")
+		return time.UTC
+	}
+	return loc
+}
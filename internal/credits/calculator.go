@@ -111,3 +111,20 @@ func normaliseDate(base time.Time, day int) time.Time {
 	}
 	return time.Date(year, month, day, 0, 0, 0, 0, base.Location())
 }
+
+// Schedule возвращает аннуитетный график кредита: первый платёж через месяц
+// после оформления, далее в тот же день месяца.
+func Schedule(c Credit) []Payment {
+	_, schedule := CalcAnnuitySchedule(c.Principal, c.Rate, c.Months, c.CreatedAt.AddDate(0, 1, 0), c.CreatedAt.Day())
+	return schedule
+}
+
+// NextPayment возвращает первый платёж графика с датой не раньше from.
+func NextPayment(c Credit, from time.Time) (Payment, int, bool) {
+	for i, p := range Schedule(c) {
+		if !p.DueDate.Before(from) {
+			return p, i + 1, true
+		}
+	}
+	return Payment{}, 0, false
+}
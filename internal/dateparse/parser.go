@@ -0,0 +1,453 @@
+// Package dateparse извлекает даты и время из свободного русского текста:
+// "завтра в 18:00 позвонить маме", "в пятницу сдать отчёт", "через 3 дня".
+package dateparse
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHour — час, который подставляется, если в тексте указана только дата.
+const DefaultHour = 9
+
+// Result — результат разбора даты из текста.
+type Result struct {
+	Time    time.Time // найденная дата/время в часовом поясе now
+	HasTime bool      // время указано явно (а не подставлено DefaultHour)
+	Rest    string    // исходный текст без фразы с датой
+}
+
+// Parse ищет в тексте дату и время относительно now (в часовом поясе now).
+// Возвращает false, если дата в тексте не найдена.
+func Parse(text string, now time.Time) (Result, bool) {
+	words := strings.Fields(text)
+	p := &parser{
+		now:      now,
+		words:    words,
+		norm:     make([]string, len(words)),
+		consumed: make([]bool, len(words)),
+	}
+	for i, w := range words {
+		p.norm[i] = normalize(w)
+	}
+
+	p.run()
+
+	if !p.hasDate && !p.hasTime && !p.hasExact {
+		return Result{Rest: strings.TrimSpace(text)}, false
+	}
+
+	return Result{
+		Time:    p.result(),
+		HasTime: p.hasTime || p.hasExact,
+		Rest:    p.rest(),
+	}, true
+}
+
+type parser struct {
+	now      time.Time
+	words    []string
+	norm     []string
+	consumed []bool
+
+	// дата без времени (год, месяц, день)
+	hasDate bool
+	date    time.Time
+
+	// явно указанное время
+	hasTime bool
+	hour    int
+	minute  int
+
+	// точный момент ("через 2 часа", "через 15 минут")
+	hasExact bool
+	exact    time.Time
+}
+
+func (p *parser) run() {
+	for i := 0; i < len(p.norm); i++ {
+		if p.consumed[i] {
+			continue
+		}
+		w := p.norm[i]
+
+		switch {
+		case w == "сегодня" || w == "завтра" || w == "послезавтра":
+			p.setDate(p.now.AddDate(0, 0, map[string]int{"сегодня": 0, "завтра": 1, "послезавтра": 2}[w]))
+			p.consume(i, 1)
+			p.consumePreposition(i, "на", "до", "к")
+
+		case w == "через":
+			if n := p.parseRelative(i + 1); n > 0 {
+				p.consume(i, 1+n)
+			}
+
+		case isPreposition(w):
+			if n := p.parseAfterPreposition(i + 1); n > 0 {
+				p.consume(i, 1+n)
+			}
+
+		default:
+			if n := p.parseDateOrTime(i); n > 0 {
+				p.consume(i, n)
+			} else if h, ok := partOfDayWords[w]; ok && !p.hasTime {
+				p.setTime(h, 0)
+				p.consume(i, 1)
+			}
+		}
+	}
+}
+
+// parseAfterPreposition разбирает фразы вида "в пятницу", "в 18:00", "к 12.03", "до 5 марта".
+func (p *parser) parseAfterPreposition(i int) int {
+	if i >= len(p.norm) {
+		return 0
+	}
+
+	// "в следующую пятницу", "в следующий вторник"
+	if strings.HasPrefix(p.norm[i], "следующ") && i+1 < len(p.norm) {
+		if wd, ok := weekdayWords[p.norm[i+1]]; ok {
+			p.setDate(nextWeekday(p.now, wd))
+			return 2
+		}
+	}
+
+	if wd, ok := weekdayWords[p.norm[i]]; ok {
+		p.setDate(nextWeekday(p.now, wd))
+		return 1
+	}
+
+	// "в 9 утра" / "в 7 вечера" / "в 6 часов" / "в 18" в конце фразы.
+	// Число перед другим словом — не время: "позвонить в 2 офиса".
+	if h, err := strconv.Atoi(p.norm[i]); err == nil && h >= 0 && h <= 23 && p.norm[i-1] != "до" && p.norm[i-1] != "к" {
+		switch {
+		case p.isPhraseEnd(i):
+			p.setTime(h, 0)
+			return 1
+		case hourWords[p.norm[i+1]]:
+			p.setTime(h, 0)
+			return 2
+		}
+		if adjusted, ok := applyDayPeriod(h, p.norm[i+1]); ok {
+			p.setTime(adjusted, 0)
+			return 2
+		}
+	}
+
+	return p.parseDateOrTime(i)
+}
+
+// parseDateOrTime разбирает "18:00", "12.03", "12.03.2026", "12 марта", "12 марта 2026".
+func (p *parser) parseDateOrTime(i int) int {
+	w := p.norm[i]
+
+	if h, m, ok := parseClock(w); ok {
+		n := 1
+		if i+1 < len(p.norm) {
+			if adjusted, ok := applyDayPeriod(h, p.norm[i+1]); ok {
+				h = adjusted
+				n = 2
+			}
+		}
+		p.setTime(h, m)
+		return n
+	}
+
+	if d, ok := p.parseNumericDate(w); ok {
+		p.setDate(d)
+		return 1
+	}
+
+	day, err := strconv.Atoi(strings.TrimSuffix(w, "-го"))
+	if err != nil || day < 1 || day > 31 || !p.isMonthAt(i+1) {
+		return 0
+	}
+	month := monthWords[p.norm[i+1]]
+	n := 2
+
+	year, explicitYear := p.now.Year(), false
+	if i+2 < len(p.norm) {
+		if y, err := strconv.Atoi(strings.TrimSuffix(p.norm[i+2], "г")); err == nil && y >= 2000 && y <= 2100 {
+			year, explicitYear = y, true
+			n = 3
+			if i+3 < len(p.norm) && (p.norm[i+3] == "года" || p.norm[i+3] == "г") {
+				n = 4
+			}
+		}
+	}
+
+	d, ok := p.buildDate(year, month, day, explicitYear)
+	if !ok {
+		return 0
+	}
+	p.setDate(d)
+	return n
+}
+
+// parseRelative разбирает продолжение фразы "через ...": "3 дня", "час", "полчаса", "2 недели".
+func (p *parser) parseRelative(i int) int {
+	if i >= len(p.norm) {
+		return 0
+	}
+
+	if p.norm[i] == "полчаса" {
+		p.setExact(p.now.Add(30 * time.Minute))
+		return 1
+	}
+
+	n, consumed := 1, 0
+	if v, err := strconv.Atoi(p.norm[i]); err == nil && v > 0 {
+		n, consumed = v, 1
+	} else if v, ok := numberWords[p.norm[i]]; ok {
+		n, consumed = v, 1
+	}
+
+	if i+consumed >= len(p.norm) {
+		return 0
+	}
+	unit := p.norm[i+consumed]
+
+	switch {
+	case strings.HasPrefix(unit, "минут"):
+		p.setExact(p.now.Add(time.Duration(n) * time.Minute))
+	case strings.HasPrefix(unit, "час"):
+		p.setExact(p.now.Add(time.Duration(n) * time.Hour))
+	case unit == "день" || unit == "дня" || unit == "дней" || unit == "сутки":
+		p.setDate(p.now.AddDate(0, 0, n))
+	case strings.HasPrefix(unit, "недел"):
+		p.setDate(p.now.AddDate(0, 0, 7*n))
+	case strings.HasPrefix(unit, "месяц"):
+		p.setDate(p.now.AddDate(0, n, 0))
+	case unit == "год" || unit == "года" || unit == "лет":
+		p.setDate(p.now.AddDate(n, 0, 0))
+	default:
+		return 0
+	}
+
+	return consumed + 1
+}
+
+func (p *parser) parseNumericDate(w string) (time.Time, bool) {
+	w = strings.TrimSuffix(w, ".")
+	parts := strings.FieldsFunc(w, func(r rune) bool { return r == '.' || r == '/' })
+	if len(parts) < 2 || len(parts) > 3 || strings.Count(w, ".")+strings.Count(w, "/") != len(parts)-1 {
+		return time.Time{}, false
+	}
+
+	// месяц — двумя цифрами, иначе это скорее дробь: "1.5 л молока", "2.1 км"
+	if len(parts[0]) > 2 || len(parts[1]) != 2 || (len(parts) == 3 && len(parts[2]) != 2 && len(parts[2]) != 4) {
+		return time.Time{}, false
+	}
+
+	day, err1 := strconv.Atoi(parts[0])
+	month, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || month < 1 || month > 12 {
+		return time.Time{}, false
+	}
+
+	year, explicitYear := p.now.Year(), false
+	if len(parts) == 3 {
+		y, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return time.Time{}, false
+		}
+		if y < 100 {
+			y += 2000
+		}
+		year, explicitYear = y, true
+	}
+
+	return p.buildDate(year, time.Month(month), day, explicitYear)
+}
+
+// buildDate собирает дату и проверяет её корректность.
+// Если год не указан и дата уже прошла, берётся следующий год.
+func (p *parser) buildDate(year int, month time.Month, day int, explicitYear bool) (time.Time, bool) {
+	d := time.Date(year, month, day, 0, 0, 0, 0, p.now.Location())
+	if d.Day() != day {
+		return time.Time{}, false
+	}
+	if !explicitYear && d.Before(startOfDay(p.now)) {
+		d = d.AddDate(1, 0, 0)
+	}
+	return d, true
+}
+
+// isPhraseEnd сообщает, что слово i завершает фразу: оно последнее или за ним стоит знак препинания.
+func (p *parser) isPhraseEnd(i int) bool {
+	return i == len(p.words)-1 || strings.TrimRight(p.words[i], ",;.!?") != p.words[i]
+}
+
+func (p *parser) isMonthAt(i int) bool {
+	if i >= len(p.norm) {
+		return false
+	}
+	_, ok := monthWords[p.norm[i]]
+	return ok
+}
+
+func (p *parser) setDate(d time.Time) {
+	if p.hasDate {
+		return
+	}
+	p.hasDate = true
+	p.date = d
+}
+
+func (p *parser) setTime(h, m int) {
+	if p.hasTime {
+		return
+	}
+	p.hasTime = true
+	p.hour, p.minute = h, m
+}
+
+func (p *parser) setExact(t time.Time) {
+	if p.hasExact {
+		return
+	}
+	p.hasExact = true
+	p.exact = t
+}
+
+func (p *parser) consume(i, n int) {
+	for j := i; j < i+n && j < len(p.consumed); j++ {
+		p.consumed[j] = true
+	}
+}
+
+// consumePreposition поглощает предлог перед уже разобранным словом ("на завтра").
+func (p *parser) consumePreposition(i int, preps ...string) {
+	if i == 0 || p.consumed[i-1] {
+		return
+	}
+	for _, prep := range preps {
+		if p.norm[i-1] == prep {
+			p.consumed[i-1] = true
+			return
+		}
+	}
+}
+
+func (p *parser) result() time.Time {
+	loc := p.now.Location()
+
+	if p.hasExact {
+		return p.exact.Truncate(time.Minute)
+	}
+
+	if p.hasDate {
+		y, m, d := p.date.Date()
+		h, min := DefaultHour, 0
+		if p.hasTime {
+			h, min = p.hour, p.minute
+		}
+		return time.Date(y, m, d, h, min, 0, 0, loc)
+	}
+
+	// только время: сегодня, а если уже прошло — завтра
+	y, m, d := p.now.Date()
+	t := time.Date(y, m, d, p.hour, p.minute, 0, 0, loc)
+	if !t.After(p.now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+func (p *parser) rest() string {
+	var out []string
+	for i, w := range p.words {
+		if !p.consumed[i] {
+			out = append(out, w)
+		}
+	}
+	return strings.Trim(strings.Join(out, " "), " ,.;:-—")
+}
+
+// normalize приводит слово к нижнему регистру и убирает пунктуацию по краям.
+func normalize(w string) string {
+	w = strings.ToLower(w)
+	w = strings.ReplaceAll(w, "ё", "е")
+	return strings.Trim(w, ",;!?()\"«»—")
+}
+
+func parseClock(w string) (int, int, bool) {
+	w = strings.TrimSuffix(w, ".")
+	sep := strings.IndexAny(w, ":")
+	if sep < 0 {
+		return 0, 0, false
+	}
+	h, err1 := strconv.Atoi(w[:sep])
+	m, err2 := strconv.Atoi(w[sep+1:])
+	if err1 != nil || err2 != nil || len(w[sep+1:]) != 2 || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, 0, false
+	}
+	return h, m, true
+}
+
+// applyDayPeriod учитывает уточнения "утра", "дня", "вечера", "ночи": "в 7 вечера" → 19.
+func applyDayPeriod(h int, word string) (int, bool) {
+	switch word {
+	case "утра":
+		return h % 12, true
+	case "дня", "вечера":
+		if h < 12 {
+			h += 12
+		}
+		return h, true
+	case "ночи":
+		if h == 12 {
+			return 0, true
+		}
+		return h, true
+	}
+	return h, false
+}
+
+func nextWeekday(now time.Time, wd time.Weekday) time.Time {
+	days := (int(wd) - int(now.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return now.AddDate(0, 0, days)
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func isPreposition(w string) bool {
+	return w == "в" || w == "во" || w == "к" || w == "ко" || w == "до" || w == "на"
+}
+
+var weekdayWords = map[string]time.Weekday{
+	"понедельник": time.Monday, "понедельника": time.Monday, "понедельнику": time.Monday, "пн": time.Monday,
+	"вторник": time.Tuesday, "вторника": time.Tuesday, "вторнику": time.Tuesday, "вт": time.Tuesday,
+	"среду": time.Wednesday, "среды": time.Wednesday, "среде": time.Wednesday, "ср": time.Wednesday,
+	"четверг": time.Thursday, "четверга": time.Thursday, "четвергу": time.Thursday, "чт": time.Thursday,
+	"пятницу": time.Friday, "пятницы": time.Friday, "пятнице": time.Friday, "пт": time.Friday,
+	"субботу": time.Saturday, "субботы": time.Saturday, "субботе": time.Saturday, "сб": time.Saturday,
+	"воскресенье": time.Sunday, "воскресенья": time.Sunday, "воскресенью": time.Sunday, "вс": time.Sunday,
+}
+
+var monthWords = map[string]time.Month{
+	"января": time.January, "февраля": time.February, "марта": time.March,
+	"апреля": time.April, "мая": time.May, "июня": time.June,
+	"июля": time.July, "августа": time.August, "сентября": time.September,
+	"октября": time.October, "ноября": time.November, "декабря": time.December,
+}
+
+var numberWords = map[string]int{
+	"один": 1, "одну": 1, "одна": 1, "два": 2, "две": 2, "три": 3, "четыре": 4,
+	"пять": 5, "шесть": 6, "семь": 7, "восемь": 8, "девять": 9, "десять": 10,
+}
+
+var partOfDayWords = map[string]int{
+	"утром": 9, "днем": 13, "вечером": 19, "ночью": 23,
+}
+
+var hourWords = map[string]bool{
+	"час": true, "часа": true, "часов": true,
+}
@@ -0,0 +1,223 @@
+package dateparse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	// среда, 14 октября 2026, 10:00 по Москве
+	now := time.Date(2026, time.October, 14, 10, 0, 0, 0, msk)
+
+	tests := []struct {
+		name     string
+		text     string
+		wantOK   bool
+		wantTime time.Time
+		hasTime  bool
+		rest     string
+	}{
+		{
+			name:     "завтра со временем",
+			text:     "завтра в 18:00 позвонить маме",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 15, 18, 0, 0, 0, msk),
+			hasTime:  true,
+			rest:     "позвонить маме",
+		},
+		{
+			name:     "день недели",
+			text:     "в пятницу сдать отчёт",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 16, DefaultHour, 0, 0, 0, msk),
+			rest:     "сдать отчёт",
+		},
+		{
+			name:     "тот же день недели переносится на следующую неделю",
+			text:     "во вторник планёрка",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 20, DefaultHour, 0, 0, 0, msk),
+			rest:     "планёрка",
+		},
+		{
+			name:     "через несколько дней",
+			text:     "через 3 дня",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 17, DefaultHour, 0, 0, 0, msk),
+			rest:     "",
+		},
+		{
+			name:     "через часы",
+			text:     "проверить духовку через 2 часа",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 14, 12, 0, 0, 0, msk),
+			hasTime:  true,
+			rest:     "проверить духовку",
+		},
+		{
+			name:     "через неделю без числа",
+			text:     "через неделю оплатить интернет",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 21, DefaultHour, 0, 0, 0, msk),
+			rest:     "оплатить интернет",
+		},
+		{
+			name:     "через полчаса",
+			text:     "через полчаса созвон",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 14, 10, 30, 0, 0, msk),
+			hasTime:  true,
+			rest:     "созвон",
+		},
+		{
+			name:     "только время, которое уже прошло",
+			text:     "в 9:30 зарядка",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 15, 9, 30, 0, 0, msk),
+			hasTime:  true,
+			rest:     "зарядка",
+		},
+		{
+			name:     "время с уточнением части суток",
+			text:     "ужин в 7 вечера",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 14, 19, 0, 0, 0, msk),
+			hasTime:  true,
+			rest:     "ужин",
+		},
+		{
+			name:     "числовая дата",
+			text:     "продлить страховку 12.03",
+			wantOK:   true,
+			wantTime: time.Date(2027, time.March, 12, DefaultHour, 0, 0, 0, msk),
+			rest:     "продлить страховку",
+		},
+		{
+			name:     "числовая дата с годом и временем",
+			text:     "врач 20.10.2026 в 8:15",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 20, 8, 15, 0, 0, msk),
+			hasTime:  true,
+			rest:     "врач",
+		},
+		{
+			name:     "дата словами с предлогом",
+			text:     "до 5 ноября подать декларацию",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.November, 5, DefaultHour, 0, 0, 0, msk),
+			rest:     "подать декларацию",
+		},
+		{
+			name:     "часть суток",
+			text:     "послезавтра вечером кино",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 16, 19, 0, 0, 0, msk),
+			hasTime:  true,
+			rest:     "кино",
+		},
+		{
+			name:     "на завтра",
+			text:     "купить хлеб на завтра",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 15, DefaultHour, 0, 0, 0, msk),
+			rest:     "купить хлеб",
+		},
+		{
+			name:   "без даты",
+			text:   "купить молоко",
+			wantOK: false,
+			rest:   "купить молоко",
+		},
+		{
+			name:     "час в конце фразы",
+			text:     "созвон с командой в 18",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 14, 18, 0, 0, 0, msk),
+			hasTime:  true,
+			rest:     "созвон с командой",
+		},
+		{
+			name:     "час со словом «часов»",
+			text:     "завтра в 6 часов пробежка",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.October, 15, 6, 0, 0, 0, msk),
+			hasTime:  true,
+			rest:     "пробежка",
+		},
+		{
+			name:     "дата с годом из двух цифр",
+			text:     "техосмотр 01.12.26",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.December, 1, DefaultHour, 0, 0, 0, msk),
+			rest:     "техосмотр",
+		},
+		{
+			name:   "число с предлогом перед словом — не время",
+			text:   "позвонить в 2 офиса",
+			wantOK: false,
+			rest:   "позвонить в 2 офиса",
+		},
+		{
+			name:   "количество с «на» — не время",
+			text:   "заказать столик на 4 человек",
+			wantOK: false,
+			rest:   "заказать столик на 4 человек",
+		},
+		{
+			name:   "дробное число — не дата",
+			text:   "купить 1.5 л молока",
+			wantOK: false,
+			rest:   "купить 1.5 л молока",
+		},
+		{
+			name:   "дробь через косую черту — не дата",
+			text:   "добавить 1/2 ложки соли",
+			wantOK: false,
+			rest:   "добавить 1/2 ложки соли",
+		},
+		{
+			name:   "версия — не дата",
+			text:   "обновить до 2.1",
+			wantOK: false,
+			rest:   "обновить до 2.1",
+		},
+		{
+			name:     "число перед датой не сбивает разбор",
+			text:     "купить 2 билета на 5 ноября",
+			wantOK:   true,
+			wantTime: time.Date(2026, time.November, 5, DefaultHour, 0, 0, 0, msk),
+			rest:     "купить 2 билета",
+		},
+		{
+			name:   "некорректная дата",
+			text:   "встреча 31.02",
+			wantOK: false,
+			rest:   "встреча 31.02",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Parse(tt.text, now)
+			if ok != tt.wantOK {
+				t.Fatalf("Parse(%q) ok = %v, want %v", tt.text, ok, tt.wantOK)
+			}
+			if got.Rest != tt.rest {
+				t.Errorf("Parse(%q) rest = %q, want %q", tt.text, got.Rest, tt.rest)
+			}
+			if !ok {
+				return
+			}
+			if !got.Time.Equal(tt.wantTime) {
+				t.Errorf("Parse(%q) time = %v, want %v", tt.text, got.Time, tt.wantTime)
+			}
+			if got.HasTime != tt.hasTime {
+				t.Errorf("Parse(%q) hasTime = %v, want %v", tt.text, got.HasTime, tt.hasTime)
+			}
+			if got.Time.Location() != msk {
+				t.Errorf("Parse(%q) location = %v, want %v", tt.text, got.Time.Location(), msk)
+			}
+		})
+	}
+}
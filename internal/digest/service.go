@@ -0,0 +1,208 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/credits"
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+)
+
+// Notifier отправляет текстовое уведомление пользователю (реализуется ботом).
+type Notifier interface {
+	Notify(ctx context.Context, userID int64, text string) error
+}
+
+// LocationProvider возвращает часовой пояс пользователя (реализуется storage.UserRepo).
+type LocationProvider interface {
+	Location(ctx context.Context, userID int64) *time.Location
+}
+
+// Service собирает сводку и управляет настройками.
+type Service struct {
+	repo     Repository
+	todos    *todo.Service
+	finance  *finance.Service
+	credits  *credits.Service
+	users    LocationProvider
+	notifier Notifier
+}
+
+func NewService(
+	repo Repository,
+	todos *todo.Service,
+	fin *finance.Service,
+	cred *credits.Service,
+	users LocationProvider,
+	notifier Notifier,
+) *Service {
+	return &Service{repo: repo, todos: todos, finance: fin, credits: cred, users: users, notifier: notifier}
+}
+
+// Settings возвращает настройки сводки пользователя.
+func (s *Service) Settings(ctx context.Context, userID int64) (Settings, error) {
+	return s.repo.GetSettings(ctx, userID)
+}
+
+// SaveSettings проверяет и сохраняет настройки.
+func (s *Service) SaveSettings(ctx context.Context, st Settings) error {
+	if st.SendMinute < 0 || st.SendMinute >= 24*60 {
+		return fmt.Errorf("время отправки вне диапазона")
+	}
+	if st.DaysAhead < 0 || st.DaysAhead > MaxDaysAhead {
+		return fmt.Errorf("горизонт платежей должен быть от 0 до %d дней", MaxDaysAhead)
+	}
+	if st.DailyBudget < 0 {
+		return fmt.Errorf("бюджет не может быть отрицательным")
+	}
+	return s.repo.SaveSettings(ctx, st)
+}
+
+// RunCheck отправляет сводку пользователям, у которых наступило время отправки.
+// Вызывается каждую минуту из main.go; за день сводка отправляется не более одного раза.
+func (s *Service) RunCheck(ctx context.Context) {
+	list, err := s.repo.ListEnabled(ctx)
+	if err != nil {
+		logger.Error("Digest: failed to list settings: " + err.Error())
+		return
+	}
+
+	now := time.Now()
+	for _, st := range list {
+		local := now.In(s.users.Location(ctx, st.UserID))
+		if local.Hour()*60+local.Minute() < st.SendMinute {
+			continue
+		}
+
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+		claimed, err := s.repo.ClaimDay(ctx, st.UserID, day)
+		if err != nil || !claimed {
+			continue
+		}
+
+		text, err := s.Build(ctx, st, now)
+		if err == nil {
+			err = s.notifier.Notify(ctx, st.UserID, text)
+		}
+		if err != nil {
+			logger.Error(fmt.Sprintf("Digest: failed for user %d: %s", st.UserID, err.Error()))
+			if err := s.repo.ReleaseDay(ctx, st.UserID, day); err != nil {
+				logger.Error("Digest: failed to release day: " + err.Error())
+			}
+		}
+	}
+}
+
+// Build формирует текст сводки на момент now.
+func (s *Service) Build(ctx context.Context, st Settings, now time.Time) (string, error) {
+	loc := s.users.Location(ctx, st.UserID)
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("☀️ Доброе утро! Сводка на %s\n", today.Format("02.01.2006")))
+
+	// задачи
+	overdue, err := s.todos.ListFiltered(ctx, st.UserID, todo.ListFilter{View: todo.ViewOverdue}, loc)
+	if err != nil {
+		return "", fmt.Errorf("overdue todos: %w", err)
+	}
+	todays, err := s.todos.ListFiltered(ctx, st.UserID, todo.ListFilter{View: todo.ViewToday}, loc)
+	if err != nil {
+		return "", fmt.Errorf("today todos: %w", err)
+	}
+	// просроченные сегодня задачи попадают в оба списка — показываем их среди просроченных
+	overdueIDs := make(map[int]bool, len(overdue))
+	for _, it := range overdue {
+		overdueIDs[it.ID] = true
+	}
+
+	b.WriteString("\n📝 Задачи на сегодня:\n")
+	n := 0
+	for _, it := range todays {
+		if overdueIDs[it.ID] {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("▫️ %d) %s — %s\n", it.ID, it.Title, it.DueDate.In(loc).Format("15:04")))
+		n++
+	}
+	if n == 0 {
+		b.WriteString("нет\n")
+	}
+
+	if len(overdue) > 0 {
+		b.WriteString("\n⚠️ Просрочено:\n")
+		for _, it := range overdue {
+			b.WriteString(fmt.Sprintf("▫️ %d) %s — %s\n", it.ID, it.Title, it.DueDate.In(loc).Format("02.01 15:04")))
+		}
+	}
+
+	// регулярные платежи
+	if st.DaysAhead > 0 {
+		payments, err := s.finance.GetRecurringList(ctx, st.UserID)
+		if err != nil {
+			return "", fmt.Errorf("recurring: %w", err)
+		}
+		until := today.AddDate(0, 0, st.DaysAhead+1)
+		sort.Slice(payments, func(i, j int) bool { return payments[i].NextPayment.Before(payments[j].NextPayment) })
+
+		var lines []string
+		for _, p := range payments {
+			if p.NextPayment.Before(until) {
+				lines = append(lines, fmt.Sprintf("• %s — %s — %.2f ₽", p.NextPayment.In(loc).Format("02.01"), p.Title, p.Amount))
+			}
+		}
+		if len(lines) > 0 {
+			b.WriteString(fmt.Sprintf("\n🔁 Платежи в ближайшие %d дн.:\n", st.DaysAhead))
+			b.WriteString(strings.Join(lines, "\n") + "\n")
+		}
+	}
+
+	// кредиты
+	list, err := s.credits.List(ctx, st.UserID)
+	if err != nil {
+		return "", fmt.Errorf("credits: %w", err)
+	}
+	var creditLines []string
+	for _, c := range list {
+		p, num, ok := credits.NextPayment(c, today)
+		if !ok {
+			continue
+		}
+		creditLines = append(creditLines, fmt.Sprintf("• %s — %s — %.2f ₽ (%d/%d)", c.Title, p.DueDate.Format("02.01"), p.Total, num, c.Months))
+	}
+	if len(creditLines) > 0 {
+		b.WriteString("\n🏦 Ближайшие платежи по кредитам:\n")
+		b.WriteString(strings.Join(creditLines, "\n") + "\n")
+	}
+
+	// вчерашние расходы
+	spent, err := s.spentBetween(ctx, st.UserID, today.AddDate(0, 0, -1), today)
+	if err != nil {
+		return "", fmt.Errorf("spending: %w", err)
+	}
+	b.WriteString(fmt.Sprintf("\n💸 Вчера потрачено: %.2f ₽", spent))
+	if st.DailyBudget > 0 {
+		b.WriteString(fmt.Sprintf(" из %.2f ₽ (%.0f%%)", st.DailyBudget, spent/st.DailyBudget*100))
+		if spent > st.DailyBudget {
+			b.WriteString(fmt.Sprintf("\nПревышение: %.2f ₽", spent-st.DailyBudget))
+		}
+	}
+	b.WriteString("\n")
+
+	return b.String(), nil
+}
+
+// spentBetween — сумма расходов пользователя в интервале [from, to).
+func (s *Service) spentBetween(ctx context.Context, userID int64, from, to time.Time) (float64, error) {
+	totals, err := s.finance.Totals(ctx, userID, from, to)
+	if err != nil {
+		return 0, err
+	}
+	return totals.Expense, nil
+}
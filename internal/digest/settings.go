@@ -0,0 +1,58 @@
+// Package digest формирует и рассылает утреннюю сводку: задачи на сегодня и просроченные,
+// ближайшие регулярные платежи, платежи по кредитам и вчерашние расходы относительно бюджета.
+package digest
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Settings — настройки сводки пользователя.
+type Settings struct {
+	UserID      int64
+	Enabled     bool
+	SendMinute  int     // время отправки в минутах от полуночи по часовому поясу пользователя
+	DaysAhead   int     // за сколько дней показывать регулярные платежи
+	DailyBudget float64 // дневной бюджет расходов; 0 — не задан
+}
+
+// Значения по умолчанию для пользователя без сохранённых настроек.
+const (
+	DefaultSendMinute = 8 * 60
+	DefaultDaysAhead  = 3
+	MaxDaysAhead      = 31
+)
+
+// DefaultSettings возвращает настройки по умолчанию (сводка выключена).
+func DefaultSettings(userID int64) Settings {
+	return Settings{UserID: userID, SendMinute: DefaultSendMinute, DaysAhead: DefaultDaysAhead}
+}
+
+// SendTime возвращает время отправки в формате ЧЧ:ММ.
+func (s Settings) SendTime() string {
+	return fmt.Sprintf("%02d:%02d", s.SendMinute/60, s.SendMinute%60)
+}
+
+// ParseSendTime разбирает время ЧЧ:ММ в минуты от полуночи.
+func ParseSendTime(v string) (int, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, fmt.Errorf("неверное время %q, ожидается ЧЧ:ММ", v)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Repository хранит настройки сводки и отметки об отправке.
+type Repository interface {
+	// GetSettings возвращает настройки пользователя или DefaultSettings.
+	GetSettings(ctx context.Context, userID int64) (Settings, error)
+	SaveSettings(ctx context.Context, s Settings) error
+	// ListEnabled возвращает настройки всех пользователей, включивших сводку.
+	ListEnabled(ctx context.Context) ([]Settings, error)
+	// ClaimDay отмечает, что сводка за день day отправлена.
+	// Возвращает false, если сводка за этот день уже была отправлена.
+	ClaimDay(ctx context.Context, userID int64, day time.Time) (bool, error)
+	// ReleaseDay снимает отметку (если отправка не удалась).
+	ReleaseDay(ctx context.Context, userID int64, day time.Time) error
+}
@@ -0,0 +1,290 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/logger"
+)
+
+// Ошибки бюджетов.
+var (
+	ErrBudgetNotFound = errors.New("бюджет не найден")
+	ErrBudgetLimit    = errors.New("лимит бюджета должен быть больше нуля")
+	// ErrBudgetCategory возвращается для бюджета на категорию доходов.
+	ErrBudgetCategory = errors.New("бюджет можно задать только для категории расходов")
+	ErrBudgetRollover = errors.New("неизвестный режим переноса остатка")
+)
+
+// Rollover — что происходит с остатком бюджета в конце месяца.
+// Переносится только результат предыдущего месяца, без накопления за несколько месяцев.
+type Rollover string
+
+const (
+	RolloverNone   Rollover = "none"   // каждый месяц с полного лимита
+	RolloverUnused Rollover = "unused" // неизрасходованный остаток добавляется к лимиту следующего месяца
+	RolloverAll    Rollover = "all"    // переносится и остаток, и перерасход
+)
+
+// ParseRollover разбирает режим переноса; пустая строка — RolloverNone.
+func ParseRollover(s string) (Rollover, bool) {
+	switch r := Rollover(strings.ToLower(strings.TrimSpace(s))); r {
+	case "":
+		return RolloverNone, true
+	case RolloverNone, RolloverUnused, RolloverAll:
+		return r, true
+	}
+	return "", false
+}
+
+// BudgetThresholds — доли лимита в процентах, при пересечении которых приходит уведомление.
+var BudgetThresholds = []float64{80, 100}
+
+// Budget — месячный лимит расходов по категории (вместе с её подкатегориями) или общий (CategoryID = 0).
+type Budget struct {
+	ID         int      `json:"id"`
+	UserID     int64    `json:"user_id"`
+	CategoryID int      `json:"category_id,omitempty"`
+	Category   string   `json:"category,omitempty"` // имя категории; пусто у общего бюджета
+	Limit      float64  `json:"limit"`
+	Rollover   Rollover `json:"rollover"`
+}
+
+// Title — название бюджета для сообщений.
+func (b *Budget) Title() string {
+	if b.CategoryID == 0 {
+		return "Общий бюджет"
+	}
+	return b.Category
+}
+
+// BudgetProgress — исполнение бюджета за месяц.
+type BudgetProgress struct {
+	Budget
+	Month     time.Time `json:"month"`     // начало месяца
+	Spent     float64   `json:"spent"`     // расходы за месяц
+	Carried   float64   `json:"carried"`   // перенесено с прошлого месяца (отрицательное — перерасход)
+	Available float64   `json:"available"` // лимит с учётом переноса
+	Remaining float64   `json:"remaining"` // сколько ещё можно потратить (отрицательное — перерасход)
+	Percent   float64   `json:"percent"`   // потрачено от доступного, %
+}
+
+// MonthRange возвращает начало месяца, в который попадает t, и начало следующего (в часовом поясе t).
+func MonthRange(t time.Time) (time.Time, time.Time) {
+	from := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return from, from.AddDate(0, 1, 0)
+}
+
+// carryOver — сколько переносится с прошлого месяца при расходах prevSpent.
+func carryOver(r Rollover, limit, prevSpent float64) float64 {
+	switch r {
+	case RolloverUnused:
+		return math.Max(limit-prevSpent, 0)
+	case RolloverAll:
+		return limit - prevSpent
+	}
+	return 0
+}
+
+// spentPercent — доля потраченного от доступного; исчерпанный бюджет без расходов — 0%, с расходами — не меньше 100%.
+func spentPercent(spent, available float64) float64 {
+	if available <= 0 {
+		if spent > 0 {
+			return 100
+		}
+		return 0
+	}
+	return spent / available * 100
+}
+
+// NewBudgetProgress считает исполнение бюджета за месяц month по расходам этого и прошлого месяца.
+func NewBudgetProgress(b Budget, month time.Time, spent, prevSpent float64) BudgetProgress {
+	carried := carryOver(b.Rollover, b.Limit, prevSpent)
+	available := b.Limit + carried
+	return BudgetProgress{
+		Budget:    b,
+		Month:     month,
+		Spent:     spent,
+		Carried:   carried,
+		Available: available,
+		Remaining: available - spent,
+		Percent:   spentPercent(spent, available),
+	}
+}
+
+// CrossedThreshold возвращает наибольший порог из BudgetThresholds, который перешла последняя операция
+// на сумму amount (уже учтённую в p.Spent), или 0.
+func CrossedThreshold(p BudgetProgress, amount float64) float64 {
+	before := spentPercent(p.Spent-amount, p.Available)
+	after := p.Percent
+	crossed := 0.0
+	for _, t := range BudgetThresholds {
+		if before < t && after >= t {
+			crossed = t
+		}
+	}
+	return crossed
+}
+
+// BudgetAlertText — текст уведомления о пересечении порога threshold.
+func BudgetAlertText(p BudgetProgress, threshold float64) string {
+	if threshold >= 100 {
+		return fmt.Sprintf("🚨 %s превышен: потрачено %.2f ₽ из %.2f ₽ (%.0f%%). Перерасход %.2f ₽.",
+			p.Title(), p.Spent, p.Available, p.Percent, -p.Remaining)
+	}
+	return fmt.Sprintf("⚠️ %s: потрачено %.2f ₽ из %.2f ₽ (%.0f%%). Осталось %.2f ₽.",
+		p.Title(), p.Spent, p.Available, p.Percent, p.Remaining)
+}
+
+// BudgetAlert — событие websocket budget_alert.
+type BudgetAlert struct {
+	Threshold float64        `json:"threshold"`
+	Progress  BudgetProgress `json:"progress"`
+	EntryID   int            `json:"entry_id"`
+}
+
+// BudgetRepository хранит бюджеты.
+type BudgetRepository interface {
+	// ListBudgets возвращает бюджеты пользователя: общий первым, затем по имени категории.
+	ListBudgets(ctx context.Context, userID int64) ([]Budget, error)
+	// SetBudget создаёт или заменяет бюджет пользователя на категорию и заполняет его ID.
+	SetBudget(ctx context.Context, b *Budget) error
+	// DeleteBudget удаляет бюджет пользователя (ErrBudgetNotFound, если его нет).
+	DeleteBudget(ctx context.Context, userID int64, id int) error
+	// BudgetSpending возвращает расходы за [from, to) по каждому бюджету пользователя (ID бюджета → сумма);
+	// бюджет категории учитывает и её подкатегории.
+	BudgetSpending(ctx context.Context, userID int64, from, to time.Time) (map[int]float64, error)
+}
+
+// Notifier отправляет пользователю сообщение в Telegram (реализуется ботом).
+type Notifier interface {
+	Notify(ctx context.Context, userID int64, text string) error
+}
+
+// EventPublisher рассылает событие клиентам пользователя (реализуется websocket.Hub).
+type EventPublisher interface {
+	Publish(userID int64, eventType string, data interface{})
+}
+
+// LocationProvider возвращает часовой пояс пользователя (реализуется storage.UserRepo).
+type LocationProvider interface {
+	Location(ctx context.Context, userID int64) *time.Location
+}
+
+// SetBudgetAlerts подключает уведомления о пересечении порогов бюджета: сообщения бота и события websocket.
+// users определяет границы месяца в часовом поясе пользователя. Любой из аргументов может быть nil.
+func (s *Service) SetBudgetAlerts(users LocationProvider, n Notifier, events EventPublisher) {
+	s.users = users
+	s.notifier = n
+	s.events = events
+}
+
+// location возвращает часовой пояс пользователя или часовой пояс сервера.
+func (s *Service) location(ctx context.Context, userID int64) *time.Location {
+	if s.users == nil {
+		return time.Local
+	}
+	return s.users.Location(ctx, userID)
+}
+
+// SetBudget задаёт месячный лимит на категорию расходов (categoryID 0 — общий бюджет).
+func (s *Service) SetBudget(ctx context.Context, userID int64, categoryID int, limit float64, rollover Rollover) (*Budget, error) {
+	if limit <= 0 || math.IsInf(limit, 0) || math.IsNaN(limit) {
+		return nil, ErrBudgetLimit
+	}
+	r, ok := ParseRollover(string(rollover))
+	if !ok {
+		return nil, ErrBudgetRollover
+	}
+
+	b := &Budget{UserID: userID, CategoryID: categoryID, Limit: limit, Rollover: r}
+	if categoryID != 0 {
+		c, err := s.repo.GetCategory(ctx, userID, categoryID)
+		if err != nil {
+			return nil, err
+		}
+		if c.Type != TypeExpense {
+			return nil, ErrBudgetCategory
+		}
+		b.Category = c.Name
+	}
+	if err := s.repo.SetBudget(ctx, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// DeleteBudget удаляет бюджет пользователя.
+func (s *Service) DeleteBudget(ctx context.Context, userID int64, id int) error {
+	return s.repo.DeleteBudget(ctx, userID, id)
+}
+
+// BudgetReport возвращает исполнение бюджетов пользователя за месяц, в который попадает month
+// (границы месяца — в часовом поясе month).
+func (s *Service) BudgetReport(ctx context.Context, userID int64, month time.Time) ([]BudgetProgress, error) {
+	budgets, err := s.repo.ListBudgets(ctx, userID)
+	if err != nil || len(budgets) == 0 {
+		return nil, err
+	}
+
+	from, to := MonthRange(month)
+	spent, err := s.repo.BudgetSpending(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	prevSpent, err := s.repo.BudgetSpending(ctx, userID, from.AddDate(0, -1, 0), from)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]BudgetProgress, 0, len(budgets))
+	for _, b := range budgets {
+		report = append(report, NewBudgetProgress(b, from, spent[b.ID], prevSpent[b.ID]))
+	}
+	return report, nil
+}
+
+// checkBudgets уведомляет пользователя о бюджетах, порог которых перешёл расход e.
+// Ошибки только логируются: операция к этому моменту уже сохранена.
+func (s *Service) checkBudgets(ctx context.Context, e *FinanceEntry) {
+	if e.Type != TypeExpense || (s.notifier == nil && s.events == nil) {
+		return
+	}
+
+	parentID := 0
+	if e.CategoryID != 0 {
+		c, err := s.repo.GetCategory(ctx, e.UserID, e.CategoryID)
+		if err != nil {
+			logger.Error("Budget check category error: " + err.Error())
+			return
+		}
+		parentID = c.ParentID
+	}
+
+	report, err := s.BudgetReport(ctx, e.UserID, e.OccurredAt.In(s.location(ctx, e.UserID)))
+	if err != nil {
+		logger.Error("Budget check error: " + err.Error())
+		return
+	}
+	for _, p := range report {
+		if p.CategoryID != 0 && p.CategoryID != e.CategoryID && p.CategoryID != parentID {
+			continue
+		}
+		threshold := CrossedThreshold(p, e.Amount)
+		if threshold == 0 {
+			continue
+		}
+		if s.events != nil {
+			s.events.Publish(e.UserID, "budget_alert", BudgetAlert{Threshold: threshold, Progress: p, EntryID: e.ID})
+		}
+		if s.notifier != nil {
+			if err := s.notifier.Notify(ctx, e.UserID, BudgetAlertText(p, threshold)); err != nil {
+				logger.Error("Budget alert notify error: " + err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package finance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRollover(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   Rollover
+		wantOK bool
+	}{
+		{in: "", want: RolloverNone, wantOK: true},
+		{in: "none", want: RolloverNone, wantOK: true},
+		{in: " Unused ", want: RolloverUnused, wantOK: true},
+		{in: "all", want: RolloverAll, wantOK: true},
+		{in: "forever", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRollover(tt.in)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("ParseRollover(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestMonthRange(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	from, to := MonthRange(time.Date(2026, 12, 31, 23, 30, 0, 0, loc))
+
+	if want := time.Date(2026, 12, 1, 0, 0, 0, 0, loc); !from.Equal(want) {
+		t.Errorf("from = %v, want %v", from, want)
+	}
+	if want := time.Date(2027, 1, 1, 0, 0, 0, 0, loc); !to.Equal(want) {
+		t.Errorf("to = %v, want %v", to, want)
+	}
+}
+
+func TestNewBudgetProgress(t *testing.T) {
+	month := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		rollover      Rollover
+		spent, prev   float64
+		wantCarried   float64
+		wantAvailable float64
+		wantRemaining float64
+		wantPercent   float64
+	}{
+		{name: "без переноса", rollover: RolloverNone, spent: 12000, prev: 5000,
+			wantCarried: 0, wantAvailable: 15000, wantRemaining: 3000, wantPercent: 80},
+		{name: "перенос остатка", rollover: RolloverUnused, spent: 12000, prev: 10000,
+			wantCarried: 5000, wantAvailable: 20000, wantRemaining: 8000, wantPercent: 60},
+		{name: "перерасход не переносится", rollover: RolloverUnused, spent: 3000, prev: 18000,
+			wantCarried: 0, wantAvailable: 15000, wantRemaining: 12000, wantPercent: 20},
+		{name: "перенос перерасхода", rollover: RolloverAll, spent: 6000, prev: 18000,
+			wantCarried: -3000, wantAvailable: 12000, wantRemaining: 6000, wantPercent: 50},
+		{name: "бюджет исчерпан прошлым месяцем", rollover: RolloverAll, spent: 100, prev: 30000,
+			wantCarried: -15000, wantAvailable: 0, wantRemaining: -100, wantPercent: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := Budget{ID: 1, Limit: 15000, Rollover: tt.rollover}
+			p := NewBudgetProgress(b, month, tt.spent, tt.prev)
+			if p.Carried != tt.wantCarried || p.Available != tt.wantAvailable ||
+				p.Remaining != tt.wantRemaining || p.Percent != tt.wantPercent {
+				t.Errorf("got carried=%v available=%v remaining=%v percent=%v, want %v %v %v %v",
+					p.Carried, p.Available, p.Remaining, p.Percent,
+					tt.wantCarried, tt.wantAvailable, tt.wantRemaining, tt.wantPercent)
+			}
+		})
+	}
+}
+
+func TestCrossedThreshold(t *testing.T) {
+	tests := []struct {
+		name   string
+		spent  float64 // с учётом новой операции
+		amount float64
+		want   float64
+	}{
+		{name: "ниже порогов", spent: 500, amount: 100, want: 0},
+		{name: "переход 80%", spent: 850, amount: 100, want: 80},
+		{name: "ровно 80%", spent: 800, amount: 50, want: 80},
+		{name: "уже выше 80%", spent: 900, amount: 50, want: 0},
+		{name: "переход 100%", spent: 1010, amount: 100, want: 100},
+		{name: "сразу через оба порога", spent: 1200, amount: 600, want: 100},
+		{name: "уже превышен", spent: 1300, amount: 100, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewBudgetProgress(Budget{Limit: 1000}, time.Time{}, tt.spent, 0)
+			if got := CrossedThreshold(p, tt.amount); got != tt.want {
+				t.Errorf("CrossedThreshold(spent=%v, amount=%v) = %v, want %v", tt.spent, tt.amount, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Ошибки справочника категорий.
+var (
+	ErrCategoryNotFound = errors.New("категория не найдена")
+	ErrCategoryExists   = errors.New("категория с таким именем уже есть")
+	ErrCategoryName     = errors.New("некорректное имя категории")
+	ErrCategoryType     = errors.New("тип категории должен быть income или expense")
+	// ErrCategoryParent возвращается для родителя другого типа, вложенного родителя или цикла.
+	ErrCategoryParent = errors.New("некорректная родительская категория")
+	// ErrCategoryMerge возвращается при объединении категории с самой собой или с категорией другого типа.
+	ErrCategoryMerge = errors.New("эти категории нельзя объединить")
+	// ErrCategoryInUse возвращается при удалении категории, в которой есть операции.
+	ErrCategoryInUse = errors.New("в категории есть операции — объедините её с другой")
+)
+
+// RecurringCategory — категория операций из регулярных платежей без своей категории.
+const RecurringCategory = "Регулярные платежи"
+
+// maxCategoryName — максимальная длина имени категории в символах.
+const maxCategoryName = 64
+
+// Category — категория операций пользователя. Категории образуют дерево глубиной два:
+// у верхнеуровневой категории ParentID = 0, у подкатегории — ID родителя того же типа.
+type Category struct {
+	ID       int    `json:"id"`
+	UserID   int64  `json:"user_id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"` // income / expense
+	ParentID int    `json:"parent_id,omitempty"`
+	Icon     string `json:"icon,omitempty"`
+	Uses     int    `json:"uses"` // число операций в категории
+}
+
+// Label — имя категории с иконкой, как оно показывается на кнопках.
+func (c *Category) Label() string {
+	if c.Icon == "" {
+		return c.Name
+	}
+	return c.Icon + " " + c.Name
+}
+
+// CategoryUpdate — изменяемые поля категории; nil-поля не меняются, ParentID 0 — сделать верхнеуровневой.
+type CategoryUpdate struct {
+	Name     *string `json:"name,omitempty"`
+	Icon     *string `json:"icon,omitempty"`
+	ParentID *int    `json:"parent_id,omitempty"`
+}
+
+// CategorySeed — категория справочника по умолчанию с подкатегориями.
+type CategorySeed struct {
+	Icon     string
+	Name     string
+	Children []CategorySeed
+}
+
+// DefaultCategories — стартовый справочник категорий по типам операций.
+var DefaultCategories = map[string][]CategorySeed{
+	TypeExpense: {
+		{Icon: "🛒", Name: "Продукты"},
+		{Icon: "🍽", Name: "Кафе и рестораны"},
+		{Icon: "🚌", Name: "Транспорт", Children: []CategorySeed{
+			{Icon: "🚕", Name: "Такси"},
+			{Icon: "⛽", Name: "Топливо"},
+		}},
+		{Icon: "🏠", Name: "Жильё", Children: []CategorySeed{
+			{Icon: "💡", Name: "Коммунальные услуги"},
+			{Icon: "📶", Name: "Связь и интернет"},
+		}},
+		{Icon: "💊", Name: "Здоровье"},
+		{Icon: "👕", Name: "Одежда"},
+		{Icon: "🎉", Name: "Развлечения", Children: []CategorySeed{
+			{Icon: "📺", Name: "Подписки"},
+		}},
+		{Icon: "🎁", Name: "Подарки"},
+		{Icon: "🔁", Name: RecurringCategory},
+		{Icon: "📦", Name: "Другое"},
+	},
+	TypeIncome: {
+		{Icon: "💼", Name: "Зарплата"},
+		{Icon: "💻", Name: "Подработка"},
+		{Icon: "📈", Name: "Проценты и дивиденды"},
+		{Icon: "🎁", Name: "Подарки"},
+		{Icon: "📦", Name: "Другое"},
+	},
+}
+
+// NormalizeCategoryName обрезает пробелы и проверяет длину имени категории.
+func NormalizeCategoryName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || len([]rune(name)) > maxCategoryName {
+		return "", ErrCategoryName
+	}
+	return name, nil
+}
+
+// MatchCategory находит категорию по тексту кнопки или имени без учёта регистра.
+func MatchCategory(list []Category, text string) (*Category, bool) {
+	text = strings.TrimSpace(text)
+	for i := range list {
+		c := &list[i]
+		if strings.EqualFold(c.Label(), text) || strings.EqualFold(c.Name, text) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// SortByUsage упорядочивает категории для клавиатуры: сначала часто используемые, затем по имени.
+func SortByUsage(list []Category) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Uses != list[j].Uses {
+			return list[i].Uses > list[j].Uses
+		}
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+}
+
+// checkParent проверяет, что parent может быть родителем категории c:
+// тот же тип, сам parent верхнеуровневый, а у c нет своих подкатегорий.
+func checkParent(c, parent *Category, hasChildren bool) error {
+	if parent.ID == c.ID || parent.Type != c.Type || parent.ParentID != 0 || hasChildren {
+		return ErrCategoryParent
+	}
+	return nil
+}
+
+// mergeParent возвращает нового родителя для подкатегорий source при объединении с target:
+// сам target, если он верхнеуровневый, иначе его родитель.
+func mergeParent(target *Category) int {
+	if target.ParentID != 0 {
+		return target.ParentID
+	}
+	return target.ID
+}
+
+// CategoryRepository хранит справочник категорий.
+type CategoryRepository interface {
+	// EnsureCategories при первом обращении заполняет справочник пользователя категориями по умолчанию
+	// и категориями из уже внесённых операций.
+	EnsureCategories(ctx context.Context, userID int64) error
+	// ListCategories возвращает категории пользователя с числом операций; typ "" — всех типов.
+	ListCategories(ctx context.Context, userID int64, typ string) ([]Category, error)
+	// GetCategory возвращает категорию пользователя или ErrCategoryNotFound.
+	GetCategory(ctx context.Context, userID int64, id int) (*Category, error)
+	// FindCategory ищет категорию по имени без учёта регистра или возвращает ErrCategoryNotFound.
+	FindCategory(ctx context.Context, userID int64, typ, name string) (*Category, error)
+	// CreateCategory сохраняет категорию и заполняет её ID (ErrCategoryExists при совпадении имени).
+	CreateCategory(ctx context.Context, c *Category) error
+	// UpdateCategory сохраняет имя, иконку и родителя; новое имя переносится в операции категории.
+	UpdateCategory(ctx context.Context, c *Category) error
+	// HasChildren сообщает, есть ли у категории подкатегории.
+	HasChildren(ctx context.Context, userID int64, id int) (bool, error)
+	// DeleteCategory удаляет категорию без операций; её подкатегории становятся верхнеуровневыми.
+	DeleteCategory(ctx context.Context, userID int64, id int) error
+	// MergeCategories переносит операции source в target, подкатегории source — к parentID
+	// и удаляет source в одной транзакции.
+	MergeCategories(ctx context.Context, userID int64, sourceID, targetID, parentID int) error
+}
+
+// Categories возвращает категории пользователя; typ "" — всех типов. Справочник заполняется при первом обращении.
+func (s *Service) Categories(ctx context.Context, userID int64, typ string) ([]Category, error) {
+	if err := s.repo.EnsureCategories(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListCategories(ctx, userID, typ)
+}
+
+// KeyboardCategories возвращает категории типа typ в порядке для клавиатуры: часто используемые первыми.
+func (s *Service) KeyboardCategories(ctx context.Context, userID int64, typ string) ([]Category, error) {
+	list, err := s.Categories(ctx, userID, typ)
+	if err != nil {
+		return nil, err
+	}
+	SortByUsage(list)
+	return list, nil
+}
+
+// CreateCategory создаёт категорию; parentID 0 — верхнеуровневую.
+func (s *Service) CreateCategory(ctx context.Context, userID int64, name, typ, icon string, parentID int) (*Category, error) {
+	name, err := NormalizeCategoryName(name)
+	if err != nil {
+		return nil, err
+	}
+	if typ != TypeIncome && typ != TypeExpense {
+		return nil, ErrCategoryType
+	}
+	if err := s.repo.EnsureCategories(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	c := &Category{UserID: userID, Name: name, Type: typ, Icon: strings.TrimSpace(icon), ParentID: parentID}
+	if parentID != 0 {
+		parent, err := s.repo.GetCategory(ctx, userID, parentID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkParent(c, parent, false); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.CreateCategory(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UpdateCategory переименовывает категорию, меняет её иконку или родителя.
+func (s *Service) UpdateCategory(ctx context.Context, userID int64, id int, u CategoryUpdate) (*Category, error) {
+	c, err := s.repo.GetCategory(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Name != nil {
+		if c.Name, err = NormalizeCategoryName(*u.Name); err != nil {
+			return nil, err
+		}
+	}
+	if u.Icon != nil {
+		c.Icon = strings.TrimSpace(*u.Icon)
+	}
+	if u.ParentID != nil && *u.ParentID != c.ParentID {
+		if *u.ParentID != 0 {
+			parent, err := s.repo.GetCategory(ctx, userID, *u.ParentID)
+			if err != nil {
+				return nil, err
+			}
+			hasChildren, err := s.repo.HasChildren(ctx, userID, id)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkParent(c, parent, hasChildren); err != nil {
+				return nil, err
+			}
+		}
+		c.ParentID = *u.ParentID
+	}
+
+	if err := s.repo.UpdateCategory(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DeleteCategory удаляет категорию без операций.
+func (s *Service) DeleteCategory(ctx context.Context, userID int64, id int) error {
+	return s.repo.DeleteCategory(ctx, userID, id)
+}
+
+// MergeCategories объединяет категорию sourceID с targetID: операции и подкатегории переходят в target,
+// source удаляется. Возвращает обновлённую target.
+func (s *Service) MergeCategories(ctx context.Context, userID int64, sourceID, targetID int) (*Category, error) {
+	source, err := s.repo.GetCategory(ctx, userID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.repo.GetCategory(ctx, userID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if source.ID == target.ID || source.Type != target.Type || target.ParentID == source.ID {
+		return nil, ErrCategoryMerge
+	}
+
+	if err := s.repo.MergeCategories(ctx, userID, source.ID, target.ID, mergeParent(target)); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCategory(ctx, userID, target.ID)
+}
+
+// resolveCategory приводит категорию операции к справочнику: имя сравнивается без учёта регистра
+// (и с иконкой, как на кнопке), неизвестное имя добавляется верхнеуровневой категорией.
+func (s *Service) resolveCategory(ctx context.Context, userID int64, typ, name string) (*Category, error) {
+	if err := s.repo.EnsureCategories(ctx, userID); err != nil {
+		return nil, err
+	}
+	name, err := NormalizeCategoryName(name)
+	if err != nil {
+		return nil, ErrInvalidEntry
+	}
+
+	list, err := s.repo.ListCategories(ctx, userID, typ)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := MatchCategory(list, name); ok {
+		return c, nil
+	}
+
+	c := &Category{UserID: userID, Name: name, Type: typ}
+	err = s.repo.CreateCategory(ctx, c)
+	if errors.Is(err, ErrCategoryExists) {
+		// категорию успели создать параллельно
+		return s.repo.FindCategory(ctx, userID, typ, name)
+	}
+	return c, err
+}
@@ -0,0 +1,111 @@
+package finance
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCategoryName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr error
+	}{
+		{name: "Продукты", want: "Продукты"},
+		{name: "  Кафе   и  рестораны ", want: "Кафе и рестораны"},
+		{name: "   ", wantErr: ErrCategoryName},
+		{name: strings.Repeat("я", 65), wantErr: ErrCategoryName},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeCategoryName(tt.name)
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("NormalizeCategoryName(%q) error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeCategoryName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchCategory(t *testing.T) {
+	list := []Category{
+		{ID: 1, Name: "Продукты", Icon: "🛒"},
+		{ID: 2, Name: "Такси", Icon: "🚕", ParentID: 3},
+		{ID: 4, Name: "Аптека"},
+	}
+	tests := []struct {
+		text   string
+		wantID int
+	}{
+		{text: "🛒 Продукты", wantID: 1},
+		{text: "продукты", wantID: 1},
+		{text: " ТАКСИ ", wantID: 2},
+		{text: "Аптека", wantID: 4},
+		{text: "Еда", wantID: 0},
+	}
+	for _, tt := range tests {
+		c, ok := MatchCategory(list, tt.text)
+		if tt.wantID == 0 {
+			if ok {
+				t.Errorf("MatchCategory(%q) = %d, want no match", tt.text, c.ID)
+			}
+			continue
+		}
+		if !ok || c.ID != tt.wantID {
+			t.Errorf("MatchCategory(%q) = %v, %v, want %d", tt.text, c, ok, tt.wantID)
+		}
+	}
+}
+
+func TestSortByUsage(t *testing.T) {
+	list := []Category{
+		{ID: 1, Name: "Одежда", Uses: 2},
+		{ID: 2, Name: "Продукты", Uses: 40},
+		{ID: 3, Name: "аптека", Uses: 2},
+		{ID: 4, Name: "Такси", Uses: 0},
+	}
+	SortByUsage(list)
+
+	want := []int{2, 3, 1, 4}
+	for i, c := range list {
+		if c.ID != want[i] {
+			t.Fatalf("SortByUsage order = %v, want IDs %v", list, want)
+		}
+	}
+}
+
+func TestCheckParent(t *testing.T) {
+	food := &Category{ID: 1, Type: TypeExpense}
+	taxi := &Category{ID: 2, Type: TypeExpense, ParentID: 5}
+	salary := &Category{ID: 3, Type: TypeIncome}
+
+	tests := []struct {
+		name        string
+		c, parent   *Category
+		hasChildren bool
+		wantErr     error
+	}{
+		{name: "верхнеуровневый родитель", c: &Category{ID: 10, Type: TypeExpense}, parent: food},
+		{name: "сама себе родитель", c: food, parent: food, wantErr: ErrCategoryParent},
+		{name: "другой тип", c: &Category{ID: 10, Type: TypeExpense}, parent: salary, wantErr: ErrCategoryParent},
+		{name: "третий уровень", c: &Category{ID: 10, Type: TypeExpense}, parent: taxi, wantErr: ErrCategoryParent},
+		{name: "у категории есть подкатегории", c: &Category{ID: 10, Type: TypeExpense}, parent: food, hasChildren: true, wantErr: ErrCategoryParent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkParent(tt.c, tt.parent, tt.hasChildren); !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkParent() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMergeParent(t *testing.T) {
+	if got := mergeParent(&Category{ID: 7}); got != 7 {
+		t.Errorf("mergeParent(top-level) = %d, want 7", got)
+	}
+	if got := mergeParent(&Category{ID: 7, ParentID: 3}); got != 3 {
+		t.Errorf("mergeParent(child) = %d, want 3", got)
+	}
+}
@@ -1,15 +1,25 @@
 package finance
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound возвращается, если запись не найдена или принадлежит другому пользователю.
+var ErrNotFound = errors.New("запись не найдена")
 
 type FinanceEntry struct {
 	ID        int
 	UserID    int64
 	Amount    float64
-	Category  string
+	Category  string // имя категории из справочника
 	Type      string
 	Note      string
-	CreatedAt time.Time
+	CreatedAt time.Time // время записи
+	// CategoryID — категория справочника (0 — операция внесена до появления справочника).
+	CategoryID int
+	// OccurredAt — когда операция совершена; по ней строятся списки и отчёты.
+	OccurredAt time.Time
 }
 
 type RecurringPayment struct {
@@ -20,5 +30,14 @@ type RecurringPayment struct {
 	Category    string
 	Period      string
 	NextPayment time.Time
+	PayDay      int // день месяца платежа; 0 — день NextPayment
 	CreatedAt   time.Time
 }
+
+// payDay возвращает день месяца, на который назначен платёж.
+func (p *RecurringPayment) payDay() int {
+	if p.PayDay > 0 {
+		return p.PayDay
+	}
+	return p.NextPayment.Day()
+}
@@ -0,0 +1,135 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Типы операций.
+const (
+	TypeIncome  = "income"
+	TypeExpense = "expense"
+)
+
+// Действия в истории изменений операций.
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// ErrInvalidEntry возвращается для операции с некорректной суммой, типом, без даты или с пустой категорией.
+var ErrInvalidEntry = errors.New("некорректная операция")
+
+// EntryPatch — изменения операции; nil-поля не меняются.
+type EntryPatch struct {
+	Amount     *float64
+	Category   *string
+	CategoryID *int
+	Type       *string
+	Note       *string
+	OccurredAt *time.Time
+}
+
+// Empty сообщает, что патч ничего не меняет.
+func (p EntryPatch) Empty() bool {
+	return p.Amount == nil && p.Category == nil && p.CategoryID == nil && p.Type == nil && p.Note == nil && p.OccurredAt == nil
+}
+
+// Apply применяет изменения к копии операции и проверяет результат.
+func (p EntryPatch) Apply(e FinanceEntry) (FinanceEntry, error) {
+	if p.Amount != nil {
+		e.Amount = *p.Amount
+	}
+	if p.Category != nil {
+		e.Category = strings.TrimSpace(*p.Category)
+	}
+	if p.CategoryID != nil {
+		e.CategoryID = *p.CategoryID
+	}
+	if p.Type != nil {
+		e.Type = *p.Type
+	}
+	if p.Note != nil {
+		e.Note = strings.TrimSpace(*p.Note)
+	}
+	if p.OccurredAt != nil {
+		e.OccurredAt = *p.OccurredAt
+	}
+	if err := ValidateEntry(&e); err != nil {
+		return FinanceEntry{}, err
+	}
+	return e, nil
+}
+
+// ValidateEntry проверяет сумму, тип, категорию и дату операции.
+func ValidateEntry(e *FinanceEntry) error {
+	if e.Amount <= 0 || e.Category == "" || (e.Type != TypeIncome && e.Type != TypeExpense) || e.OccurredAt.IsZero() {
+		return ErrInvalidEntry
+	}
+	return nil
+}
+
+// EntryChange — запись истории изменений операции. Before пуст для создания, After — для удаления.
+type EntryChange struct {
+	ID        int
+	EntryID   int
+	UserID    int64
+	Action    string
+	Before    *FinanceEntry
+	After     *FinanceEntry
+	ChangedAt time.Time
+}
+
+// historyRepository хранит изменения операций вместе с историей.
+type historyRepository interface {
+	// UpdateEntry применяет patch к операции пользователя и записывает изменение в историю в одной транзакции.
+	// Возвращает операцию до и после изменения (ErrNotFound, если операции нет).
+	UpdateEntry(ctx context.Context, userID int64, id int, patch EntryPatch) (before, after *FinanceEntry, err error)
+	// DeleteEntry удаляет операцию пользователя, сохраняя её последнее состояние в истории.
+	DeleteEntry(ctx context.Context, userID int64, id int) (*FinanceEntry, error)
+	// ListHistory возвращает историю изменений операций пользователя, новые сначала; entryID 0 — всех операций.
+	ListHistory(ctx context.Context, userID int64, entryID int) ([]EntryChange, error)
+}
+
+// UpdateEntry изменяет операцию пользователя. Новая категория или тип приводятся к справочнику категорий.
+func (s *Service) UpdateEntry(ctx context.Context, userID int64, id int, patch EntryPatch) (*FinanceEntry, error) {
+	if patch.Empty() {
+		return s.repo.GetEntry(ctx, userID, id)
+	}
+	if patch.Category != nil || patch.Type != nil {
+		current, err := s.repo.GetEntry(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		typ, name := current.Type, current.Category
+		if patch.Type != nil {
+			typ = *patch.Type
+		}
+		if patch.Category != nil {
+			name = *patch.Category
+		}
+		if typ != TypeIncome && typ != TypeExpense {
+			return nil, ErrInvalidEntry
+		}
+		c, err := s.resolveCategory(ctx, userID, typ, name)
+		if err != nil {
+			return nil, err
+		}
+		patch.Category, patch.CategoryID = &c.Name, &c.ID
+	}
+	_, after, err := s.repo.UpdateEntry(ctx, userID, id, patch)
+	return after, err
+}
+
+// DeleteEntry удаляет операцию пользователя и возвращает её.
+func (s *Service) DeleteEntry(ctx context.Context, userID int64, id int) (*FinanceEntry, error) {
+	return s.repo.DeleteEntry(ctx, userID, id)
+}
+
+// History возвращает историю изменений операций пользователя; entryID 0 — всех операций.
+func (s *Service) History(ctx context.Context, userID int64, entryID int) ([]EntryChange, error) {
+	return s.repo.ListHistory(ctx, userID, entryID)
+}
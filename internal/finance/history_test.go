@@ -0,0 +1,43 @@
+package finance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntryPatchApply(t *testing.T) {
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	base := FinanceEntry{ID: 1, Amount: 100, Category: "Еда", Type: TypeExpense, Note: "обед", OccurredAt: day}
+	amount := func(v float64) *float64 { return &v }
+	str := func(v string) *string { return &v }
+	yesterday := day.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name    string
+		patch   EntryPatch
+		want    FinanceEntry
+		wantErr bool
+	}{
+		{name: "сумма", patch: EntryPatch{Amount: amount(150)}, want: FinanceEntry{ID: 1, Amount: 150, Category: "Еда", Type: TypeExpense, Note: "обед", OccurredAt: day}},
+		{name: "категория и описание", patch: EntryPatch{Category: str(" Кафе "), Note: str("")}, want: FinanceEntry{ID: 1, Amount: 100, Category: "Кафе", Type: TypeExpense, OccurredAt: day}},
+		{name: "тип", patch: EntryPatch{Type: str(TypeIncome)}, want: FinanceEntry{ID: 1, Amount: 100, Category: "Еда", Type: TypeIncome, Note: "обед", OccurredAt: day}},
+		{name: "дата", patch: EntryPatch{OccurredAt: &yesterday}, want: FinanceEntry{ID: 1, Amount: 100, Category: "Еда", Type: TypeExpense, Note: "обед", OccurredAt: yesterday}},
+		{name: "нулевая сумма", patch: EntryPatch{Amount: amount(0)}, wantErr: true},
+		{name: "пустая категория", patch: EntryPatch{Category: str("  ")}, wantErr: true},
+		{name: "неизвестный тип", patch: EntryPatch{Type: str("transfer")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.patch.Apply(base)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Apply() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if base.Amount != 100 {
+		t.Errorf("Apply() modified the original entry")
+	}
+}
@@ -0,0 +1,85 @@
+package finance
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ошибки разбора даты операции.
+var (
+	ErrInvalidDate = errors.New("не удалось разобрать дату")
+	ErrFutureDate  = errors.New("дата операции в будущем")
+)
+
+// occurredHour — час, который подставляется для операций прошлых дней (середина дня
+// не даёт дате уехать на соседний день при смене часового пояса).
+const occurredHour = 12
+
+// relativeDays — слова для недавних дат.
+var relativeDays = map[string]int{"сегодня": 0, "вчера": 1, "позавчера": 2}
+
+// ParseOccurredDate разбирает дату операции относительно now (в часовом поясе now):
+// «сегодня», «вчера», «позавчера», «12.03», «12.03.2025», «12.03.25», «2025-03-12».
+// Дата без года, которая ещё не наступила, относится к прошлому году.
+// Для сегодняшнего дня возвращается now, для прошлых дней — полдень.
+func ParseOccurredDate(text string, now time.Time) (time.Time, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var day time.Time
+	if n, ok := relativeDays[text]; ok {
+		day = today.AddDate(0, 0, -n)
+	} else if d, err := time.ParseInLocation("2006-01-02", text, now.Location()); err == nil {
+		day = d
+	} else {
+		d, explicitYear, ok := parseDayMonth(text, now)
+		if !ok {
+			return time.Time{}, ErrInvalidDate
+		}
+		if !explicitYear && d.After(today) {
+			d = d.AddDate(-1, 0, 0)
+		}
+		day = d
+	}
+
+	switch {
+	case day.After(today):
+		return time.Time{}, ErrFutureDate
+	case day.Equal(today):
+		return now, nil
+	}
+	return day.Add(occurredHour * time.Hour), nil
+}
+
+// parseDayMonth разбирает «ДД.ММ», «ДД.ММ.ГГГГ» и «ДД.ММ.ГГ».
+func parseDayMonth(text string, now time.Time) (time.Time, bool, bool) {
+	parts := strings.Split(strings.TrimSuffix(text, "."), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return time.Time{}, false, false
+	}
+	day, err1 := strconv.Atoi(parts[0])
+	month, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || month < 1 || month > 12 {
+		return time.Time{}, false, false
+	}
+
+	year, explicitYear := now.Year(), false
+	if len(parts) == 3 {
+		y, err := strconv.Atoi(parts[2])
+		if err != nil || y < 0 {
+			return time.Time{}, false, false
+		}
+		if y < 100 {
+			y += 2000
+		}
+		year, explicitYear = y, true
+	}
+
+	d := time.Date(year, time.Month(month), day, 0, 0, 0, 0, now.Location())
+	if d.Day() != day {
+		return time.Time{}, false, false
+	}
+	return d, explicitYear, true
+}
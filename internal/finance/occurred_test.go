@@ -0,0 +1,45 @@
+package finance
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseOccurredDate(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	now := time.Date(2026, 3, 10, 18, 30, 0, 0, loc)
+	noon := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 12, 0, 0, 0, loc) }
+
+	tests := []struct {
+		text    string
+		want    time.Time
+		wantErr error
+	}{
+		{text: "сегодня", want: now},
+		{text: "Вчера", want: noon(2026, 3, 9)},
+		{text: "позавчера", want: noon(2026, 3, 8)},
+		{text: "10.03", want: now},
+		{text: "01.03", want: noon(2026, 3, 1)},
+		{text: "12.03", want: noon(2025, 3, 12)}, // ещё не наступило — прошлый год
+		{text: "12.03.2025", want: noon(2025, 3, 12)},
+		{text: "12.03.25", want: noon(2025, 3, 12)},
+		{text: "2026-02-28", want: noon(2026, 2, 28)},
+		{text: "12.03.2026", wantErr: ErrFutureDate},
+		{text: "2026-04-01", wantErr: ErrFutureDate},
+		{text: "31.02", wantErr: ErrInvalidDate},
+		{text: "завтра", wantErr: ErrInvalidDate},
+		{text: "", wantErr: ErrInvalidDate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseOccurredDate(tt.text, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseOccurredDate(%q) error = %v, want %v", tt.text, err, tt.wantErr)
+			}
+			if err == nil && !got.Equal(tt.want) {
+				t.Errorf("ParseOccurredDate(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -19,18 +19,19 @@ func NewRecurringRepo(db *pgxpool.Pool) *RecurringRepo {
 func (r *RecurringRepo) Add(ctx context.Context, p *RecurringPayment) (int, error) {
 	var id int
 	err := r.db.QueryRow(ctx, `
-        INSERT INTO recurring_payments (user_id, title, amount, category, period, next_payment, created_at)
-        VALUES ($1,$2,$3,$4,$5,$6,$7)
+        INSERT INTO recurring_payments (user_id, title, amount, category, period, next_payment, created_at, pay_day)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
         RETURNING id
     `,
-		p.UserID, p.Title, p.Amount, p.Category, p.Period, p.NextPayment, time.Now(),
+		p.UserID, p.Title, p.Amount, p.Category, p.Period, p.NextPayment, time.Now(), p.payDay(),
 	).Scan(&id)
 	return id, err
 }
 
 func (r *RecurringRepo) List(ctx context.Context, userID int64) ([]RecurringPayment, error) {
 	rows, err := r.db.Query(ctx, `
-        SELECT id, user_id, title, amount, category, period, next_payment, created_at
+        SELECT id, user_id, title, amount, category, period, next_payment, created_at,
+               COALESCE(pay_day, EXTRACT(DAY FROM next_payment)::int)
         FROM recurring_payments
         WHERE user_id=$1
         ORDER BY next_payment
@@ -45,15 +46,22 @@ func (r *RecurringRepo) List(ctx context.Context, userID int64) ([]RecurringPaym
 	var list []RecurringPayment
 	for rows.Next() {
 		var p RecurringPayment
-		rows.Scan(&p.ID, &p.UserID, &p.Title, &p.Amount, &p.Category, &p.Period, &p.NextPayment, &p.CreatedAt)
+		rows.Scan(&p.ID, &p.UserID, &p.Title, &p.Amount, &p.Category, &p.Period, &p.NextPayment, &p.CreatedAt, &p.PayDay)
 		list = append(list, p)
 	}
 	return list, nil
 }
 
-func (r *RecurringRepo) Delete(ctx context.Context, id int) error {
-	_, err := r.db.Exec(ctx, `DELETE FROM recurring_payments WHERE id=$1`, id)
-	return err
+// Delete удаляет регулярный платёж, если он принадлежит пользователю.
+func (r *RecurringRepo) Delete(ctx context.Context, id int, userID int64) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM recurring_payments WHERE id=$1 AND user_id=$2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
 func (r *RecurringRepo) UpdateNextPayment(ctx context.Context, id int, next time.Time) error {
@@ -70,14 +78,16 @@ func (r *RecurringRepo) GetUserPayments(ctx context.Context, userID int64) ([]*R
 	if userID == 0 {
 		// Для scheduler: получаем все платежи
 		rows, err = r.db.Query(ctx, `
-			SELECT id, user_id, title, amount, category, period, next_payment, created_at
+			SELECT id, user_id, title, amount, category, period, next_payment, created_at,
+			       COALESCE(pay_day, EXTRACT(DAY FROM next_payment)::int)
 			FROM recurring_payments
 			ORDER BY next_payment
 		`)
 	} else {
 		// Для конкретного пользователя
 		rows, err = r.db.Query(ctx, `
-			SELECT id, user_id, title, amount, category, period, next_payment, created_at
+			SELECT id, user_id, title, amount, category, period, next_payment, created_at,
+			       COALESCE(pay_day, EXTRACT(DAY FROM next_payment)::int)
 			FROM recurring_payments
 			WHERE user_id=$1
 			ORDER BY next_payment
@@ -93,7 +103,7 @@ func (r *RecurringRepo) GetUserPayments(ctx context.Context, userID int64) ([]*R
 
 	for rows.Next() {
 		var p RecurringPayment
-		if err := rows.Scan(&p.ID, &p.UserID, &p.Title, &p.Amount, &p.Category, &p.Period, &p.NextPayment, &p.CreatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.UserID, &p.Title, &p.Amount, &p.Category, &p.Period, &p.NextPayment, &p.CreatedAt, &p.PayDay); err != nil {
 			return nil, err
 		}
 		list = append(list, &p)
@@ -0,0 +1,289 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrInvalidPeriod возвращается для неизвестного вида периода или пустого интервала.
+var ErrInvalidPeriod = errors.New("некорректный период отчёта")
+
+// TopExpensesLimit — сколько крупнейших расходов показывать в отчёте.
+const TopExpensesLimit = 5
+
+// MaxCustomPeriodDays — наибольшая длина произвольного периода отчёта в днях.
+const MaxCustomPeriodDays = 366
+
+// PeriodKind — вид периода отчёта.
+type PeriodKind string
+
+const (
+	PeriodDay    PeriodKind = "day"
+	PeriodWeek   PeriodKind = "week" // с понедельника
+	PeriodMonth  PeriodKind = "month"
+	PeriodYear   PeriodKind = "year" // текущий год — с начала года по сегодня
+	PeriodCustom PeriodKind = "custom"
+)
+
+// PeriodKinds — виды периодов с навигацией, в порядке кнопок.
+var PeriodKinds = []PeriodKind{PeriodDay, PeriodWeek, PeriodMonth, PeriodYear}
+
+// Period — интервал отчёта [From, To).
+type Period struct {
+	Kind PeriodKind `json:"kind"`
+	From time.Time  `json:"from"`
+	To   time.Time  `json:"to"`
+}
+
+// startOfDay — полночь дня t в часовом поясе t.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// NewPeriod возвращает период вида kind, в который попадает anchor (в часовом поясе anchor).
+// Текущий год (по now) заканчивается сегодняшним днём, прошлые годы — полные.
+func NewPeriod(kind PeriodKind, anchor, now time.Time) (Period, error) {
+	day := startOfDay(anchor)
+	p := Period{Kind: kind}
+	switch kind {
+	case PeriodDay:
+		p.From, p.To = day, day.AddDate(0, 0, 1)
+	case PeriodWeek:
+		offset := (int(day.Weekday()) + 6) % 7 // понедельник — 0
+		p.From = day.AddDate(0, 0, -offset)
+		p.To = p.From.AddDate(0, 0, 7)
+	case PeriodMonth:
+		p.From, p.To = MonthRange(day)
+	case PeriodYear:
+		p.From = time.Date(day.Year(), 1, 1, 0, 0, 0, 0, day.Location())
+		p.To = p.From.AddDate(1, 0, 0)
+		if today := startOfDay(now.In(day.Location())); today.Year() == day.Year() {
+			p.To = today.AddDate(0, 0, 1)
+		}
+	default:
+		return Period{}, ErrInvalidPeriod
+	}
+	return p, nil
+}
+
+// CustomPeriod возвращает произвольный период с from по to включительно (по дням).
+// Период длиннее MaxCustomPeriodDays дней считается некорректным.
+func CustomPeriod(from, to time.Time) (Period, error) {
+	p := Period{Kind: PeriodCustom, From: startOfDay(from), To: startOfDay(to).AddDate(0, 0, 1)}
+	if !p.From.Before(p.To) || p.Days() > MaxCustomPeriodDays {
+		return Period{}, ErrInvalidPeriod
+	}
+	return p, nil
+}
+
+// Shift возвращает соседний период того же вида: n = -1 — предыдущий, 1 — следующий.
+// Произвольный период сдвигается на свою длину в днях.
+func (p Period) Shift(n int, now time.Time) Period {
+	switch p.Kind {
+	case PeriodDay:
+		p, _ = NewPeriod(p.Kind, p.From.AddDate(0, 0, n), now)
+	case PeriodWeek:
+		p, _ = NewPeriod(p.Kind, p.From.AddDate(0, 0, 7*n), now)
+	case PeriodMonth:
+		p, _ = NewPeriod(p.Kind, p.From.AddDate(0, n, 0), now)
+	case PeriodYear:
+		p, _ = NewPeriod(p.Kind, p.From.AddDate(n, 0, 0), now)
+	default:
+		days := p.Days()
+		p.From, p.To = p.From.AddDate(0, 0, n*days), p.To.AddDate(0, 0, n*days)
+	}
+	return p
+}
+
+// Days — длина периода в календарных днях. Считается по датам границ в UTC,
+// чтобы переход на летнее время не укорачивал и не удлинял сутки.
+func (p Period) Days() int {
+	from := time.Date(p.From.Year(), p.From.Month(), p.From.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(p.To.Year(), p.To.Month(), p.To.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from) / (24 * time.Hour))
+}
+
+// Previous — период для сравнения: предыдущий период того же вида; для года — тот же отрезок
+// прошлого года (с начала года по ту же дату), чтобы неполный текущий год сравнивался честно.
+func (p Period) Previous() Period {
+	if p.Kind == PeriodYear {
+		return Period{Kind: p.Kind, From: p.From.AddDate(-1, 0, 0), To: p.To.AddDate(-1, 0, 0)}
+	}
+	return p.Shift(-1, p.From)
+}
+
+// CategoryTotal — сумма операций одного типа по категории за период.
+// Подкатегории учитываются в родительской категории.
+type CategoryTotal struct {
+	CategoryID int     `json:"category_id,omitempty"`
+	Category   string  `json:"category"`
+	Icon       string  `json:"icon,omitempty"`
+	Type       string  `json:"type"`
+	Amount     float64 `json:"amount"`
+	Count      int     `json:"count"`
+	Share      float64 `json:"share"`       // доля от всех операций этого типа, %
+	PrevAmount float64 `json:"prev_amount"` // сумма за период сравнения
+}
+
+// ReportEntry — операция в списке крупнейших расходов.
+type ReportEntry struct {
+	ID         int       `json:"id"`
+	Amount     float64   `json:"amount"`
+	Category   string    `json:"category"`
+	Note       string    `json:"note,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Totals — итоги за период.
+type Totals struct {
+	Income  float64 `json:"income"`
+	Expense float64 `json:"expense"`
+	Count   int     `json:"count"`
+}
+
+// Balance — доходы минус расходы.
+func (t Totals) Balance() float64 {
+	return t.Income - t.Expense
+}
+
+// Report — отчёт за период со сравнением с предыдущим периодом.
+type Report struct {
+	Period     Period          `json:"period"`
+	Previous   Period          `json:"previous"`
+	Totals     Totals          `json:"totals"`
+	PrevTotals Totals          `json:"prev_totals"`
+	Expenses   []CategoryTotal `json:"expenses"` // по убыванию суммы
+	Incomes    []CategoryTotal `json:"incomes"`  // по убыванию суммы
+	Top        []ReportEntry   `json:"top_expenses"`
+}
+
+// Change — изменение суммы относительно прошлого периода в процентах; ok = false, если в прошлом периоде было 0.
+func Change(cur, prev float64) (float64, bool) {
+	if prev == 0 {
+		return 0, false
+	}
+	return (cur - prev) / prev * 100, true
+}
+
+// sumTotals складывает суммы категорий в итоги.
+func sumTotals(list []CategoryTotal) Totals {
+	var t Totals
+	for _, c := range list {
+		if c.Type == TypeIncome {
+			t.Income += c.Amount
+		} else {
+			t.Expense += c.Amount
+		}
+		t.Count += c.Count
+	}
+	return t
+}
+
+// categoryKey — ключ для сопоставления категорий текущего и прошлого периода.
+func categoryKey(c CategoryTotal) [3]any {
+	return [3]any{c.Type, c.CategoryID, c.Category}
+}
+
+// BuildReport собирает отчёт из сумм по категориям за период (cur) и период сравнения (prev).
+// Категории, по которым были операции только в прошлом периоде, в разбивку не попадают.
+func BuildReport(period, previous Period, cur, prev []CategoryTotal, top []ReportEntry) Report {
+	r := Report{
+		Period:     period,
+		Previous:   previous,
+		Totals:     sumTotals(cur),
+		PrevTotals: sumTotals(prev),
+		Top:        top,
+	}
+
+	prevByKey := make(map[[3]any]float64, len(prev))
+	for _, c := range prev {
+		prevByKey[categoryKey(c)] += c.Amount
+	}
+	for _, c := range cur {
+		c.PrevAmount = prevByKey[categoryKey(c)]
+		if c.Type == TypeIncome {
+			c.Share = share(c.Amount, r.Totals.Income)
+			r.Incomes = append(r.Incomes, c)
+		} else {
+			c.Share = share(c.Amount, r.Totals.Expense)
+			r.Expenses = append(r.Expenses, c)
+		}
+	}
+	sortTotals(r.Expenses)
+	sortTotals(r.Incomes)
+	return r
+}
+
+func share(part, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return part / total * 100
+}
+
+// sortTotals упорядочивает категории по убыванию суммы, при равенстве — по имени.
+func sortTotals(list []CategoryTotal) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Amount != list[j].Amount {
+			return list[i].Amount > list[j].Amount
+		}
+		return list[i].Category < list[j].Category
+	})
+}
+
+// ReportRepository агрегирует операции для отчётов.
+type ReportRepository interface {
+	// CategoryTotals возвращает суммы и число операций за [from, to) по типу и категории верхнего уровня.
+	CategoryTotals(ctx context.Context, userID int64, from, to time.Time) ([]CategoryTotal, error)
+	// TopExpenses возвращает до limit крупнейших расходов за [from, to).
+	TopExpenses(ctx context.Context, userID int64, from, to time.Time, limit int) ([]ReportEntry, error)
+	// ListEntriesPage возвращает страницу операций пользователя, новые сначала.
+	ListEntriesPage(ctx context.Context, userID int64, limit, offset int) ([]*FinanceEntry, error)
+	// EntryTotals возвращает итоги пользователя за всё время.
+	EntryTotals(ctx context.Context, userID int64) (Totals, error)
+}
+
+// Report строит отчёт за период со сравнением с предыдущим периодом и крупнейшими расходами.
+func (s *Service) Report(ctx context.Context, userID int64, period Period) (*Report, error) {
+	if !period.From.Before(period.To) {
+		return nil, ErrInvalidPeriod
+	}
+	previous := period.Previous()
+
+	cur, err := s.repo.CategoryTotals(ctx, userID, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := s.repo.CategoryTotals(ctx, userID, previous.From, previous.To)
+	if err != nil {
+		return nil, err
+	}
+	top, err := s.repo.TopExpenses(ctx, userID, period.From, period.To, TopExpensesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	r := BuildReport(period, previous, cur, prev, top)
+	return &r, nil
+}
+
+// Totals возвращает итоги пользователя за [from, to).
+func (s *Service) Totals(ctx context.Context, userID int64, from, to time.Time) (Totals, error) {
+	list, err := s.repo.CategoryTotals(ctx, userID, from, to)
+	if err != nil {
+		return Totals{}, err
+	}
+	return sumTotals(list), nil
+}
+
+// AllTimeTotals возвращает итоги пользователя за всё время.
+func (s *Service) AllTimeTotals(ctx context.Context, userID int64) (Totals, error) {
+	return s.repo.EntryTotals(ctx, userID)
+}
+
+// EntriesPage возвращает страницу операций пользователя, новые сначала.
+func (s *Service) EntriesPage(ctx context.Context, userID int64, limit, offset int) ([]*FinanceEntry, error) {
+	return s.repo.ListEntriesPage(ctx, userID, limit, offset)
+}
@@ -0,0 +1,141 @@
+package finance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewPeriod(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	now := time.Date(2026, 10, 17, 15, 0, 0, 0, loc) // суббота
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, loc) }
+
+	tests := []struct {
+		name     string
+		kind     PeriodKind
+		anchor   time.Time
+		from, to time.Time
+	}{
+		{name: "день", kind: PeriodDay, anchor: now, from: day(2026, 10, 17), to: day(2026, 10, 18)},
+		{name: "неделя с понедельника", kind: PeriodWeek, anchor: now, from: day(2026, 10, 12), to: day(2026, 10, 19)},
+		{name: "неделя в воскресенье", kind: PeriodWeek, anchor: day(2026, 10, 18), from: day(2026, 10, 12), to: day(2026, 10, 19)},
+		{name: "месяц", kind: PeriodMonth, anchor: now, from: day(2026, 10, 1), to: day(2026, 11, 1)},
+		{name: "текущий год по сегодня", kind: PeriodYear, anchor: now, from: day(2026, 1, 1), to: day(2026, 10, 18)},
+		{name: "прошлый год целиком", kind: PeriodYear, anchor: day(2025, 5, 5), from: day(2025, 1, 1), to: day(2026, 1, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPeriod(tt.kind, tt.anchor, now)
+			if err != nil {
+				t.Fatalf("NewPeriod: %v", err)
+			}
+			if !p.From.Equal(tt.from) || !p.To.Equal(tt.to) {
+				t.Errorf("got [%v, %v), want [%v, %v)", p.From, p.To, tt.from, tt.to)
+			}
+		})
+	}
+
+	if _, err := NewPeriod("decade", now, now); err != ErrInvalidPeriod {
+		t.Errorf("unknown kind: err = %v, want ErrInvalidPeriod", err)
+	}
+}
+
+func TestPeriodShiftAndPrevious(t *testing.T) {
+	now := time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	month, _ := NewPeriod(PeriodMonth, day(2026, 3, 31), now)
+	if prev := month.Shift(-1, now); !prev.From.Equal(day(2026, 2, 1)) || !prev.To.Equal(day(2026, 3, 1)) {
+		t.Errorf("month.Shift(-1) = [%v, %v)", prev.From, prev.To)
+	}
+
+	// Прошлый год, сдвинутый вперёд, становится текущим неполным годом
+	year, _ := NewPeriod(PeriodYear, day(2025, 6, 1), now)
+	if next := year.Shift(1, now); !next.From.Equal(day(2026, 1, 1)) || !next.To.Equal(day(2026, 10, 18)) {
+		t.Errorf("year.Shift(1) = [%v, %v)", next.From, next.To)
+	}
+
+	// Неполный год сравнивается с тем же отрезком прошлого года
+	ytd, _ := NewPeriod(PeriodYear, now, now)
+	if prev := ytd.Previous(); !prev.From.Equal(day(2025, 1, 1)) || !prev.To.Equal(day(2025, 10, 18)) {
+		t.Errorf("ytd.Previous() = [%v, %v)", prev.From, prev.To)
+	}
+
+	custom, err := CustomPeriod(day(2026, 3, 1), day(2026, 3, 15))
+	if err != nil {
+		t.Fatalf("CustomPeriod: %v", err)
+	}
+	if custom.Days() != 15 {
+		t.Errorf("custom.Days() = %d, want 15", custom.Days())
+	}
+	if prev := custom.Previous(); !prev.From.Equal(day(2026, 2, 14)) || !prev.To.Equal(day(2026, 3, 1)) {
+		t.Errorf("custom.Previous() = [%v, %v)", prev.From, prev.To)
+	}
+
+	if _, err := CustomPeriod(day(2026, 3, 15), day(2026, 3, 1)); err != ErrInvalidPeriod {
+		t.Errorf("reversed range: err = %v, want ErrInvalidPeriod", err)
+	}
+	if _, err := CustomPeriod(day(2026, 1, 1), day(2026, 12, 31)); err != nil {
+		t.Errorf("full year: err = %v, want nil", err)
+	}
+	if _, err := CustomPeriod(day(1, 1, 1), day(2026, 3, 1)); err != ErrInvalidPeriod {
+		t.Errorf("too long range: err = %v, want ErrInvalidPeriod", err)
+	}
+
+	msk := time.FixedZone("MSK", 3*60*60)
+	long := Period{From: time.Date(2024, 1, 1, 0, 0, 0, 0, msk), To: time.Date(2025, 1, 1, 0, 0, 0, 0, msk)}
+	if long.Days() != 366 {
+		t.Errorf("leap year Days() = %d, want 366", long.Days())
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	cur := []CategoryTotal{
+		{CategoryID: 1, Category: "Кафе", Type: TypeExpense, Amount: 250, Count: 3},
+		{CategoryID: 2, Category: "Продукты", Type: TypeExpense, Amount: 750, Count: 5},
+		{CategoryID: 3, Category: "Зарплата", Type: TypeIncome, Amount: 5000, Count: 1},
+	}
+	prev := []CategoryTotal{
+		{CategoryID: 2, Category: "Продукты", Type: TypeExpense, Amount: 500, Count: 4},
+		{CategoryID: 4, Category: "Такси", Type: TypeExpense, Amount: 300, Count: 2},
+	}
+	r := BuildReport(Period{}, Period{}, cur, prev, nil)
+
+	if r.Totals != (Totals{Income: 5000, Expense: 1000, Count: 9}) {
+		t.Errorf("Totals = %+v", r.Totals)
+	}
+	if r.PrevTotals != (Totals{Expense: 800, Count: 6}) {
+		t.Errorf("PrevTotals = %+v", r.PrevTotals)
+	}
+	if len(r.Expenses) != 2 || r.Expenses[0].Category != "Продукты" || r.Expenses[1].Category != "Кафе" {
+		t.Fatalf("Expenses = %+v", r.Expenses)
+	}
+	if r.Expenses[0].Share != 75 || r.Expenses[0].PrevAmount != 500 {
+		t.Errorf("Продукты: share=%v prev=%v, want 75 500", r.Expenses[0].Share, r.Expenses[0].PrevAmount)
+	}
+	if r.Expenses[1].PrevAmount != 0 {
+		t.Errorf("Кафе: prev=%v, want 0", r.Expenses[1].PrevAmount)
+	}
+	if len(r.Incomes) != 1 || r.Incomes[0].Share != 100 {
+		t.Errorf("Incomes = %+v", r.Incomes)
+	}
+}
+
+func TestChange(t *testing.T) {
+	tests := []struct {
+		cur, prev float64
+		want      float64
+		wantOK    bool
+	}{
+		{cur: 150, prev: 100, want: 50, wantOK: true},
+		{cur: 50, prev: 100, want: -50, wantOK: true},
+		{cur: 100, prev: 100, want: 0, wantOK: true},
+		{cur: 100, prev: 0, wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := Change(tt.cur, tt.prev)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("Change(%v, %v) = %v, %v, want %v, %v", tt.cur, tt.prev, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
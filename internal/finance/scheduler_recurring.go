@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/recurrence"
 	"tg_bot_asist/internal/todo"
 )
 
@@ -55,7 +56,7 @@ func (s *RecurringScheduler) RunDailyCheck(ctx context.Context) {
 			}
 
 			// 2. Обновляем дату следующего платежа
-			nextPayment := calcNextRecurringDate(payment)
+			nextPayment := NextRecurringDate(payment)
 			if err := s.repo.UpdateNextPayment(ctx, payment.ID, nextPayment); err != nil {
 				logger.Error("Failed to update next payment date: " + err.Error())
 				continue
@@ -79,16 +80,31 @@ func (s *RecurringScheduler) RunDailyCheck(ctx context.Context) {
 	}
 }
 
-func calcNextRecurringDate(p *RecurringPayment) time.Time {
-	switch p.Period {
-	case "daily":
-		return p.NextPayment.AddDate(0, 0, 1)
-	case "weekly":
-		return p.NextPayment.AddDate(0, 0, 7)
-	case "monthly":
-		return p.NextPayment.AddDate(0, 1, 0)
-	case "yearly":
-		return p.NextPayment.AddDate(1, 0, 0)
+// NextRecurringDate возвращает дату платежа, следующего за p.NextPayment.
+// Неизвестный период считается ежемесячным. Ежемесячный и ежегодный платёж, день которого
+// в месяце отсутствует (31-е, 29 февраля), приходится на последний день месяца, а не пропускается,
+// как в RFC 5545; следующий платёж снова назначается на p.PayDay.
+func NextRecurringDate(p *RecurringPayment) time.Time {
+	rule, err := recurrence.FromPeriod(p.Period)
+	if err != nil {
+		rule = recurrence.Rule{Freq: recurrence.Monthly}
+	}
+	switch rule.Freq {
+	case recurrence.Monthly:
+		return addMonthsClamped(p.NextPayment, 1, p.payDay())
+	case recurrence.Yearly:
+		return addMonthsClamped(p.NextPayment, 12, p.payDay())
+	}
+	if next, ok := rule.Next(p.NextPayment, p.NextPayment); ok {
+		return next
 	}
 	return p.NextPayment.AddDate(0, 1, 0)
 }
+
+// addMonthsClamped возвращает дату через months месяцев после t на день day,
+// а если в том месяце меньше дней — на его последний день.
+func addMonthsClamped(t time.Time, months, day int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, last)-1)
+}
@@ -0,0 +1,36 @@
+package finance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextRecurringDate(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name   string
+		period string
+		next   time.Time
+		payDay int
+		want   time.Time
+	}{
+		{name: "31-е в апреле — последний день", period: "monthly", next: day(2026, 3, 31), payDay: 31, want: day(2026, 4, 30)},
+		{name: "после короткого месяца снова 31-е", period: "monthly", next: day(2026, 4, 30), payDay: 31, want: day(2026, 5, 31)},
+		{name: "30-е в феврале", period: "monthly", next: day(2026, 1, 30), payDay: 30, want: day(2026, 2, 28)},
+		{name: "после февраля снова 30-е", period: "monthly", next: day(2026, 2, 28), payDay: 30, want: day(2026, 3, 30)},
+		{name: "день не задан — день текущего платежа", period: "monthly", next: day(2026, 12, 15), want: day(2027, 1, 15)},
+		{name: "29 февраля в невисокосный год", period: "yearly", next: day(2028, 2, 29), payDay: 29, want: day(2029, 2, 28)},
+		{name: "29 февраля возвращается в високосный год", period: "yearly", next: day(2031, 2, 28), payDay: 29, want: day(2032, 2, 29)},
+		{name: "еженедельно", period: "weekly", next: day(2026, 10, 17), want: day(2026, 10, 24)},
+		{name: "неизвестный период — ежемесячно", period: "sometimes", next: day(2026, 1, 31), payDay: 31, want: day(2026, 2, 28)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &RecurringPayment{Period: tt.period, NextPayment: tt.next, PayDay: tt.payDay}
+			if got := NextRecurringDate(p); !got.Equal(tt.want) {
+				t.Errorf("NextRecurringDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,19 +2,30 @@ package finance
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
 // repository определяет интерфейс для работы с финансовыми записями.
 type repository interface {
+	historyRepository
+	CategoryRepository
+	BudgetRepository
+	ReportRepository
+
 	AddEntry(context.Context, *FinanceEntry) error
 	ListEntries(context.Context, int64) ([]*FinanceEntry, error)
+	GetEntry(ctx context.Context, userID int64, id int) (*FinanceEntry, error)
 }
 
 // Service предоставляет бизнес-логику для работы с финансами и регулярными платежами.
 type Service struct {
 	repo          repository
 	recurringRepo *RecurringRepo
+
+	users    LocationProvider
+	notifier Notifier       // уведомления о бюджетах в Telegram
+	events   EventPublisher // события websocket
 }
 
 // NewService создаёт новый экземпляр сервиса финансов.
@@ -23,8 +34,26 @@ func NewService(repo repository, recurring *RecurringRepo) *Service {
 }
 
 // AddEntry добавляет новую финансовую запись (доход или расход).
+// Если дата операции не указана, операция считается совершённой сейчас.
+// Категория приводится к справочнику: «еда» попадёт в существующую «Еда», новое имя станет новой категорией.
+// Если расход переходит 80% или 100% бюджета, пользователь получает уведомление (см. SetBudgetAlerts).
 func (s *Service) AddEntry(ctx context.Context, e *FinanceEntry) error {
-	return s.repo.AddEntry(ctx, e)
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	if err := ValidateEntry(e); err != nil {
+		return err
+	}
+	c, err := s.resolveCategory(ctx, e.UserID, e.Type, e.Category)
+	if err != nil {
+		return err
+	}
+	e.Category, e.CategoryID = c.Name, c.ID
+	if err := s.repo.AddEntry(ctx, e); err != nil {
+		return err
+	}
+	s.checkBudgets(ctx, e)
+	return nil
 }
 
 // ListEntries возвращает список всех финансовых записей пользователя.
@@ -32,14 +61,19 @@ func (s *Service) ListEntries(ctx context.Context, userID int64) ([]*FinanceEntr
 	return s.repo.ListEntries(ctx, userID)
 }
 
+// GetEntry возвращает операцию пользователя по ID (ErrNotFound, если её нет).
+func (s *Service) GetEntry(ctx context.Context, userID int64, id int) (*FinanceEntry, error) {
+	return s.repo.GetEntry(ctx, userID, id)
+}
+
 // AddRecurring добавляет новый регулярный платёж.
 func (s *Service) AddRecurring(ctx context.Context, p *RecurringPayment) (int, error) {
 	return s.recurringRepo.Add(ctx, p)
 }
 
-// DeleteRecurring удаляет регулярный платёж по ID.
-func (s *Service) DeleteRecurring(ctx context.Context, id int) error {
-	return s.recurringRepo.Delete(ctx, id)
+// DeleteRecurring удаляет регулярный платёж по ID, если он принадлежит пользователю.
+func (s *Service) DeleteRecurring(ctx context.Context, userID int64, id int) error {
+	return s.recurringRepo.Delete(ctx, id, userID)
 }
 
 // GetRecurringList возвращает список всех регулярных платежей пользователя.
@@ -53,13 +87,19 @@ func (s *Service) GetRecurringList(ctx context.Context, userID int64) ([]*Recurr
 
 // AddRecurringExecution создаёт финансовую запись из выполненного регулярного платежа.
 func (s *Service) AddRecurringExecution(ctx context.Context, p *RecurringPayment) error {
+	category := p.Category
+	if strings.TrimSpace(category) == "" {
+		category = RecurringCategory
+	}
 	entry := &FinanceEntry{
 		UserID:    p.UserID,
 		Amount:    p.Amount,
-		Category:  p.Category,
+		Category:  category,
 		Type:      "expense",
 		Note:      "Регулярный платёж: " + p.Title,
 		CreatedAt: time.Now(),
+		// платёж, обработанный с опозданием, относится к дню, на который он был запланирован
+		OccurredAt: p.NextPayment,
 	}
-	return s.repo.AddEntry(ctx, entry)
+	return s.AddEntry(ctx, entry)
 }
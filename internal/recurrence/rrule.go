@@ -0,0 +1,442 @@
+// Package recurrence вычисляет даты повторяющихся событий по правилам RRULE (RFC 5545).
+//
+// Поддерживается подмножество RFC 5545:
+//   - FREQ=DAILY|WEEKLY|MONTHLY|YEARLY (обязательно);
+//   - INTERVAL=N;
+//   - BYDAY=MO,TU,... с порядковым номером для MONTHLY/YEARLY (2TU — второй вторник, -1FR — последняя пятница месяца);
+//   - BYMONTHDAY=1..31 и -1..-31 (от конца месяца);
+//   - BYMONTH=1..12;
+//   - COUNT=N или UNTIL=YYYYMMDD[THHMMSS[Z]].
+//
+// Неделя начинается с понедельника (WKST=MO). Время события берётся из даты начала серии.
+// Для YEARLY порядковый номер в BYDAY считается внутри месяца.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency — базовая частота повторения.
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum — день недели с необязательным порядковым номером внутри месяца.
+// N == 0 означает «каждый такой день», N < 0 — отсчёт от конца месяца.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule — разобранное правило повторения.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	Count      int
+	Until      *time.Time
+}
+
+// ErrInvalid возвращается для некорректных или неподдерживаемых правил.
+var ErrInvalid = errors.New("некорректное правило повторения")
+
+// maxPeriods ограничивает перебор периодов при поиске следующего события
+// (правило вроде 29 февраля по понедельникам может не срабатывать годами).
+const maxPeriods = 2000
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// Parse разбирает строку правила, например "FREQ=MONTHLY;BYDAY=2TU".
+// Префикс "RRULE:" допускается.
+func Parse(s string) (Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(strings.ToUpper(s)), "RRULE:")
+	if s == "" {
+		return Rule{}, fmt.Errorf("%w: пустое правило", ErrInvalid)
+	}
+
+	r := Rule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("%w: %q", ErrInvalid, part)
+		}
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(value)
+			switch r.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				err = fmt.Errorf("частота %s не поддерживается", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err == nil && r.Interval < 1 {
+				err = errors.New("INTERVAL должен быть положительным")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err == nil && r.Count < 1 {
+				err = errors.New("COUNT должен быть положительным")
+			}
+		case "UNTIL":
+			var until time.Time
+			until, err = parseUntil(value)
+			r.Until = &until
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseInts(value, -31, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseInts(value, 1, 12)
+			for _, m := range months {
+				r.ByMonth = append(r.ByMonth, time.Month(m))
+			}
+		case "WKST":
+			if value != "MO" {
+				err = errors.New("поддерживается только WKST=MO")
+			}
+		default:
+			err = fmt.Errorf("параметр %s не поддерживается", key)
+		}
+		if err != nil {
+			return Rule{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+	}
+
+	if r.Freq == "" {
+		return Rule{}, fmt.Errorf("%w: не указан FREQ", ErrInvalid)
+	}
+	if r.Count > 0 && r.Until != nil {
+		return Rule{}, fmt.Errorf("%w: COUNT и UNTIL нельзя указывать вместе", ErrInvalid)
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return Rule{}, fmt.Errorf("%w: номер дня в BYDAY допустим только для MONTHLY и YEARLY", ErrInvalid)
+		}
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == Weekly {
+		return Rule{}, fmt.Errorf("%w: BYMONTHDAY не используется с WEEKLY", ErrInvalid)
+	}
+	return r, nil
+}
+
+func parseUntil(v string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			if layout == "20060102" {
+				// дата без времени — включительно до конца дня
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("неверный UNTIL %q", v)
+}
+
+func parseByDay(v string) ([]WeekdayNum, error) {
+	var out []WeekdayNum
+	for _, item := range strings.Split(v, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("неверный день %q", item)
+		}
+		code := item[len(item)-2:]
+		day, ok := weekdayCodes[code]
+		if !ok {
+			return nil, fmt.Errorf("неверный день %q", item)
+		}
+		wd := WeekdayNum{Day: day}
+		if num := item[:len(item)-2]; num != "" {
+			n, err := strconv.Atoi(num)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("неверный номер дня %q", item)
+			}
+			wd.N = n
+		}
+		out = append(out, wd)
+	}
+	return out, nil
+}
+
+func parseInts(v string, lo, hi int) ([]int, error) {
+	var out []int
+	for _, item := range strings.Split(v, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < lo || n > hi {
+			return nil, fmt.Errorf("значение %q вне диапазона", item)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// String возвращает каноническую запись правила.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			days[i] = wd.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]int, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			months[i] = int(m)
+		}
+		parts = append(parts, "BYMONTH="+joinInts(months))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+func (wd WeekdayNum) String() string {
+	code := ""
+	for c, d := range weekdayCodes {
+		if d == wd.Day {
+			code = c
+		}
+	}
+	if wd.N == 0 {
+		return code
+	}
+	return strconv.Itoa(wd.N) + code
+}
+
+func joinInts(ns []int) string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ",")
+}
+
+// FromPeriod строит правило по периоду регулярного платежа (daily/weekly/monthly/yearly).
+func FromPeriod(period string) (Rule, error) {
+	switch period {
+	case "daily":
+		return Rule{Freq: Daily, Interval: 1}, nil
+	case "weekly":
+		return Rule{Freq: Weekly, Interval: 1}, nil
+	case "monthly":
+		return Rule{Freq: Monthly, Interval: 1}, nil
+	case "yearly":
+		return Rule{Freq: Yearly, Interval: 1}, nil
+	}
+	return Rule{}, fmt.Errorf("%w: период %q", ErrInvalid, period)
+}
+
+// Next возвращает первое событие серии, начатой в start, строго позже after.
+// start сам является событием серии, если удовлетворяет правилу.
+// Возвращает false, если серия закончилась (COUNT, UNTIL) или событие не найдено.
+func (r Rule) Next(start, after time.Time) (time.Time, bool) {
+	interval := max(r.Interval, 1)
+	seen := 0
+
+	for k := 0; k < maxPeriods; k++ {
+		for _, t := range r.candidates(start, k*interval) {
+			if t.Before(start) {
+				continue
+			}
+			if r.Until != nil && t.After(*r.Until) {
+				return time.Time{}, false
+			}
+			seen++
+			if r.Count > 0 && seen > r.Count {
+				return time.Time{}, false
+			}
+			if t.After(after) {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// candidates возвращает отсортированные события периода с номером offset (в единицах FREQ от start).
+func (r Rule) candidates(start time.Time, offset int) []time.Time {
+	loc := start.Location()
+	hh, mm, ss := start.Clock()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hh, mm, ss, 0, loc)
+	}
+
+	var out []time.Time
+	switch r.Freq {
+	case Daily:
+		t := at(start.Year(), start.Month(), start.Day()+offset)
+		if r.matchWeekday(t) && r.matchMonthDay(t) && r.matchMonth(t.Month()) {
+			out = append(out, t)
+		}
+
+	case Weekly:
+		// понедельник недели start
+		monday := start.Day() - (int(start.Weekday())+6)%7
+		days := []time.Weekday{start.Weekday()}
+		if len(r.ByDay) > 0 {
+			days = days[:0]
+			for _, wd := range r.ByDay {
+				days = append(days, wd.Day)
+			}
+		}
+		for _, d := range days {
+			t := at(start.Year(), start.Month(), monday+offset*7+(int(d)+6)%7)
+			if r.matchMonth(t.Month()) {
+				out = append(out, t)
+			}
+		}
+
+	case Monthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(offset), 1, 0, 0, 0, 0, loc)
+		if r.matchMonth(first.Month()) {
+			out = r.daysInMonth(first.Year(), first.Month(), start.Day(), at)
+		}
+
+	case Yearly:
+		year := start.Year() + offset
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{start.Month()}
+		}
+		for _, m := range months {
+			out = append(out, r.daysInMonth(year, m, start.Day(), at)...)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return dedup(out)
+}
+
+// daysInMonth — события месяца по BYMONTHDAY и BYDAY; без них — день defaultDay.
+// Несуществующие даты (31 апреля) пропускаются, как требует RFC 5545.
+func (r Rule) daysInMonth(year int, month time.Month, defaultDay int, at func(int, time.Month, int) time.Time) []time.Time {
+	last := daysIn(year, month)
+	var days []int
+
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, d := range r.ByMonthDay {
+			if d < 0 {
+				d = last + d + 1
+			}
+			if d >= 1 && d <= last {
+				days = append(days, d)
+			}
+		}
+	case len(r.ByDay) > 0:
+		for d := 1; d <= last; d++ {
+			days = append(days, d)
+		}
+	default:
+		if defaultDay <= last {
+			days = append(days, defaultDay)
+		}
+	}
+
+	var out []time.Time
+	for _, d := range days {
+		t := at(year, month, d)
+		if len(r.ByDay) == 0 || r.matchWeekdayInMonth(t, last) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func (r Rule) matchWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Day == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWeekdayInMonth учитывает порядковый номер дня недели внутри месяца.
+func (r Rule) matchWeekdayInMonth(t time.Time, last int) bool {
+	for _, wd := range r.ByDay {
+		if wd.Day != t.Weekday() {
+			continue
+		}
+		switch {
+		case wd.N == 0:
+			return true
+		case wd.N > 0 && (t.Day()-1)/7+1 == wd.N:
+			return true
+		case wd.N < 0 && (last-t.Day())/7+1 == -wd.N:
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matchMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := daysIn(t.Year(), t.Month())
+	for _, d := range r.ByMonthDay {
+		if d == t.Day() || (d < 0 && last+d+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matchMonth(m time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, bm := range r.ByMonth {
+		if bm == m {
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func dedup(ts []time.Time) []time.Time {
+	out := ts[:0]
+	for i, t := range ts {
+		if i == 0 || !t.Equal(ts[i-1]) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
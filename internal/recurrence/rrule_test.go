@@ -0,0 +1,118 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 0, 0, 0, msk)
+	}
+
+	tests := []struct {
+		name   string
+		rule   string
+		start  time.Time
+		after  time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "каждый будний день: после пятницы — понедельник",
+			rule:   "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+			start:  day(2026, time.October, 14),
+			after:  day(2026, time.October, 16),
+			want:   day(2026, time.October, 19),
+			wantOK: true,
+		},
+		{
+			name:   "раз в две недели по вторникам",
+			rule:   "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+			start:  day(2026, time.October, 13),
+			after:  day(2026, time.October, 13),
+			want:   day(2026, time.October, 27),
+			wantOK: true,
+		},
+		{
+			name:   "второй вторник месяца",
+			rule:   "FREQ=MONTHLY;BYDAY=2TU",
+			start:  day(2026, time.October, 13),
+			after:  day(2026, time.October, 13),
+			want:   day(2026, time.November, 10),
+			wantOK: true,
+		},
+		{
+			name:   "последний день месяца",
+			rule:   "RRULE:FREQ=MONTHLY;BYMONTHDAY=-1",
+			start:  day(2026, time.January, 31),
+			after:  day(2026, time.January, 31),
+			want:   day(2026, time.February, 28),
+			wantOK: true,
+		},
+		{
+			// По RFC 5545 несуществующие даты пропускаются; регулярные платежи
+			// вместо этого переносятся на конец месяца (finance.NextRecurringDate).
+			name:   "31-е число пропускает короткие месяцы",
+			rule:   "FREQ=MONTHLY",
+			start:  day(2026, time.March, 31),
+			after:  day(2026, time.March, 31),
+			want:   day(2026, time.May, 31),
+			wantOK: true,
+		},
+		{
+			name:   "последняя пятница года в декабре",
+			rule:   "FREQ=YEARLY;BYMONTH=12;BYDAY=-1FR",
+			start:  day(2026, time.January, 1),
+			after:  day(2026, time.January, 1),
+			want:   day(2026, time.December, 25),
+			wantOK: true,
+		},
+		{
+			name:   "COUNT ограничивает серию",
+			rule:   "FREQ=DAILY;COUNT=3",
+			start:  day(2026, time.October, 14),
+			after:  day(2026, time.October, 16),
+			wantOK: false,
+		},
+		{
+			name:   "UNTIL ограничивает серию",
+			rule:   "FREQ=DAILY;UNTIL=20261015",
+			start:  day(2026, time.October, 14),
+			after:  day(2026, time.October, 15),
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rule, err)
+			}
+			got, ok := r.Next(tt.start, tt.after)
+			if ok != tt.wantOK {
+				t.Fatalf("Next ok = %v, want %v (got %v)", ok, tt.wantOK, got)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("Next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=WEEKLY;BYDAY=2TU",
+		"FREQ=DAILY;COUNT=2;UNTIL=20260101",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+	} {
+		if _, err := Parse(rule); err == nil {
+			t.Errorf("Parse(%q): expected error", rule)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Package search ищет по данным пользователя во всех модулях: задачам, финансовым операциям,
+// регулярным платежам и кредитам. Поиск полнотекстовый (PostgreSQL, русская и английская морфология).
+package search
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Module — модуль, к которому относится результат поиска.
+type Module string
+
+const (
+	ModuleTodo      Module = "todo"
+	ModuleFinance   Module = "finance"
+	ModuleRecurring Module = "recurring"
+	ModuleCredit    Module = "credit"
+)
+
+// MaxTerms — наибольшее число слов запроса; остальные слова отбрасываются.
+const MaxTerms = 8
+
+// PerModuleLimit — наибольшее число результатов одного модуля.
+const PerModuleLimit = 10
+
+// ErrEmptyQuery возвращается, если в запросе нет ни одного слова.
+var ErrEmptyQuery = errors.New("пустой поисковый запрос")
+
+// Hit — найденная запись.
+type Hit struct {
+	Module Module     `json:"module"`
+	ID     int        `json:"id"`
+	Title  string     `json:"title"`
+	Detail string     `json:"detail,omitempty"` // описание задачи, категория операции или платежа
+	Amount float64    `json:"amount,omitempty"`
+	Date   *time.Time `json:"date,omitempty"` // срок задачи, дата операции, следующий платёж, дата кредита
+	Done   bool       `json:"done,omitempty"` // задача выполнена
+	Rank   float64    `json:"rank"`
+}
+
+// Group — результаты одного модуля.
+type Group struct {
+	Module Module `json:"module"`
+	Hits   []Hit  `json:"hits"`
+}
+
+// Repository выполняет полнотекстовый поиск.
+type Repository interface {
+	// Search ищет записи пользователя, содержащие все слова terms (слова ищутся и как префиксы).
+	// В каждом модуле возвращается не больше limit записей, упорядоченных по убыванию релевантности.
+	Search(ctx context.Context, userID int64, terms []string, limit int) ([]Hit, error)
+}
+
+// Service ищет по всем модулям.
+type Service struct {
+	repo Repository
+}
+
+func NewService(r Repository) *Service {
+	return &Service{repo: r}
+}
+
+// Search ищет запрос query во всех модулях. Результаты сгруппированы по модулям;
+// модули упорядочены по лучшему результату, результаты внутри модуля — по релевантности.
+func (s *Service) Search(ctx context.Context, userID int64, query string) ([]Group, error) {
+	terms := Terms(query)
+	if len(terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+	hits, err := s.repo.Search(ctx, userID, terms, PerModuleLimit)
+	if err != nil {
+		return nil, err
+	}
+	return GroupHits(hits), nil
+}
+
+// Terms разбивает запрос на слова из букв и цифр в нижнем регистре.
+// Знаки препинания и операторы tsquery отбрасываются, поэтому слова безопасно подставлять в запрос.
+func Terms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > MaxTerms {
+		words = words[:MaxTerms]
+	}
+	return words
+}
+
+// GroupHits группирует результаты по модулям. Модули упорядочены по лучшему результату,
+// результаты внутри модуля — по убыванию релевантности.
+func GroupHits(hits []Hit) []Group {
+	index := map[Module]int{}
+	var groups []Group
+	for _, h := range hits {
+		i, ok := index[h.Module]
+		if !ok {
+			i = len(groups)
+			index[h.Module] = i
+			groups = append(groups, Group{Module: h.Module})
+		}
+		groups[i].Hits = append(groups[i].Hits, h)
+	}
+
+	for i := range groups {
+		sort.SliceStable(groups[i].Hits, func(a, b int) bool { return groups[i].Hits[a].Rank > groups[i].Hits[b].Rank })
+	}
+	sort.SliceStable(groups, func(a, b int) bool { return groups[a].Hits[0].Rank > groups[b].Hits[0].Rank })
+	return groups
+}
+
+// Total возвращает общее число результатов.
+func Total(groups []Group) int {
+	n := 0
+	for _, g := range groups {
+		n += len(g.Hits)
+	}
+	return n
+}
@@ -0,0 +1,52 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "Молоко", want: []string{"молоко"}},
+		{query: "  оплата   ЖКХ, март! ", want: []string{"оплата", "жкх", "март"}},
+		{query: "rent & (flat | !car):*", want: []string{"rent", "flat", "car"}},
+		{query: "iPhone 15", want: []string{"iphone", "15"}},
+		{query: "&|!", want: []string{}},
+		{query: "a b c d e f g h i j", want: []string{"a", "b", "c", "d", "e", "f", "g", "h"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := Terms(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Terms(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupHits(t *testing.T) {
+	hits := []Hit{
+		{Module: ModuleTodo, ID: 1, Rank: 0.1},
+		{Module: ModuleFinance, ID: 2, Rank: 0.5},
+		{Module: ModuleTodo, ID: 3, Rank: 0.3},
+		{Module: ModuleCredit, ID: 4, Rank: 0.2},
+	}
+
+	groups := GroupHits(hits)
+
+	var modules []Module
+	for _, g := range groups {
+		modules = append(modules, g.Module)
+	}
+	if want := []Module{ModuleFinance, ModuleTodo, ModuleCredit}; !reflect.DeepEqual(modules, want) {
+		t.Errorf("modules = %v, want %v", modules, want)
+	}
+	if todos := groups[1].Hits; todos[0].ID != 3 || todos[1].ID != 1 {
+		t.Errorf("todo hits not sorted by rank: %+v", todos)
+	}
+	if n := Total(groups); n != 4 {
+		t.Errorf("Total() = %d, want 4", n)
+	}
+}
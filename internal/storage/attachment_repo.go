@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"tg_bot_asist/internal/attachment"
+	"tg_bot_asist/internal/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AttachmentRepo хранит вложения задач и финансовых операций.
+type AttachmentRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAttachmentRepo(db *pgxpool.Pool) *AttachmentRepo {
+	return &AttachmentRepo{db: db}
+}
+
+// attachmentColumns — колонки вложения в порядке, который ожидает scanAttachment.
+const attachmentColumns = `id, user_id, CASE WHEN todo_id IS NOT NULL THEN 'todo' ELSE 'finance' END,
+        COALESCE(todo_id, finance_entry_id), kind, file_id, file_unique_id, file_name, mime_type, size, created_at`
+
+func scanAttachment(row pgx.Row, a *attachment.Attachment) error {
+	return row.Scan(&a.ID, &a.UserID, &a.Owner, &a.OwnerID, &a.Kind, &a.FileID, &a.FileUniqueID,
+		&a.FileName, &a.MimeType, &a.Size, &a.CreatedAt)
+}
+
+// ownerColumn возвращает колонку ссылки на запись владельца.
+func ownerColumn(owner attachment.Owner) (string, error) {
+	switch owner {
+	case attachment.OwnerTodo:
+		return "todo_id", nil
+	case attachment.OwnerFinance:
+		return "finance_entry_id", nil
+	}
+	return "", attachment.ErrOwner
+}
+
+// Add сохраняет вложение.
+func (r *AttachmentRepo) Add(ctx context.Context, a *attachment.Attachment) error {
+	col, err := ownerColumn(a.Owner)
+	if err != nil {
+		return err
+	}
+	err = r.db.QueryRow(ctx, `
+        INSERT INTO attachments (user_id, `+col+`, kind, file_id, file_unique_id, file_name, mime_type, size)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, created_at
+    `, a.UserID, a.OwnerID, a.Kind, a.FileID, a.FileUniqueID, a.FileName, a.MimeType, a.Size).Scan(&a.ID, &a.CreatedAt)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return attachment.ErrDuplicate
+		}
+		logger.Error("AttachmentRepo.Add error: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// List возвращает вложения записи в порядке добавления.
+func (r *AttachmentRepo) List(ctx context.Context, owner attachment.Owner, ownerID int) ([]attachment.Attachment, error) {
+	col, err := ownerColumn(owner)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(ctx, `
+        SELECT `+attachmentColumns+`
+        FROM attachments
+        WHERE `+col+`=$1
+        ORDER BY id
+    `, ownerID)
+	if err != nil {
+		logger.Error("AttachmentRepo.List error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []attachment.Attachment
+	for rows.Next() {
+		var a attachment.Attachment
+		if err := scanAttachment(rows, &a); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// Count возвращает число вложений записи.
+func (r *AttachmentRepo) Count(ctx context.Context, owner attachment.Owner, ownerID int) (int, error) {
+	col, err := ownerColumn(owner)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	if err := r.db.QueryRow(ctx, `SELECT count(*) FROM attachments WHERE `+col+`=$1`, ownerID).Scan(&n); err != nil {
+		logger.Error("AttachmentRepo.Count error: " + err.Error())
+		return 0, err
+	}
+	return n, nil
+}
+
+// Get возвращает вложение по ID.
+func (r *AttachmentRepo) Get(ctx context.Context, id int) (*attachment.Attachment, error) {
+	var a attachment.Attachment
+	err := scanAttachment(r.db.QueryRow(ctx, `SELECT `+attachmentColumns+` FROM attachments WHERE id=$1`, id), &a)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, attachment.ErrNotFound
+		}
+		logger.Error("AttachmentRepo.Get error: " + err.Error())
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Delete удаляет вложение.
+func (r *AttachmentRepo) Delete(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM attachments WHERE id=$1`, id)
+	if err != nil {
+		logger.Error("AttachmentRepo.Delete error: " + err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return attachment.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"tg_bot_asist/internal/calendar"
+	"tg_bot_asist/internal/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CalendarTokenRepo хранит токены подписки на календарь.
+type CalendarTokenRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewCalendarTokenRepo(db *pgxpool.Pool) *CalendarTokenRepo {
+	return &CalendarTokenRepo{db: db}
+}
+
+// GetToken возвращает токен пользователя или "" если токен ещё не выдан.
+func (r *CalendarTokenRepo) GetToken(ctx context.Context, userID int64) (string, error) {
+	var token string
+	err := r.db.QueryRow(ctx, `SELECT token FROM calendar_tokens WHERE user_id=$1`, userID).Scan(&token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		logger.Error("CalendarTokenRepo.GetToken error: " + err.Error())
+		return "", err
+	}
+	return token, nil
+}
+
+// SetToken сохраняет новый токен пользователя, заменяя предыдущий.
+func (r *CalendarTokenRepo) SetToken(ctx context.Context, userID int64, token string) error {
+	_, err := r.db.Exec(ctx, `
+        INSERT INTO calendar_tokens (user_id, token, created_at)
+        VALUES ($1,$2,$3)
+        ON CONFLICT (user_id) DO UPDATE SET
+            token = EXCLUDED.token,
+            created_at = EXCLUDED.created_at
+    `,
+		userID, token, time.Now(),
+	)
+	if err != nil {
+		logger.Error("CalendarTokenRepo.SetToken error: " + err.Error())
+	}
+	return err
+}
+
+// UserByToken возвращает владельца токена или calendar.ErrInvalidToken.
+func (r *CalendarTokenRepo) UserByToken(ctx context.Context, token string) (int64, error) {
+	var userID int64
+	err := r.db.QueryRow(ctx, `SELECT user_id FROM calendar_tokens WHERE token=$1`, token).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, calendar.ErrInvalidToken
+	}
+	if err != nil {
+		logger.Error("CalendarTokenRepo.UserByToken error: " + err.Error())
+		return 0, err
+	}
+	return userID, nil
+}
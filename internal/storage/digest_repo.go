@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"tg_bot_asist/internal/digest"
+	"tg_bot_asist/internal/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DigestRepo хранит настройки утренней сводки.
+type DigestRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewDigestRepo(db *pgxpool.Pool) *DigestRepo {
+	return &DigestRepo{db: db}
+}
+
+// GetSettings возвращает настройки пользователя или настройки по умолчанию.
+func (r *DigestRepo) GetSettings(ctx context.Context, userID int64) (digest.Settings, error) {
+	s := digest.Settings{UserID: userID}
+	err := r.db.QueryRow(ctx, `
+        SELECT enabled, send_minute, days_ahead, daily_budget::float8
+        FROM digest_settings
+        WHERE user_id=$1
+    `, userID).Scan(&s.Enabled, &s.SendMinute, &s.DaysAhead, &s.DailyBudget)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return digest.DefaultSettings(userID), nil
+	}
+	if err != nil {
+		logger.Error("DigestRepo.GetSettings error: " + err.Error())
+		return s, err
+	}
+	return s, nil
+}
+
+// SaveSettings сохраняет настройки пользователя.
+func (r *DigestRepo) SaveSettings(ctx context.Context, s digest.Settings) error {
+	_, err := r.db.Exec(ctx, `
+        INSERT INTO digest_settings (user_id, enabled, send_minute, days_ahead, daily_budget)
+        VALUES ($1,$2,$3,$4,$5)
+        ON CONFLICT (user_id) DO UPDATE SET
+            enabled = EXCLUDED.enabled,
+            send_minute = EXCLUDED.send_minute,
+            days_ahead = EXCLUDED.days_ahead,
+            daily_budget = EXCLUDED.daily_budget
+    `,
+		s.UserID, s.Enabled, s.SendMinute, s.DaysAhead, s.DailyBudget,
+	)
+	if err != nil {
+		logger.Error("DigestRepo.SaveSettings error: " + err.Error())
+	}
+	return err
+}
+
+// ListEnabled возвращает настройки пользователей, включивших сводку.
+func (r *DigestRepo) ListEnabled(ctx context.Context) ([]digest.Settings, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT user_id, enabled, send_minute, days_ahead, daily_budget::float8
+        FROM digest_settings
+        WHERE enabled
+    `)
+	if err != nil {
+		logger.Error("DigestRepo.ListEnabled error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []digest.Settings
+	for rows.Next() {
+		var s digest.Settings
+		if err := rows.Scan(&s.UserID, &s.Enabled, &s.SendMinute, &s.DaysAhead, &s.DailyBudget); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// ClaimDay отмечает отправку сводки за день; false — сводка за этот день уже отправлена.
+func (r *DigestRepo) ClaimDay(ctx context.Context, userID int64, day time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+        UPDATE digest_settings
+        SET last_sent_on = $2
+        WHERE user_id = $1 AND (last_sent_on IS NULL OR last_sent_on < $2)
+    `, userID, day)
+	if err != nil {
+		logger.Error("DigestRepo.ClaimDay error: " + err.Error())
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReleaseDay снимает отметку об отправке сводки за день.
+func (r *DigestRepo) ReleaseDay(ctx context.Context, userID int64, day time.Time) error {
+	_, err := r.db.Exec(ctx, `
+        UPDATE digest_settings SET last_sent_on = NULL
+        WHERE user_id = $1 AND last_sent_on = $2
+    `, userID, day)
+	if err != nil {
+		logger.Error("DigestRepo.ReleaseDay error: " + err.Error())
+	}
+	return err
+}
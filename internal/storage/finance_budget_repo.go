@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/logger"
+)
+
+// ListBudgets возвращает бюджеты пользователя: общий первым, затем по имени категории.
+func (r *FinanceRepo) ListBudgets(ctx context.Context, userID int64) ([]finance.Budget, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT b.id, b.user_id, COALESCE(b.category_id, 0), COALESCE(c.name, ''), b.amount::float8, b.rollover
+        FROM finance_budgets b
+        LEFT JOIN finance_categories c ON c.id = b.category_id
+        WHERE b.user_id=$1
+        ORDER BY b.category_id IS NOT NULL, lower(c.name), b.id
+    `, userID)
+	if err != nil {
+		logger.Error("FinanceRepo.ListBudgets error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []finance.Budget
+	for rows.Next() {
+		var b finance.Budget
+		if err := rows.Scan(&b.ID, &b.UserID, &b.CategoryID, &b.Category, &b.Limit, &b.Rollover); err != nil {
+			return nil, err
+		}
+		list = append(list, b)
+	}
+	return list, rows.Err()
+}
+
+// SetBudget создаёт бюджет или заменяет лимит и режим переноса существующего бюджета на ту же категорию.
+func (r *FinanceRepo) SetBudget(ctx context.Context, b *finance.Budget) error {
+	err := r.db.QueryRow(ctx, `
+        INSERT INTO finance_budgets (user_id, category_id, amount, rollover)
+        VALUES ($1, NULLIF($2, 0), $3, $4)
+        ON CONFLICT (user_id, (COALESCE(category_id, 0)))
+        DO UPDATE SET amount = EXCLUDED.amount, rollover = EXCLUDED.rollover
+        RETURNING id
+    `, b.UserID, b.CategoryID, b.Limit, b.Rollover).Scan(&b.ID)
+	if err != nil {
+		logger.Error("FinanceRepo.SetBudget error: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// DeleteBudget удаляет бюджет пользователя или возвращает finance.ErrBudgetNotFound.
+func (r *FinanceRepo) DeleteBudget(ctx context.Context, userID int64, id int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM finance_budgets WHERE id=$1 AND user_id=$2`, id, userID)
+	if err != nil {
+		logger.Error("FinanceRepo.DeleteBudget error: " + err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return finance.ErrBudgetNotFound
+	}
+	return nil
+}
+
+// BudgetSpending считает расходы за [from, to) по каждому бюджету пользователя.
+// Общий бюджет учитывает все расходы, бюджет категории — её операции и операции её подкатегорий.
+func (r *FinanceRepo) BudgetSpending(ctx context.Context, userID int64, from, to time.Time) (map[int]float64, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT b.id, COALESCE(SUM(e.amount), 0)::float8
+        FROM finance_budgets b
+        LEFT JOIN finance_entries e
+               ON e.user_id = b.user_id AND e.type = 'expense'
+              AND e.occurred_at >= $2 AND e.occurred_at < $3
+              AND (b.category_id IS NULL
+                   OR e.category_id = b.category_id
+                   OR e.category_id IN (SELECT id FROM finance_categories WHERE parent_id = b.category_id))
+        WHERE b.user_id=$1
+        GROUP BY b.id
+    `, userID, from, to)
+	if err != nil {
+		logger.Error("FinanceRepo.BudgetSpending error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	spent := make(map[int]float64)
+	for rows.Next() {
+		var id int
+		var sum float64
+		if err := rows.Scan(&id, &sum); err != nil {
+			return nil, err
+		}
+		spent[id] = sum
+	}
+	return spent, rows.Err()
+}
@@ -0,0 +1,305 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// categoryColumns — колонки категории в порядке, который ожидает scanCategory.
+const categoryColumns = `c.id, c.user_id, c.name, c.type, COALESCE(c.parent_id, 0), c.icon,
+        (SELECT count(*) FROM finance_entries e WHERE e.category_id = c.id)`
+
+func scanCategory(row pgx.Row, c *finance.Category) error {
+	return row.Scan(&c.ID, &c.UserID, &c.Name, &c.Type, &c.ParentID, &c.Icon, &c.Uses)
+}
+
+// EnsureCategories заполняет пустой справочник пользователя категориями по умолчанию и категориями
+// из его операций, после чего связывает операции с категориями; изменённые названия категорий операций
+// записываются в историю. Повторный вызов ничего не делает.
+func (r *FinanceRepo) EnsureCategories(ctx context.Context, userID int64) error {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM finance_categories WHERE user_id=$1)`, userID).Scan(&exists); err != nil {
+		logger.Error("FinanceRepo.EnsureCategories error: " + err.Error())
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("FinanceRepo.EnsureCategories begin error: " + err.Error())
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for typ, seeds := range finance.DefaultCategories {
+		for _, seed := range seeds {
+			parentID, err := seedCategory(ctx, tx, userID, typ, seed, 0)
+			if err != nil {
+				logger.Error("FinanceRepo.EnsureCategories seed error: " + err.Error())
+				return err
+			}
+			for _, child := range seed.Children {
+				if _, err := seedCategory(ctx, tx, userID, typ, child, parentID); err != nil {
+					logger.Error("FinanceRepo.EnsureCategories seed error: " + err.Error())
+					return err
+				}
+			}
+		}
+	}
+
+	// Категории, которые пользователь уже вводил текстом, становятся верхнеуровневыми категориями справочника
+	if _, err := tx.Exec(ctx, `
+        INSERT INTO finance_categories (user_id, name, type)
+        SELECT DISTINCT ON (type, lower(btrim(category))) user_id, btrim(category), type
+        FROM finance_entries
+        WHERE user_id=$1 AND btrim(COALESCE(category, '')) <> '' AND type IN ('income', 'expense')
+        ORDER BY type, lower(btrim(category)), occurred_at DESC
+        ON CONFLICT DO NOTHING
+    `, userID); err != nil {
+		logger.Error("FinanceRepo.EnsureCategories import error: " + err.Error())
+		return err
+	}
+	if err := updateEntryCategories(ctx, tx, `
+        UPDATE finance_entries e SET category_id = c.id, category = c.name
+        FROM finance_categories c,
+             (SELECT id AS old_id, category AS old_category FROM finance_entries
+              WHERE user_id=$1 AND category_id IS NULL FOR UPDATE) old
+        WHERE e.id = old.old_id
+          AND c.user_id = e.user_id AND c.type = e.type AND lower(c.name) = lower(btrim(e.category))
+    `, userID); err != nil {
+		logger.Error("FinanceRepo.EnsureCategories link error: " + err.Error())
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("FinanceRepo.EnsureCategories commit error: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// seedCategory добавляет категорию по умолчанию и возвращает её ID; существующая категория
+// (например, созданная параллельным запросом) не дублируется.
+func seedCategory(ctx context.Context, tx pgx.Tx, userID int64, typ string, seed finance.CategorySeed, parentID int) (int, error) {
+	var id int
+	err := tx.QueryRow(ctx, `
+        INSERT INTO finance_categories (user_id, name, type, icon, parent_id)
+        VALUES ($1, $2, $3, $4, NULLIF($5, 0))
+        ON CONFLICT DO NOTHING
+        RETURNING id
+    `, userID, seed.Name, typ, seed.Icon, parentID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = tx.QueryRow(ctx, `
+            SELECT id FROM finance_categories WHERE user_id=$1 AND type=$2 AND lower(name)=lower($3)
+        `, userID, typ, seed.Name).Scan(&id)
+	}
+	return id, err
+}
+
+// ListCategories возвращает категории пользователя: родитель, затем его подкатегории, в порядке создания.
+func (r *FinanceRepo) ListCategories(ctx context.Context, userID int64, typ string) ([]finance.Category, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT `+categoryColumns+`
+        FROM finance_categories c
+        WHERE c.user_id=$1 AND ($2 = '' OR c.type = $2)
+        ORDER BY c.type, COALESCE(c.parent_id, c.id), c.parent_id IS NOT NULL, c.id
+    `, userID, typ)
+	if err != nil {
+		logger.Error("FinanceRepo.ListCategories error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []finance.Category
+	for rows.Next() {
+		var c finance.Category
+		if err := scanCategory(rows, &c); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// GetCategory возвращает категорию пользователя или finance.ErrCategoryNotFound.
+func (r *FinanceRepo) GetCategory(ctx context.Context, userID int64, id int) (*finance.Category, error) {
+	var c finance.Category
+	err := scanCategory(r.db.QueryRow(ctx, `
+        SELECT `+categoryColumns+`
+        FROM finance_categories c
+        WHERE c.id=$1 AND c.user_id=$2
+    `, id, userID), &c)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, finance.ErrCategoryNotFound
+		}
+		logger.Error("FinanceRepo.GetCategory error: " + err.Error())
+		return nil, err
+	}
+	return &c, nil
+}
+
+// FindCategory ищет категорию пользователя по типу и имени без учёта регистра.
+func (r *FinanceRepo) FindCategory(ctx context.Context, userID int64, typ, name string) (*finance.Category, error) {
+	var c finance.Category
+	err := scanCategory(r.db.QueryRow(ctx, `
+        SELECT `+categoryColumns+`
+        FROM finance_categories c
+        WHERE c.user_id=$1 AND c.type=$2 AND lower(c.name)=lower($3)
+    `, userID, typ, name), &c)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, finance.ErrCategoryNotFound
+		}
+		logger.Error("FinanceRepo.FindCategory error: " + err.Error())
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CreateCategory сохраняет категорию и заполняет её ID.
+func (r *FinanceRepo) CreateCategory(ctx context.Context, c *finance.Category) error {
+	err := r.db.QueryRow(ctx, `
+        INSERT INTO finance_categories (user_id, name, type, icon, parent_id)
+        VALUES ($1, $2, $3, $4, NULLIF($5, 0))
+        RETURNING id
+    `, c.UserID, c.Name, c.Type, c.Icon, c.ParentID).Scan(&c.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return finance.ErrCategoryExists
+		}
+		logger.Error("FinanceRepo.CreateCategory error: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// UpdateCategory сохраняет имя, иконку и родителя категории и переименовывает её операции, записывая переименование в историю.
+func (r *FinanceRepo) UpdateCategory(ctx context.Context, c *finance.Category) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("FinanceRepo.UpdateCategory begin error: " + err.Error())
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+        UPDATE finance_categories SET name=$3, icon=$4, parent_id=NULLIF($5, 0)
+        WHERE id=$1 AND user_id=$2
+    `, c.ID, c.UserID, c.Name, c.Icon, c.ParentID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return finance.ErrCategoryExists
+		}
+		logger.Error("FinanceRepo.UpdateCategory error: " + err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return finance.ErrCategoryNotFound
+	}
+	if err := updateEntryCategories(ctx, tx, `
+        UPDATE finance_entries e SET category=$3
+        FROM (SELECT id AS old_id, category AS old_category FROM finance_entries
+              WHERE category_id=$1 AND user_id=$2 FOR UPDATE) old
+        WHERE e.id = old.old_id
+    `, c.ID, c.UserID, c.Name); err != nil {
+		logger.Error("FinanceRepo.UpdateCategory entries error: " + err.Error())
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("FinanceRepo.UpdateCategory commit error: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// HasChildren сообщает, есть ли у категории подкатегории.
+func (r *FinanceRepo) HasChildren(ctx context.Context, userID int64, id int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+        SELECT EXISTS (SELECT 1 FROM finance_categories WHERE parent_id=$1 AND user_id=$2)
+    `, id, userID).Scan(&exists)
+	if err != nil {
+		logger.Error("FinanceRepo.HasChildren error: " + err.Error())
+	}
+	return exists, err
+}
+
+// DeleteCategory удаляет категорию без операций; подкатегории становятся верхнеуровневыми (ON DELETE SET NULL).
+func (r *FinanceRepo) DeleteCategory(ctx context.Context, userID int64, id int) error {
+	tag, err := r.db.Exec(ctx, `
+        DELETE FROM finance_categories c
+        WHERE c.id=$1 AND c.user_id=$2
+          AND NOT EXISTS (SELECT 1 FROM finance_entries e WHERE e.category_id = c.id)
+    `, id, userID)
+	if err != nil {
+		logger.Error("FinanceRepo.DeleteCategory error: " + err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		// категории нет или в ней есть операции
+		if _, err := r.GetCategory(ctx, userID, id); err != nil {
+			return err
+		}
+		return finance.ErrCategoryInUse
+	}
+	return nil
+}
+
+// MergeCategories переносит операции (с записью в историю) и подкатегории source в target и удаляет source.
+func (r *FinanceRepo) MergeCategories(ctx context.Context, userID int64, sourceID, targetID, parentID int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("FinanceRepo.MergeCategories begin error: " + err.Error())
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateEntryCategories(ctx, tx, `
+        UPDATE finance_entries e
+        SET category_id=$3, category=(SELECT name FROM finance_categories WHERE id=$3)
+        FROM (SELECT id AS old_id, category AS old_category FROM finance_entries
+              WHERE category_id=$1 AND user_id=$2 FOR UPDATE) old
+        WHERE e.id = old.old_id
+    `, sourceID, userID, targetID); err != nil {
+		logger.Error("FinanceRepo.MergeCategories entries error: " + err.Error())
+		return err
+	}
+	// Бюджет source переходит к target, если у target своего бюджета нет; иначе удаляется вместе с source
+	if _, err := tx.Exec(ctx, `
+        UPDATE finance_budgets SET category_id=$3
+        WHERE category_id=$1 AND user_id=$2
+          AND NOT EXISTS (SELECT 1 FROM finance_budgets WHERE category_id=$3)
+    `, sourceID, userID, targetID); err != nil {
+		logger.Error("FinanceRepo.MergeCategories budgets error: " + err.Error())
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+        UPDATE finance_categories SET parent_id=$3 WHERE parent_id=$1 AND user_id=$2
+    `, sourceID, userID, parentID); err != nil {
+		logger.Error("FinanceRepo.MergeCategories children error: " + err.Error())
+		return err
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM finance_categories WHERE id=$1 AND user_id=$2`, sourceID, userID)
+	if err != nil {
+		logger.Error("FinanceRepo.MergeCategories error: " + err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return finance.ErrCategoryNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("FinanceRepo.MergeCategories commit error: " + err.Error())
+		return err
+	}
+	return nil
+}
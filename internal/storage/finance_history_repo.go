@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// entrySnapshot — состояние операции в истории изменений (finance_entry_history.before/after).
+type entrySnapshot struct {
+	Amount     float64   `json:"amount"`
+	Category   string    `json:"category"`
+	Type       string    `json:"type"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// snapshotJSON кодирует состояние операции для истории; nil — отсутствующее состояние.
+func snapshotJSON(e *finance.FinanceEntry) ([]byte, error) {
+	if e == nil {
+		return nil, nil
+	}
+	return json.Marshal(entrySnapshot{Amount: e.Amount, Category: e.Category, Type: e.Type, Note: e.Note, CreatedAt: e.CreatedAt, OccurredAt: e.OccurredAt})
+}
+
+// snapshotEntry восстанавливает операцию из истории.
+func snapshotEntry(raw []byte, id int, userID int64) (*finance.FinanceEntry, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var s entrySnapshot
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return &finance.FinanceEntry{ID: id, UserID: userID, Amount: s.Amount, Category: s.Category, Type: s.Type, Note: s.Note,
+		CreatedAt: s.CreatedAt, OccurredAt: s.OccurredAt}, nil
+}
+
+// addHistory записывает изменение операции в историю в рамках транзакции tx.
+func addHistory(ctx context.Context, tx pgx.Tx, userID int64, entryID int, action string, before, after *finance.FinanceEntry) error {
+	b, err := snapshotJSON(before)
+	if err != nil {
+		return err
+	}
+	a, err := snapshotJSON(after)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+        INSERT INTO finance_entry_history (entry_id, user_id, action, before, after)
+        VALUES ($1, $2, $3, $4, $5)
+    `, entryID, userID, action, b, a)
+	return err
+}
+
+// lockEntry читает операцию пользователя с блокировкой строки до конца транзакции.
+func lockEntry(ctx context.Context, tx pgx.Tx, userID int64, id int) (*finance.FinanceEntry, error) {
+	var e finance.FinanceEntry
+	err := scanEntry(tx.QueryRow(ctx, `
+        SELECT `+entryColumns+`
+        FROM finance_entries
+        WHERE id=$1 AND user_id=$2
+        FOR UPDATE
+    `, id, userID), &e)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, finance.ErrNotFound
+	}
+	return &e, err
+}
+
+// updateEntryCategories выполняет UPDATE, меняющий категорию операций, и записывает каждое изменение названия
+// категории в историю в рамках транзакции tx. Запрос обновляет finance_entries под псевдонимом e и берёт прежние
+// значения из подзапроса old (old_id, old_category), заблокированного FOR UPDATE; RETURNING добавляется здесь.
+func updateEntryCategories(ctx context.Context, tx pgx.Tx, query string, args ...any) error {
+	rows, err := tx.Query(ctx, query+`
+        RETURNING COALESCE(old.old_category, ''), e.id, e.user_id, e.amount, COALESCE(e.category, ''), e.type,
+                  COALESCE(e.note, ''), e.created_at, e.occurred_at, COALESCE(e.category_id, 0)
+    `, args...)
+	if err != nil {
+		return err
+	}
+	var befores, afters []finance.FinanceEntry
+	for rows.Next() {
+		var after finance.FinanceEntry
+		var oldCategory string
+		if err := rows.Scan(&oldCategory, &after.ID, &after.UserID, &after.Amount, &after.Category, &after.Type,
+			&after.Note, &after.CreatedAt, &after.OccurredAt, &after.CategoryID); err != nil {
+			rows.Close()
+			return err
+		}
+		// привязка к категории с тем же названием не меняет состояние операции в истории
+		if oldCategory == after.Category {
+			continue
+		}
+		before := after
+		before.Category = oldCategory
+		befores, afters = append(befores, before), append(afters, after)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range afters {
+		if err := addHistory(ctx, tx, afters[i].UserID, afters[i].ID, finance.ActionUpdate, &befores[i], &afters[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateEntry изменяет операцию и записывает изменение в историю.
+func (r *FinanceRepo) UpdateEntry(ctx context.Context, userID int64, id int, patch finance.EntryPatch) (*finance.FinanceEntry, *finance.FinanceEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("FinanceRepo.UpdateEntry begin error: " + err.Error())
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := lockEntry(ctx, tx, userID, id)
+	if err != nil {
+		if !errors.Is(err, finance.ErrNotFound) {
+			logger.Error("FinanceRepo.UpdateEntry select error: " + err.Error())
+		}
+		return nil, nil, err
+	}
+	after, err := patch.Apply(*before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+        UPDATE finance_entries SET amount=$3, category=$4, type=$5, note=$6, occurred_at=$7, category_id=NULLIF($8, 0)
+        WHERE id=$1 AND user_id=$2
+    `, id, userID, after.Amount, after.Category, after.Type, after.Note, after.OccurredAt, after.CategoryID); err != nil {
+		logger.Error("FinanceRepo.UpdateEntry error: " + err.Error())
+		return nil, nil, err
+	}
+	if err := addHistory(ctx, tx, userID, id, finance.ActionUpdate, before, &after); err != nil {
+		logger.Error("FinanceRepo.UpdateEntry history error: " + err.Error())
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("FinanceRepo.UpdateEntry commit error: " + err.Error())
+		return nil, nil, err
+	}
+	return before, &after, nil
+}
+
+// DeleteEntry удаляет операцию, сохраняя её последнее состояние в истории.
+func (r *FinanceRepo) DeleteEntry(ctx context.Context, userID int64, id int) (*finance.FinanceEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("FinanceRepo.DeleteEntry begin error: " + err.Error())
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := lockEntry(ctx, tx, userID, id)
+	if err != nil {
+		if !errors.Is(err, finance.ErrNotFound) {
+			logger.Error("FinanceRepo.DeleteEntry select error: " + err.Error())
+		}
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM finance_entries WHERE id=$1 AND user_id=$2`, id, userID); err != nil {
+		logger.Error("FinanceRepo.DeleteEntry error: " + err.Error())
+		return nil, err
+	}
+	if err := addHistory(ctx, tx, userID, id, finance.ActionDelete, before, nil); err != nil {
+		logger.Error("FinanceRepo.DeleteEntry history error: " + err.Error())
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("FinanceRepo.DeleteEntry commit error: " + err.Error())
+		return nil, err
+	}
+	return before, nil
+}
+
+// ListHistory возвращает историю изменений операций пользователя, новые сначала.
+func (r *FinanceRepo) ListHistory(ctx context.Context, userID int64, entryID int) ([]finance.EntryChange, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, entry_id, action, before, after, changed_at
+        FROM finance_entry_history
+        WHERE user_id=$1 AND ($2 = 0 OR entry_id = $2)
+        ORDER BY changed_at DESC, id DESC
+    `, userID, entryID)
+	if err != nil {
+		logger.Error("FinanceRepo.ListHistory error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []finance.EntryChange
+	for rows.Next() {
+		c := finance.EntryChange{UserID: userID}
+		var before, after []byte
+		if err := rows.Scan(&c.ID, &c.EntryID, &c.Action, &before, &after, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		if c.Before, err = snapshotEntry(before, c.EntryID, userID); err != nil {
+			return nil, err
+		}
+		if c.After, err = snapshotEntry(after, c.EntryID, userID); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
@@ -2,11 +2,13 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"tg_bot_asist/internal/finance"
 	"tg_bot_asist/internal/logger"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -18,29 +20,55 @@ func NewFinanceRepo(db *pgxpool.Pool) *FinanceRepo {
 	return &FinanceRepo{db: db}
 }
 
+// AddEntry сохраняет операцию, заполняет её ID и записывает создание в историю изменений.
 func (r *FinanceRepo) AddEntry(ctx context.Context, e *finance.FinanceEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("FinanceRepo.AddEntry begin error: " + err.Error())
+		return err
+	}
+	defer tx.Rollback(ctx)
 
-	_, err := r.db.Exec(ctx, `
-        INSERT INTO finance_entries (user_id, amount, category, type, note, created_at)
-        VALUES ($1,$2,$3,$4,$5,$6)
+	err = tx.QueryRow(ctx, `
+        INSERT INTO finance_entries (user_id, amount, category, type, note, created_at, occurred_at, category_id)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,NULLIF($8, 0))
+        RETURNING id
     `,
-		e.UserID, e.Amount, e.Category, e.Type, e.Note, time.Now(),
-	)
+		e.UserID, e.Amount, e.Category, e.Type, e.Note, time.Now(), e.OccurredAt, e.CategoryID,
+	).Scan(&e.ID)
 
 	if err != nil {
 		logger.Error("FinanceRepo.AddEntry error: " + err.Error())
+		return err
+	}
+	if err := addHistory(ctx, tx, e.UserID, e.ID, finance.ActionCreate, nil, e); err != nil {
+		logger.Error("FinanceRepo.AddEntry history error: " + err.Error())
+		return err
 	}
 
-	return err
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("FinanceRepo.AddEntry commit error: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// entryColumns — колонки операции в порядке, который ожидает scanEntry.
+// note и category могут быть NULL в старых записях и после отката изменений.
+const entryColumns = `id, user_id, amount, COALESCE(category, ''), type, COALESCE(note, ''), created_at, occurred_at,
+               COALESCE(category_id, 0)`
+
+func scanEntry(row pgx.Row, e *finance.FinanceEntry) error {
+	return row.Scan(&e.ID, &e.UserID, &e.Amount, &e.Category, &e.Type, &e.Note, &e.CreatedAt, &e.OccurredAt, &e.CategoryID)
 }
 
 func (r *FinanceRepo) ListEntries(ctx context.Context, userID int64) ([]*finance.FinanceEntry, error) {
 
 	rows, err := r.db.Query(ctx, `
-        SELECT id, user_id, amount, category, type, note, created_at
+        SELECT `+entryColumns+`
         FROM finance_entries
         WHERE user_id=$1
-        ORDER BY created_at DESC
+        ORDER BY occurred_at DESC, id DESC
     `,
 		userID,
 	)
@@ -57,7 +85,7 @@ func (r *FinanceRepo) ListEntries(ctx context.Context, userID int64) ([]*finance
 	for rows.Next() {
 		var e finance.FinanceEntry
 
-		if err := rows.Scan(&e.ID, &e.UserID, &e.Amount, &e.Category, &e.Type, &e.Note, &e.CreatedAt); err != nil {
+		if err := scanEntry(rows, &e); err != nil {
 			return nil, err
 		}
 
@@ -66,3 +94,22 @@ func (r *FinanceRepo) ListEntries(ctx context.Context, userID int64) ([]*finance
 
 	return list, nil
 }
+
+// GetEntry возвращает операцию пользователя по ID или finance.ErrNotFound.
+func (r *FinanceRepo) GetEntry(ctx context.Context, userID int64, id int) (*finance.FinanceEntry, error) {
+	var e finance.FinanceEntry
+	err := scanEntry(r.db.QueryRow(ctx, `
+        SELECT `+entryColumns+`
+        FROM finance_entries
+        WHERE id=$1 AND user_id=$2
+    `, id, userID), &e)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, finance.ErrNotFound
+		}
+		logger.Error("FinanceRepo.GetEntry error: " + err.Error())
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"tg_bot_asist/internal/finance"
+	"tg_bot_asist/internal/logger"
+)
+
+// CategoryTotals суммирует операции за [from, to) по типу и категории; подкатегории сворачиваются в родителя,
+// операции без категории справочника группируются по тексту категории.
+func (r *FinanceRepo) CategoryTotals(ctx context.Context, userID int64, from, to time.Time) ([]finance.CategoryTotal, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT e.type,
+               COALESCE(p.id, c.id, 0),
+               COALESCE(p.name, c.name, NULLIF(btrim(e.category), ''), 'Без категории'),
+               COALESCE(p.icon, c.icon, ''),
+               SUM(e.amount)::float8,
+               count(*)
+        FROM finance_entries e
+        LEFT JOIN finance_categories c ON c.id = e.category_id
+        LEFT JOIN finance_categories p ON p.id = c.parent_id
+        WHERE e.user_id=$1 AND e.occurred_at >= $2 AND e.occurred_at < $3
+        GROUP BY 1, 2, 3, 4
+    `, userID, from, to)
+	if err != nil {
+		logger.Error("FinanceRepo.CategoryTotals error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []finance.CategoryTotal
+	for rows.Next() {
+		var c finance.CategoryTotal
+		if err := rows.Scan(&c.Type, &c.CategoryID, &c.Category, &c.Icon, &c.Amount, &c.Count); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// TopExpenses возвращает крупнейшие расходы за [from, to).
+func (r *FinanceRepo) TopExpenses(ctx context.Context, userID int64, from, to time.Time, limit int) ([]finance.ReportEntry, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, amount::float8, COALESCE(category, ''), COALESCE(note, ''), occurred_at
+        FROM finance_entries
+        WHERE user_id=$1 AND type='expense' AND occurred_at >= $2 AND occurred_at < $3
+        ORDER BY amount DESC, occurred_at DESC
+        LIMIT $4
+    `, userID, from, to, limit)
+	if err != nil {
+		logger.Error("FinanceRepo.TopExpenses error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []finance.ReportEntry
+	for rows.Next() {
+		var e finance.ReportEntry
+		if err := rows.Scan(&e.ID, &e.Amount, &e.Category, &e.Note, &e.OccurredAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// ListEntriesPage возвращает страницу операций пользователя в порядке ListEntries.
+func (r *FinanceRepo) ListEntriesPage(ctx context.Context, userID int64, limit, offset int) ([]*finance.FinanceEntry, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT `+entryColumns+`
+        FROM finance_entries
+        WHERE user_id=$1
+        ORDER BY occurred_at DESC, id DESC
+        LIMIT $2 OFFSET $3
+    `, userID, limit, offset)
+	if err != nil {
+		logger.Error("FinanceRepo.ListEntriesPage error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*finance.FinanceEntry
+	for rows.Next() {
+		var e finance.FinanceEntry
+		if err := scanEntry(rows, &e); err != nil {
+			return nil, err
+		}
+		list = append(list, &e)
+	}
+	return list, rows.Err()
+}
+
+// EntryTotals возвращает доходы, расходы и число операций пользователя за всё время.
+func (r *FinanceRepo) EntryTotals(ctx context.Context, userID int64) (finance.Totals, error) {
+	var t finance.Totals
+	err := r.db.QueryRow(ctx, `
+        SELECT COALESCE(SUM(amount) FILTER (WHERE type = 'income'), 0)::float8,
+               COALESCE(SUM(amount) FILTER (WHERE type = 'expense'), 0)::float8,
+               count(*)
+        FROM finance_entries
+        WHERE user_id=$1
+    `, userID).Scan(&t.Income, &t.Expense, &t.Count)
+	if err != nil {
+		logger.Error("FinanceRepo.EntryTotals error: " + err.Error())
+	}
+	return t, err
+}
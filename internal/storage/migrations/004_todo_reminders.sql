@@ -0,0 +1,25 @@
+-- Напоминания о сроках задач
+
+-- due_date хранится с часовым поясом, чтобы сроки пользователей
+-- из разных поясов сравнивались корректно
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'todos' AND column_name = 'due_date'
+          AND data_type = 'timestamp without time zone'
+    ) THEN
+        ALTER TABLE todos ALTER COLUMN due_date TYPE TIMESTAMPTZ USING due_date AT TIME ZONE 'UTC';
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_todos_status_due_date ON todos (status, due_date);
+
+-- Отправленные напоминания: по одной записи на задачу и интервал упреждения.
+-- Не даёт повторно отправить напоминание после перезапуска бота.
+CREATE TABLE IF NOT EXISTS todo_reminders (
+    todo_id INT NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    lead_minutes INT NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (todo_id, lead_minutes)
+);
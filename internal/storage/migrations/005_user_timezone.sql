@@ -0,0 +1,3 @@
+-- Часовой пояс пользователя (IANA, например Europe/Moscow).
+-- NULL означает часовой пояс по умолчанию (DEFAULT_TIMEZONE).
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone TEXT;
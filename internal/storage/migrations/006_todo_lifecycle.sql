@@ -0,0 +1,3 @@
+-- Жизненный цикл задач: время завершения и последнего изменения
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
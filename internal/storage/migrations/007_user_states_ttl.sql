@@ -0,0 +1,4 @@
+-- Время последнего изменения состояния диалога (для истечения брошенных диалогов)
+ALTER TABLE user_states ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_user_states_updated_at ON user_states (updated_at);
@@ -0,0 +1,26 @@
+-- Уровни приоритета задач
+CREATE TABLE IF NOT EXISTS todo_priorities (
+    level SMALLINT PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    title TEXT NOT NULL
+);
+
+INSERT INTO todo_priorities (level, code, title) VALUES
+    (1, 'low', 'Низкий'),
+    (2, 'normal', 'Обычный'),
+    (3, 'high', 'Высокий'),
+    (4, 'urgent', 'Срочный')
+ON CONFLICT (level) DO NOTHING;
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 2 REFERENCES todo_priorities(level);
+
+CREATE INDEX IF NOT EXISTS idx_todos_user_priority ON todos(user_id, priority);
+
+-- Произвольные метки задач
+CREATE TABLE IF NOT EXISTS todo_tags (
+    todo_id INT NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (todo_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_tags_tag ON todo_tags(tag);
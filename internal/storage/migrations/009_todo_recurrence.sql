@@ -0,0 +1,2 @@
+-- Повторяющиеся задачи: правило повторения в формате RRULE (RFC 5545)
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS rrule TEXT;
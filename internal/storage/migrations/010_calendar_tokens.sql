@@ -0,0 +1,6 @@
+-- Токены подписки на календарь (.ics)
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    user_id BIGINT PRIMARY KEY,
+    token TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,11 @@
+-- Настройки утренней сводки
+CREATE TABLE IF NOT EXISTS digest_settings (
+    user_id BIGINT PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    send_minute INT NOT NULL DEFAULT 480 CHECK (send_minute >= 0 AND send_minute < 1440),
+    days_ahead INT NOT NULL DEFAULT 3,
+    daily_budget NUMERIC(14,2) NOT NULL DEFAULT 0,
+    last_sent_on DATE
+);
+
+CREATE INDEX IF NOT EXISTS idx_digest_settings_enabled ON digest_settings(enabled) WHERE enabled;
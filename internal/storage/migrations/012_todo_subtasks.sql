@@ -0,0 +1,19 @@
+-- Подзадачи: ссылка на родительскую задачу, удаление родителя удаляет подзадачи
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES todos(id) ON DELETE CASCADE;
+
+-- Автоматически выполнять задачу, когда выполнены все пункты чек-листа и подзадачи
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS auto_complete BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_todos_parent ON todos(parent_id) WHERE parent_id IS NOT NULL;
+
+-- Пункты чек-листа задачи
+CREATE TABLE IF NOT EXISTS todo_checklist (
+    id SERIAL PRIMARY KEY,
+    todo_id INT NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    done BOOLEAN NOT NULL DEFAULT false,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_checklist_todo ON todo_checklist(todo_id, position);
@@ -0,0 +1,19 @@
+-- Политика настойчивых напоминаний о просроченных задачах (по пользователю)
+CREATE TABLE IF NOT EXISTS todo_escalation_policies (
+    user_id BIGINT PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    first_delay_minutes INT NOT NULL DEFAULT 30 CHECK (first_delay_minutes > 0),
+    factor NUMERIC(4,2) NOT NULL DEFAULT 2 CHECK (factor >= 1),
+    max_interval_minutes INT NOT NULL DEFAULT 1440 CHECK (max_interval_minutes > 0),
+    max_nudges INT NOT NULL DEFAULT 5 CHECK (max_nudges >= 0),
+    morning_minute INT NOT NULL DEFAULT 540 CHECK (morning_minute >= 0 AND morning_minute < 1440)
+);
+
+-- Состояние напоминаний о просроченной задаче.
+-- Запись удаляется при изменении срока задачи (в том числе при откладывании).
+CREATE TABLE IF NOT EXISTS todo_escalations (
+    todo_id INT PRIMARY KEY REFERENCES todos(id) ON DELETE CASCADE,
+    nudges INT NOT NULL DEFAULT 0,
+    last_nudge_at TIMESTAMPTZ,
+    acknowledged_at TIMESTAMPTZ
+);
@@ -0,0 +1,28 @@
+-- Проекты (списки) задач
+CREATE TABLE IF NOT EXISTS todo_projects (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name TEXT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    sort_order TEXT NOT NULL DEFAULT 'created',
+    archived BOOLEAN NOT NULL DEFAULT false,
+    is_inbox BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (user_id, name)
+);
+
+-- У каждого пользователя не больше одного проекта «Inbox»
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_projects_inbox ON todo_projects(user_id) WHERE is_inbox;
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS project_id INT REFERENCES todo_projects(id);
+
+CREATE INDEX IF NOT EXISTS idx_todos_project ON todos(project_id);
+
+-- Существующие задачи переносятся в «Inbox» своего пользователя
+INSERT INTO todo_projects (user_id, name, is_inbox)
+SELECT DISTINCT user_id, 'Inbox', true FROM todos WHERE project_id IS NULL
+ON CONFLICT DO NOTHING;
+
+UPDATE todos t SET project_id = p.id
+FROM todo_projects p
+WHERE t.project_id IS NULL AND p.user_id = t.user_id AND p.is_inbox;
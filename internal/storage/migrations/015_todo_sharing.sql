@@ -0,0 +1,20 @@
+-- Участники общих проектов задач
+CREATE TABLE IF NOT EXISTS todo_project_members (
+    project_id INT NOT NULL REFERENCES todo_projects(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('view', 'edit')),
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_project_members_user ON todo_project_members(user_id);
+
+-- Одноразовые приглашения в проект (ссылка t.me/<bot>?start=join_<token>)
+CREATE TABLE IF NOT EXISTS todo_project_invites (
+    token TEXT PRIMARY KEY,
+    project_id INT NOT NULL REFERENCES todo_projects(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('view', 'edit')),
+    created_by BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,15 @@
+-- Учёт времени по задачам
+CREATE TABLE IF NOT EXISTS todo_time_entries (
+    id SERIAL PRIMARY KEY,
+    todo_id INT NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    stopped_at TIMESTAMPTZ,
+    CHECK (stopped_at IS NULL OR stopped_at >= started_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_time_entries_user ON todo_time_entries(user_id, started_at);
+CREATE INDEX IF NOT EXISTS idx_todo_time_entries_todo ON todo_time_entries(todo_id);
+
+-- У пользователя не больше одного запущенного таймера
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_time_entries_running ON todo_time_entries(user_id) WHERE stopped_at IS NULL;
@@ -0,0 +1,23 @@
+-- Полнотекстовый поиск: векторы с русской и английской морфологией вычисляются при записи
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('russian', title || ' ' || COALESCE(description, '')) ||
+    to_tsvector('english', title || ' ' || COALESCE(description, ''))
+) STORED;
+
+ALTER TABLE finance_entries ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('russian', COALESCE(note, '') || ' ' || COALESCE(category, '')) ||
+    to_tsvector('english', COALESCE(note, '') || ' ' || COALESCE(category, ''))
+) STORED;
+
+ALTER TABLE recurring_payments ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('russian', title) || to_tsvector('english', title)
+) STORED;
+
+ALTER TABLE credits ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('russian', title) || to_tsvector('english', title)
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_todos_search ON todos USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_finance_entries_search ON finance_entries USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_recurring_payments_search ON recurring_payments USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_credits_search ON credits USING GIN (search_vector);
@@ -0,0 +1,13 @@
+-- Канбан-доска: порядок задач в колонке и настраиваемые колонки пользователя
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_todos_board ON todos(status, position);
+
+-- Колонка доски показывает задачи одного статуса; без настроенных колонок используются колонки по умолчанию
+CREATE TABLE IF NOT EXISTS todo_board_columns (
+    user_id BIGINT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'in_progress', 'completed')),
+    name TEXT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, status)
+);
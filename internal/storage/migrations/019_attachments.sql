@@ -0,0 +1,22 @@
+-- Вложения: файлы Telegram (по file_id), прикреплённые к задачам и финансовым операциям
+CREATE TABLE IF NOT EXISTS attachments (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    todo_id INT REFERENCES todos(id) ON DELETE CASCADE,
+    finance_entry_id INT REFERENCES finance_entries(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('photo', 'document', 'voice', 'audio', 'video')),
+    file_id TEXT NOT NULL,
+    file_unique_id TEXT NOT NULL,
+    file_name TEXT NOT NULL DEFAULT '',
+    mime_type TEXT NOT NULL DEFAULT '',
+    size BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((todo_id IS NULL) <> (finance_entry_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_todo ON attachments(todo_id) WHERE todo_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_attachments_finance ON attachments(finance_entry_id) WHERE finance_entry_id IS NOT NULL;
+
+-- Один и тот же файл прикрепляется к записи один раз
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attachments_todo_file ON attachments(todo_id, file_unique_id) WHERE todo_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attachments_finance_file ON attachments(finance_entry_id, file_unique_id) WHERE finance_entry_id IS NOT NULL;
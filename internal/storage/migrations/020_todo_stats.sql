@@ -0,0 +1,6 @@
+-- Статистика выполнения задач опирается на completed_at: заполняем его у задач,
+-- выполненных до появления колонки, временем последнего изменения
+UPDATE todos SET completed_at = COALESCE(updated_at, created_at AT TIME ZONE 'UTC')
+WHERE status = 'completed' AND completed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_todos_user_completed_at ON todos (user_id, completed_at) WHERE completed_at IS NOT NULL;
@@ -0,0 +1,14 @@
+-- История изменений финансовых операций: состояние до и после каждого создания,
+-- изменения и удаления. Ссылки на finance_entries нет, чтобы история удалённых операций сохранялась.
+CREATE TABLE IF NOT EXISTS finance_entry_history (
+    id SERIAL PRIMARY KEY,
+    entry_id INT NOT NULL,
+    user_id BIGINT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    before JSONB,
+    after JSONB,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_finance_entry_history_user ON finance_entry_history (user_id, changed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_finance_entry_history_entry ON finance_entry_history (entry_id);
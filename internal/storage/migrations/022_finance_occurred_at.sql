@@ -0,0 +1,8 @@
+-- Дата совершения операции, отдельная от времени её записи: позволяет вносить операции задним числом.
+-- Существующие операции считаются совершёнными в момент записи (created_at хранится в UTC).
+ALTER TABLE finance_entries ADD COLUMN IF NOT EXISTS occurred_at TIMESTAMPTZ;
+UPDATE finance_entries SET occurred_at = COALESCE(created_at AT TIME ZONE 'UTC', now()) WHERE occurred_at IS NULL;
+ALTER TABLE finance_entries ALTER COLUMN occurred_at SET DEFAULT now();
+ALTER TABLE finance_entries ALTER COLUMN occurred_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_finance_entries_user_occurred ON finance_entries (user_id, occurred_at DESC);
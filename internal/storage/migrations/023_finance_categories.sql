@@ -0,0 +1,19 @@
+-- Справочник категорий операций: у каждого пользователя свои категории доходов и расходов
+-- с иконками и одним уровнем вложенности. Справочник заполняется при первом обращении (см. EnsureCategories).
+CREATE TABLE IF NOT EXISTS finance_categories (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('income', 'expense')),
+    parent_id INT REFERENCES finance_categories(id) ON DELETE SET NULL,
+    icon TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- «Еда» и «еда» — одна категория
+CREATE UNIQUE INDEX IF NOT EXISTS idx_finance_categories_name ON finance_categories (user_id, type, lower(name));
+CREATE INDEX IF NOT EXISTS idx_finance_categories_parent ON finance_categories (parent_id);
+
+-- Операции ссылаются на категорию справочника; текстовое имя остаётся для отчётов и поиска
+ALTER TABLE finance_entries ADD COLUMN IF NOT EXISTS category_id INT REFERENCES finance_categories(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_finance_entries_category ON finance_entries (category_id);
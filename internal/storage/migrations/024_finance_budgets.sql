@@ -0,0 +1,12 @@
+-- Месячные бюджеты расходов: на категорию (вместе с подкатегориями) или общий (category_id IS NULL).
+-- У пользователя не больше одного бюджета на категорию и одного общего.
+CREATE TABLE IF NOT EXISTS finance_budgets (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    category_id INT REFERENCES finance_categories(id) ON DELETE CASCADE,
+    amount NUMERIC(14,2) NOT NULL CHECK (amount > 0),
+    rollover TEXT NOT NULL DEFAULT 'none' CHECK (rollover IN ('none', 'unused', 'all')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_finance_budgets_category ON finance_budgets (user_id, (COALESCE(category_id, 0)));
@@ -0,0 +1,4 @@
+-- День месяца, на который назначен регулярный платёж. Ежемесячные и ежегодные платежи в коротких
+-- месяцах переносятся на последний день, а следующий платёж снова приходится на pay_day.
+ALTER TABLE recurring_payments ADD COLUMN IF NOT EXISTS pay_day SMALLINT;
+UPDATE recurring_payments SET pay_day = EXTRACT(DAY FROM next_payment) WHERE pay_day IS NULL;
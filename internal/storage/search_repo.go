@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"strings"
+
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/search"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SearchRepo выполняет полнотекстовый поиск по колонкам search_vector (см. миграцию 017).
+type SearchRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSearchRepo(db *pgxpool.Pool) *SearchRepo {
+	return &SearchRepo{db: db}
+}
+
+// searchQuery — запрос tsquery: все слова как префиксы в русской или английской морфологии.
+const searchQuery = `(to_tsquery('russian', $2) || to_tsquery('english', $2))`
+
+// Search ищет задачи (свои и общих проектов, кроме архивных), операции, регулярные платежи и кредиты пользователя.
+// terms должны состоять только из букв и цифр (см. search.Terms).
+// Даты колонок TIMESTAMP и DATE хранятся в UTC и приводятся к TIMESTAMPTZ.
+func (r *SearchRepo) Search(ctx context.Context, userID int64, terms []string, limit int) ([]search.Hit, error) {
+	query := strings.Join(terms, ":* & ") + ":*"
+
+	rows, err := r.db.Query(ctx, `
+        (SELECT 'todo', id, title, COALESCE(description, ''), 0::float8, due_date,
+                status = 'completed', ts_rank(search_vector, `+searchQuery+`)
+         FROM todos
+         WHERE `+todoAccess("todos", "$1", false)+` AND `+notArchived+` AND search_vector @@ `+searchQuery+`
+         ORDER BY 8 DESC, id DESC
+         LIMIT $3)
+        UNION ALL
+        (SELECT 'finance', id, COALESCE(NULLIF(note, ''), category, ''), COALESCE(category, ''),
+                CASE WHEN type = 'expense' THEN -amount ELSE amount END::float8, occurred_at,
+                false, ts_rank(search_vector, `+searchQuery+`)
+         FROM finance_entries
+         WHERE user_id=$1 AND search_vector @@ `+searchQuery+`
+         ORDER BY 8 DESC, id DESC
+         LIMIT $3)
+        UNION ALL
+        (SELECT 'recurring', id, title, COALESCE(category, ''), amount::float8, next_payment::timestamp AT TIME ZONE 'UTC',
+                false, ts_rank(search_vector, `+searchQuery+`)
+         FROM recurring_payments
+         WHERE user_id=$1 AND search_vector @@ `+searchQuery+`
+         ORDER BY 8 DESC, id DESC
+         LIMIT $3)
+        UNION ALL
+        (SELECT 'credit', id, title, '', principal::float8, created_at AT TIME ZONE 'UTC',
+                false, ts_rank(search_vector, `+searchQuery+`)
+         FROM credits
+         WHERE user_id=$1 AND search_vector @@ `+searchQuery+`
+         ORDER BY 8 DESC, id DESC
+         LIMIT $3)
+    `, userID, query, limit)
+	if err != nil {
+		logger.Error("SearchRepo.Search error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []search.Hit
+	for rows.Next() {
+		var (
+			h      search.Hit
+			module string
+			rank   float32
+		)
+		if err := rows.Scan(&module, &h.ID, &h.Title, &h.Detail, &h.Amount, &h.Date, &h.Done, &rank); err != nil {
+			return nil, err
+		}
+		h.Module = search.Module(module)
+		h.Rank = float64(rank)
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
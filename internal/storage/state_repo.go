@@ -2,9 +2,12 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"tg_bot_asist/internal/logger"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -18,11 +21,13 @@ func NewStateRepo(db *pgxpool.Pool) *StateRepo {
 
 func (r *StateRepo) Save(ctx context.Context, userID int64, state string) error {
 	_, err := r.db.Exec(ctx, `
-        INSERT INTO user_states (user_id, state_json)
-        VALUES ($1,$2)
-        ON CONFLICT (user_id) DO UPDATE SET state_json = EXCLUDED.state_json
+        INSERT INTO user_states (user_id, state_json, updated_at)
+        VALUES ($1,$2,$3)
+        ON CONFLICT (user_id) DO UPDATE SET
+            state_json = EXCLUDED.state_json,
+            updated_at = EXCLUDED.updated_at
     `,
-		userID, state,
+		userID, state, time.Now(),
 	)
 
 	if err != nil {
@@ -32,22 +37,25 @@ func (r *StateRepo) Save(ctx context.Context, userID int64, state string) error
 	return err
 }
 
-func (r *StateRepo) Get(ctx context.Context, userID int64) (string, error) {
+// Get возвращает сохранённое состояние и время его последнего изменения.
+// Если состояния нет, возвращает пустую строку.
+func (r *StateRepo) Get(ctx context.Context, userID int64) (string, time.Time, error) {
 	var s string
+	var updatedAt time.Time
 
 	err := r.db.QueryRow(ctx, `
-        SELECT state_json FROM user_states WHERE user_id=$1
-    `, userID).Scan(&s)
+        SELECT state_json, updated_at FROM user_states WHERE user_id=$1
+    `, userID).Scan(&s, &updatedAt)
 
 	if err != nil {
-		if err.Error() == "no rows in result set" {
-			return "", nil
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", time.Time{}, nil
 		}
 		logger.Error("StateRepo.Get error: " + err.Error())
-		return "", err
+		return "", time.Time{}, err
 	}
 
-	return s, nil
+	return s, updatedAt, nil
 }
 
 func (r *StateRepo) Delete(ctx context.Context, userID int64) error {
@@ -59,3 +67,15 @@ func (r *StateRepo) Delete(ctx context.Context, userID int64) error {
 
 	return err
 }
+
+// DeleteExpired удаляет состояния, которые не менялись с момента before.
+func (r *StateRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM user_states WHERE updated_at < $1`, before)
+
+	if err != nil {
+		logger.Error("StateRepo.DeleteExpired error: " + err.Error())
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
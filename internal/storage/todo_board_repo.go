@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+)
+
+// boardOrder — порядок задач в колонке доски. Новые задачи имеют position 0 и оказываются вверху колонки.
+const boardOrder = "position, created_at DESC, id DESC"
+
+// boardScope возвращает условие «задача верхнего уровня на доске пользователя $1»:
+// проект projectParam или все проекты, кроме архивных, если он равен 0.
+func boardScope(projectParam string) string {
+	return todoAccess("todos", "$1", false) + ` AND parent_id IS NULL
+          AND ((` + projectParam + ` = 0 AND ` + notArchived + `) OR project_id = ` + projectParam + `)`
+}
+
+// ListColumns возвращает настроенные колонки доски пользователя.
+func (r *TodoRepo) ListColumns(ctx context.Context, userID int64) ([]todo.BoardColumn, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT status, name, position
+        FROM todo_board_columns
+        WHERE user_id=$1
+        ORDER BY position, status
+    `, userID)
+	if err != nil {
+		logger.Error("TodoRepo.ListColumns error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cols []todo.BoardColumn
+	for rows.Next() {
+		var c todo.BoardColumn
+		if err := rows.Scan(&c.Status, &c.Name, &c.Position); err != nil {
+			return nil, err
+		}
+		cols = append(cols, c)
+	}
+	return cols, rows.Err()
+}
+
+// SaveColumns заменяет колонки доски пользователя.
+func (r *TodoRepo) SaveColumns(ctx context.Context, userID int64, cols []todo.BoardColumn) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("TodoRepo.SaveColumns begin error: " + err.Error())
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM todo_board_columns WHERE user_id=$1`, userID); err != nil {
+		logger.Error("TodoRepo.SaveColumns delete error: " + err.Error())
+		return err
+	}
+	for _, c := range cols {
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO todo_board_columns (user_id, status, name, position)
+            VALUES ($1, $2, $3, $4)
+        `, userID, c.Status, c.Name, c.Position); err != nil {
+			logger.Error("TodoRepo.SaveColumns insert error: " + err.Error())
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ListBoard возвращает задачи доски в порядке колонки; выполненные — завершённые не раньше doneSince.
+func (r *TodoRepo) ListBoard(ctx context.Context, userID int64, projectID int, doneSince time.Time) ([]todo.Item, error) {
+	return r.queryTodos(ctx, "ListBoard", `
+        SELECT `+todoColumns+`
+        FROM todos
+        WHERE `+boardScope("$2")+`
+          AND (status <> 'completed' OR completed_at >= $3)
+        ORDER BY `+boardOrder+`
+    `,
+		userID, projectID, doneSince,
+	)
+}
+
+// MoveOnBoard меняет статус задачи и перенумеровывает её колонку в одной транзакции.
+// Задачи колонки блокируются на время перестановки, поэтому одновременные перемещения не перемешивают порядок.
+// Как и в ListBoard, выполненные задачи, завершённые раньше doneSince, в колонку не входят.
+func (r *TodoRepo) MoveOnBoard(ctx context.Context, userID int64, m todo.BoardMove, now, doneSince time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("TodoRepo.MoveOnBoard begin error: " + err.Error())
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+        UPDATE todos
+        SET status=$2,
+            completed_at = CASE WHEN $2 = 'completed' THEN COALESCE(completed_at, $3) END,
+            updated_at=$3
+        WHERE id=$1 AND parent_id IS NULL
+    `, m.TodoID, m.Status, now)
+	if err != nil {
+		logger.Error("TodoRepo.MoveOnBoard status error: " + err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return todo.ErrNotFound
+	}
+
+	rows, err := tx.Query(ctx, `
+        SELECT id
+        FROM todos
+        WHERE `+boardScope("$2")+` AND status=$3
+          AND (status <> 'completed' OR completed_at >= $4)
+        ORDER BY `+boardOrder+`
+        FOR UPDATE OF todos
+    `, userID, m.ProjectID, m.Status, doneSince)
+	if err != nil {
+		logger.Error("TodoRepo.MoveOnBoard lock error: " + err.Error())
+		return err
+	}
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		if id != m.TodoID {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		logger.Error("TodoRepo.MoveOnBoard lock error: " + err.Error())
+		return err
+	}
+
+	pos := min(m.Position, len(ids))
+	ids = append(ids[:pos], append([]int{m.TodoID}, ids[pos:]...)...)
+
+	if _, err := tx.Exec(ctx, `
+        UPDATE todos t
+        SET position = v.ord - 1
+        FROM unnest($1::int[]) WITH ORDINALITY AS v(id, ord)
+        WHERE t.id = v.id
+    `, ids); err != nil {
+		logger.Error("TodoRepo.MoveOnBoard reorder error: " + err.Error())
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// projectColumns возвращает колонки проекта в порядке, который ожидает scanProject;
+// роль вычисляется для пользователя из параметра param.
+func projectColumns(param string) string {
+	return `id, user_id, name, position, sort_order, archived, is_inbox,
+        (SELECT count(*) FROM todos t WHERE t.project_id = todo_projects.id AND t.status <> 'completed'),
+        CASE WHEN user_id = ` + param + ` THEN 'owner'
+             ELSE (SELECT m.role FROM todo_project_members m WHERE m.project_id = todo_projects.id AND m.user_id = ` + param + `) END,
+        (SELECT count(*) FROM todo_project_members m WHERE m.project_id = todo_projects.id)`
+}
+
+// projectAccess возвращает условие «пользователь param — владелец или участник проекта».
+func projectAccess(param string) string {
+	return `(user_id = ` + param + `
+            OR EXISTS (SELECT 1 FROM todo_project_members m WHERE m.project_id = todo_projects.id AND m.user_id = ` + param + `))`
+}
+
+func scanProject(row pgx.Row, p *todo.Project) error {
+	return row.Scan(&p.ID, &p.UserID, &p.Name, &p.Position, &p.SortOrder, &p.Archived, &p.Inbox, &p.Open, &p.Role, &p.Members)
+}
+
+// isUniqueViolation сообщает, что запрос нарушил ограничение уникальности.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// ListProjects возвращает проекты пользователя и общие проекты: Inbox первым, затем свои по position, затем общие.
+func (r *TodoRepo) ListProjects(ctx context.Context, userID int64, includeArchived bool) ([]todo.Project, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT `+projectColumns("$1")+`
+        FROM todo_projects
+        WHERE `+projectAccess("$1")+` AND ($2 OR NOT archived)
+        ORDER BY is_inbox DESC, archived, user_id <> $1, position, id
+    `, userID, includeArchived)
+	if err != nil {
+		logger.Error("TodoRepo.ListProjects error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []todo.Project
+	for rows.Next() {
+		var p todo.Project
+		if err := scanProject(rows, &p); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// GetProject возвращает проект, владельцем или участником которого является пользователь, или todo.ErrProjectNotFound.
+func (r *TodoRepo) GetProject(ctx context.Context, userID int64, id int) (*todo.Project, error) {
+	var p todo.Project
+	err := scanProject(r.db.QueryRow(ctx, `
+        SELECT `+projectColumns("$2")+`
+        FROM todo_projects
+        WHERE id=$1 AND `+projectAccess("$2")+`
+    `, id, userID), &p)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, todo.ErrProjectNotFound
+		}
+		logger.Error("TodoRepo.GetProject error: " + err.Error())
+		return nil, err
+	}
+	return &p, nil
+}
+
+// EnsureInbox возвращает Inbox пользователя, создавая его при первом обращении.
+func (r *TodoRepo) EnsureInbox(ctx context.Context, userID int64) (*todo.Project, error) {
+	_, err := r.db.Exec(ctx, `
+        INSERT INTO todo_projects (user_id, name, is_inbox)
+        VALUES ($1, $2, true)
+        ON CONFLICT DO NOTHING
+    `, userID, todo.InboxName)
+	if err != nil {
+		logger.Error("TodoRepo.EnsureInbox error: " + err.Error())
+		return nil, err
+	}
+
+	var p todo.Project
+	err = scanProject(r.db.QueryRow(ctx, `
+        SELECT `+projectColumns("$1")+`
+        FROM todo_projects
+        WHERE user_id=$1 AND is_inbox
+    `, userID), &p)
+	if err != nil {
+		logger.Error("TodoRepo.EnsureInbox select error: " + err.Error())
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CreateProject добавляет проект в конец списка проектов пользователя.
+func (r *TodoRepo) CreateProject(ctx context.Context, p *todo.Project) error {
+	err := r.db.QueryRow(ctx, `
+        INSERT INTO todo_projects (user_id, name, sort_order, position)
+        VALUES ($1, $2, $3, COALESCE((SELECT max(position) + 1 FROM todo_projects WHERE user_id=$1), 0))
+        RETURNING id, position
+    `, p.UserID, p.Name, p.SortOrder).Scan(&p.ID, &p.Position)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return todo.ErrProjectExists
+		}
+		logger.Error("TodoRepo.CreateProject error: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// UpdateProject сохраняет имя, позицию, порядок сортировки и признак архива проекта.
+func (r *TodoRepo) UpdateProject(ctx context.Context, p *todo.Project) error {
+	tag, err := r.db.Exec(ctx, `
+        UPDATE todo_projects
+        SET name=$3, position=$4, sort_order=$5, archived=$6
+        WHERE id=$1 AND user_id=$2
+    `, p.ID, p.UserID, p.Name, p.Position, p.SortOrder, p.Archived)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return todo.ErrProjectExists
+		}
+		logger.Error("TodoRepo.UpdateProject error: " + err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return todo.ErrProjectNotFound
+	}
+	return nil
+}
+
+// DeleteProject переносит задачи проекта (в том числе задачи участников) в Inbox владельца и удаляет проект
+// в одной транзакции. Участники и приглашения удаляются каскадно.
+func (r *TodoRepo) DeleteProject(ctx context.Context, userID int64, id int) error {
+	inbox, err := r.EnsureInbox(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("TodoRepo.DeleteProject begin error: " + err.Error())
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+        UPDATE todos SET project_id=$3, user_id=$2
+        WHERE project_id=$1 AND EXISTS (SELECT 1 FROM todo_projects WHERE id=$1 AND user_id=$2)
+    `, id, userID, inbox.ID); err != nil {
+		logger.Error("TodoRepo.DeleteProject move error: " + err.Error())
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+        DELETE FROM todo_projects WHERE id=$1 AND user_id=$2 AND NOT is_inbox
+    `, id, userID)
+	if err != nil {
+		logger.Error("TodoRepo.DeleteProject error: " + err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return todo.ErrProjectNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+// MoveTodo переносит задачу и её подзадачи в проект.
+// Задачи, автор которых не владелец и не участник проекта, переходят владельцу проекта.
+func (r *TodoRepo) MoveTodo(ctx context.Context, todoID, projectID int) error {
+	tag, err := r.db.Exec(ctx, `
+        UPDATE todos t
+        SET project_id=p.id, updated_at=now(),
+            user_id = CASE WHEN t.user_id = p.user_id
+                             OR EXISTS (SELECT 1 FROM todo_project_members m WHERE m.project_id = p.id AND m.user_id = t.user_id)
+                           THEN t.user_id ELSE p.user_id END
+        FROM todo_projects p
+        WHERE p.id=$2 AND (t.id=$1 OR t.parent_id=$1)
+    `, todoID, projectID)
+
+	if err != nil {
+		logger.Error("TodoRepo.MoveTodo error: " + err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return todo.ErrNotFound
+	}
+	return nil
+}
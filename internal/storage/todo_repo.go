@@ -2,14 +2,29 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"tg_bot_asist/internal/logger"
 	"tg_bot_asist/internal/todo"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// todoColumns — список колонок задачи в порядке, который ожидает scanTodo.
+// Метки и прогресс собираются подзапросами, поэтому таблица todos в запросе не должна иметь псевдонима.
+const todoColumns = `id, user_id, title, description, due_date, status, created_at, completed_at, priority, COALESCE(rrule, ''),
+        COALESCE((SELECT array_agg(tag ORDER BY tag) FROM todo_tags WHERE todo_id = todos.id), '{}'),
+        parent_id, COALESCE(project_id, 0), auto_complete,
+        (SELECT count(*) FILTER (WHERE c.done) FROM todo_checklist c WHERE c.todo_id = todos.id)
+            + (SELECT count(*) FILTER (WHERE s.status = 'completed') FROM todos s WHERE s.parent_id = todos.id),
+        (SELECT count(*) FROM todo_checklist c WHERE c.todo_id = todos.id)
+            + (SELECT count(*) FROM todos s WHERE s.parent_id = todos.id),
+        position`
+
 type TodoRepo struct {
 	db *pgxpool.Pool
 }
@@ -18,16 +33,78 @@ func NewTodoRepo(db *pgxpool.Pool) *TodoRepo {
 	return &TodoRepo{db: db}
 }
 
+// scanTodo читает строку с колонками todoColumns; extra — колонки, следующие за ними.
+func scanTodo(row pgx.Row, it *todo.Item, extra ...any) error {
+	var desc *string
+	dest := []any{&it.ID, &it.UserID, &it.Title, &desc, &it.DueDate, &it.Status, &it.CreatedAt, &it.CompletedAt, &it.Priority, &it.RRule, &it.Tags,
+		&it.ParentID, &it.ProjectID, &it.AutoComplete, &it.Progress.Done, &it.Progress.Total, &it.Position}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	if desc != nil {
+		it.Description = *desc
+	}
+	return nil
+}
+
+// queryTodos выполняет запрос, возвращающий колонки todoColumns, и собирает список задач.
+func (r *TodoRepo) queryTodos(ctx context.Context, op, sql string, args ...any) ([]todo.Item, error) {
+
+	rows, err := r.db.Query(ctx, sql, args...)
+
+	if err != nil {
+		logger.Error("TodoRepo." + op + " error: " + err.Error())
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var list []todo.Item
+
+	for rows.Next() {
+		var it todo.Item
+		if err := scanTodo(rows, &it); err != nil {
+			return nil, err
+		}
+
+		list = append(list, it)
+	}
+
+	return list, rows.Err()
+}
+
+// replaceTags заменяет метки задачи в рамках транзакции.
+func replaceTags(ctx context.Context, tx pgx.Tx, todoID int, tags []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM todo_tags WHERE todo_id=$1`, todoID); err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+        INSERT INTO todo_tags (todo_id, tag)
+        SELECT $1, unnest($2::text[])
+        ON CONFLICT DO NOTHING
+    `, todoID, tags)
+	return err
+}
+
 func (r *TodoRepo) Create(ctx context.Context, t *todo.Item) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("TodoRepo.Create begin error: " + err.Error())
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
 
 	var id int
 
-	err := r.db.QueryRow(ctx, `
-        INSERT INTO todos (user_id, title, description, due_date, status, created_at)
-        VALUES ($1,$2,$3,$4,$5,$6)
+	err = tx.QueryRow(ctx, `
+        INSERT INTO todos (user_id, title, description, due_date, status, created_at, priority, rrule, parent_id, auto_complete, project_id)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,NULLIF($8,''),$9,$10,NULLIF($11,0))
         RETURNING id
     `,
-		t.UserID, t.Title, t.Description, t.DueDate, t.Status, time.Now(),
+		t.UserID, t.Title, t.Description, t.DueDate, t.Status, time.Now(), t.Priority, t.RRule, t.ParentID, t.AutoComplete, t.ProjectID,
 	).Scan(&id)
 
 	if err != nil {
@@ -35,53 +112,455 @@ func (r *TodoRepo) Create(ctx context.Context, t *todo.Item) (int, error) {
 		return 0, err
 	}
 
-	return id, nil
+	if err := replaceTags(ctx, tx, id, t.Tags); err != nil {
+		logger.Error("TodoRepo.Create tags error: " + err.Error())
+		return 0, err
+	}
+
+	return id, tx.Commit(ctx)
 }
 
-func (r *TodoRepo) List(ctx context.Context, userID int64) ([]todo.Item, error) {
+// notArchived — условие «задача не в архивном проекте».
+const notArchived = `NOT EXISTS (SELECT 1 FROM todo_projects p WHERE p.id = todos.project_id AND p.archived)`
 
-	rows, err := r.db.Query(ctx, `
-        SELECT id, user_id, title, description, due_date, status, created_at
+// todoAccess возвращает условие «пользователь param имеет доступ к задаче t»: это его задача,
+// он владелец её проекта или участник проекта. С edit участник должен иметь право на изменение,
+// а своя задача в чужом проекте доступна только по роли в проекте.
+func todoAccess(t, param string, edit bool) string {
+	own := t + ".user_id = " + param
+	role := ""
+	if edit {
+		own = "(" + t + ".project_id IS NULL AND " + own + ")"
+		role = " AND am.role = 'edit'"
+	}
+	return "(" + own + `
+            OR EXISTS (SELECT 1 FROM todo_projects ap WHERE ap.id = ` + t + `.project_id AND ap.user_id = ` + param + `)
+            OR EXISTS (SELECT 1 FROM todo_project_members am WHERE am.project_id = ` + t + `.project_id AND am.user_id = ` + param + role + `))`
+}
+
+// List возвращает задачи пользователя и общих проектов, кроме задач архивных проектов.
+func (r *TodoRepo) List(ctx context.Context, userID int64) ([]todo.Item, error) {
+	return r.queryTodos(ctx, "List", `
+        SELECT `+todoColumns+`
         FROM todos
-        WHERE user_id=$1
+        WHERE `+todoAccess("todos", "$1", false)+` AND `+notArchived+`
         ORDER BY created_at DESC
     `,
 		userID,
 	)
+}
+
+// ListFiltered возвращает доступные пользователю задачи по метке, приоритету, статусу, диапазону срока и проекту.
+// Без явного порядка задачи с установленным сроком идут первыми в порядке срока, затем по приоритету.
+func (r *TodoRepo) ListFiltered(ctx context.Context, userID int64, q todo.ListQuery) ([]todo.Item, error) {
+	where := []string{todoAccess("todos", "$1", false)}
+	args := []any{userID}
+
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if q.Tag != "" {
+		add("EXISTS (SELECT 1 FROM todo_tags tt WHERE tt.todo_id = todos.id AND tt.tag = $%d)", q.Tag)
+	}
+	if q.Priority != 0 {
+		add("priority = $%d", q.Priority)
+	}
+	if q.PendingOnly {
+		add("status <> $%d", todo.StatusCompleted)
+	}
+	if q.DueFrom != nil {
+		add("due_date >= $%d", *q.DueFrom)
+	}
+	if q.DueBefore != nil {
+		add("due_date < $%d", *q.DueBefore)
+	}
+	if q.ProjectID != 0 {
+		add("project_id = $%d", q.ProjectID)
+	} else {
+		where = append(where, notArchived)
+	}
+
+	return r.queryTodos(ctx, "ListFiltered", `
+        SELECT `+todoColumns+`
+        FROM todos
+        WHERE `+strings.Join(where, " AND ")+`
+        ORDER BY `+todoOrder(q.Order)+`
+    `,
+		args...,
+	)
+}
+
+// todoOrder возвращает ORDER BY для порядка сортировки задач.
+func todoOrder(o todo.SortOrder) string {
+	switch o {
+	case todo.SortCreated:
+		return "created_at DESC, id DESC"
+	case todo.SortDue:
+		return "due_date NULLS LAST, created_at DESC"
+	case todo.SortPriority:
+		return "priority DESC, due_date NULLS LAST, created_at DESC"
+	case todo.SortTitle:
+		return "lower(title), id"
+	}
+	return "due_date NULLS LAST, priority DESC, created_at DESC"
+}
+
+// Get возвращает доступную пользователю задачу по ID или todo.ErrNotFound.
+func (r *TodoRepo) Get(ctx context.Context, userID int64, id int) (*todo.Item, error) {
+	var it todo.Item
+
+	err := scanTodo(r.db.QueryRow(ctx, `
+        SELECT `+todoColumns+`
+        FROM todos
+        WHERE id=$1 AND `+todoAccess("todos", "$2", false)+`
+    `, id, userID), &it)
 
 	if err != nil {
-		logger.Error("TodoRepo.List error: " + err.Error())
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, todo.ErrNotFound
+		}
+		logger.Error("TodoRepo.Get error: " + err.Error())
 		return nil, err
 	}
 
-	defer rows.Close()
+	return &it, nil
+}
 
-	var list []todo.Item
+// Update сохраняет изменяемые поля задачи.
+// Если срок изменился, отметки об отправленных и повторных напоминаниях сбрасываются.
+func (r *TodoRepo) Update(ctx context.Context, t *todo.Item) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("TodoRepo.Update begin error: " + err.Error())
+		return err
+	}
+	defer tx.Rollback(ctx)
 
-	for rows.Next() {
-		var it todo.Item
-		err := rows.Scan(&it.ID, &it.UserID, &it.Title, &it.Description, &it.DueDate, &it.Status, &it.CreatedAt)
+	var dueChanged bool
+	err = tx.QueryRow(ctx, `
+        UPDATE todos t
+        SET title=$3, description=$4, due_date=$5, status=$6, completed_at=$7, updated_at=$8, priority=$9, rrule=NULLIF($10,''), auto_complete=$11
+        FROM (SELECT id, due_date FROM todos WHERE id=$1 AND user_id=$2 FOR UPDATE) old
+        WHERE t.id = old.id
+        RETURNING old.due_date IS DISTINCT FROM t.due_date
+    `,
+		t.ID, t.UserID, t.Title, t.Description, t.DueDate, t.Status, t.CompletedAt, time.Now(), t.Priority, t.RRule, t.AutoComplete,
+	).Scan(&dueChanged)
 
-		if err != nil {
-			return nil, err
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return todo.ErrNotFound
 		}
+		logger.Error("TodoRepo.Update error: " + err.Error())
+		return err
+	}
 
-		list = append(list, it)
+	if err := replaceTags(ctx, tx, t.ID, t.Tags); err != nil {
+		logger.Error("TodoRepo.Update tags error: " + err.Error())
+		return err
 	}
 
-	return list, nil
+	if dueChanged {
+		if _, err := tx.Exec(ctx, `DELETE FROM todo_reminders WHERE todo_id=$1`, t.ID); err != nil {
+			logger.Error("TodoRepo.Update reset reminders error: " + err.Error())
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM todo_escalations WHERE todo_id=$1`, t.ID); err != nil {
+			logger.Error("TodoRepo.Update reset escalation error: " + err.Error())
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListChildren возвращает подзадачи в порядке создания.
+func (r *TodoRepo) ListChildren(ctx context.Context, userID int64, parentID int) ([]todo.Item, error) {
+	return r.queryTodos(ctx, "ListChildren", `
+        SELECT `+todoColumns+`
+        FROM todos
+        WHERE `+todoAccess("todos", "$1", false)+` AND parent_id=$2
+        ORDER BY created_at, id
+    `,
+		userID, parentID,
+	)
 }
 
 func (r *TodoRepo) Delete(ctx context.Context, userID int64, id int) error {
 
-	_, err := r.db.Exec(ctx,
+	tag, err := r.db.Exec(ctx,
 		`DELETE FROM todos WHERE id=$1 AND user_id=$2`,
 		id, userID,
 	)
 
 	if err != nil {
 		logger.Error("TodoRepo.Delete error: " + err.Error())
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return todo.ErrNotFound
+	}
+
+	return nil
+}
+
+// ListDueBefore возвращает незавершённые задачи всех пользователей со сроком не позже until.
+func (r *TodoRepo) ListDueBefore(ctx context.Context, since, until time.Time) ([]todo.Item, error) {
+	return r.queryTodos(ctx, "ListDueBefore", `
+        SELECT `+todoColumns+`
+        FROM todos
+        WHERE status <> 'completed' AND due_date IS NOT NULL AND due_date >= $1 AND due_date <= $2
+        ORDER BY due_date
+    `,
+		since, until,
+	)
+}
+
+// ClaimReminder отмечает напоминание как отправленное.
+// Возвращает false, если такое напоминание уже было отправлено ранее.
+func (r *TodoRepo) ClaimReminder(ctx context.Context, todoID int, lead time.Duration) (bool, error) {
+
+	tag, err := r.db.Exec(ctx, `
+        INSERT INTO todo_reminders (todo_id, lead_minutes, sent_at)
+        VALUES ($1,$2,$3)
+        ON CONFLICT (todo_id, lead_minutes) DO NOTHING
+    `,
+		todoID, int(lead/time.Minute), time.Now(),
+	)
+
+	if err != nil {
+		logger.Error("TodoRepo.ClaimReminder error: " + err.Error())
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReleaseReminder удаляет отметку об отправке напоминания.
+func (r *TodoRepo) ReleaseReminder(ctx context.Context, todoID int, lead time.Duration) error {
+
+	_, err := r.db.Exec(ctx,
+		`DELETE FROM todo_reminders WHERE todo_id=$1 AND lead_minutes=$2`,
+		todoID, int(lead/time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("TodoRepo.ReleaseReminder error: " + err.Error())
+	}
+
+	return err
+}
+
+// ListChecklist возвращает пункты чек-листа задачи.
+func (r *TodoRepo) ListChecklist(ctx context.Context, todoID int) ([]todo.ChecklistItem, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, todo_id, title, done, position
+        FROM todo_checklist
+        WHERE todo_id=$1
+        ORDER BY position, id
+    `, todoID)
+	if err != nil {
+		logger.Error("TodoRepo.ListChecklist error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []todo.ChecklistItem
+	for rows.Next() {
+		var c todo.ChecklistItem
+		if err := rows.Scan(&c.ID, &c.TodoID, &c.Title, &c.Done, &c.Position); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// AddChecklistItem добавляет пункт в конец чек-листа, проверяя, что задача принадлежит пользователю.
+func (r *TodoRepo) AddChecklistItem(ctx context.Context, userID int64, todoID int, title string) (*todo.ChecklistItem, error) {
+	c := todo.ChecklistItem{TodoID: todoID, Title: title}
+
+	err := r.db.QueryRow(ctx, `
+        INSERT INTO todo_checklist (todo_id, title, position)
+        SELECT t.id, $3, COALESCE((SELECT max(position) + 1 FROM todo_checklist WHERE todo_id = t.id), 0)
+        FROM todos t
+        WHERE t.id=$1 AND t.user_id=$2
+        RETURNING id, position
+    `, todoID, userID, title).Scan(&c.ID, &c.Position)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, todo.ErrNotFound
+		}
+		logger.Error("TodoRepo.AddChecklistItem error: " + err.Error())
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ToggleChecklistItem меняет отметку пункта чек-листа задачи, которую пользователь может изменять.
+func (r *TodoRepo) ToggleChecklistItem(ctx context.Context, userID int64, itemID int) (*todo.ChecklistItem, error) {
+	var c todo.ChecklistItem
+
+	err := r.db.QueryRow(ctx, `
+        UPDATE todo_checklist c
+        SET done = NOT c.done
+        FROM todos t
+        WHERE c.id=$1 AND t.id = c.todo_id AND `+todoAccess("t", "$2", true)+`
+        RETURNING c.id, c.todo_id, c.title, c.done, c.position
+    `, itemID, userID).Scan(&c.ID, &c.TodoID, &c.Title, &c.Done, &c.Position)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, todo.ErrChecklistItemNotFound
+		}
+		logger.Error("TodoRepo.ToggleChecklistItem error: " + err.Error())
+		return nil, err
+	}
+	return &c, nil
+}
+
+// DeleteChecklistItem удаляет пункт чек-листа задачи, которую пользователь может изменять.
+func (r *TodoRepo) DeleteChecklistItem(ctx context.Context, userID int64, itemID int) (*todo.ChecklistItem, error) {
+	var c todo.ChecklistItem
+
+	err := r.db.QueryRow(ctx, `
+        DELETE FROM todo_checklist c
+        USING todos t
+        WHERE c.id=$1 AND t.id = c.todo_id AND `+todoAccess("t", "$2", true)+`
+        RETURNING c.id, c.todo_id, c.title, c.done, c.position
+    `, itemID, userID).Scan(&c.ID, &c.TodoID, &c.Title, &c.Done, &c.Position)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, todo.ErrChecklistItemNotFound
+		}
+		logger.Error("TodoRepo.DeleteChecklistItem error: " + err.Error())
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Acknowledge отмечает напоминания о задаче пользователя как принятые.
+func (r *TodoRepo) Acknowledge(ctx context.Context, userID int64, id int) error {
+	tag, err := r.db.Exec(ctx, `
+        INSERT INTO todo_escalations (todo_id, acknowledged_at)
+        SELECT id, $3 FROM todos WHERE id=$1 AND user_id=$2
+        ON CONFLICT (todo_id) DO UPDATE SET acknowledged_at = EXCLUDED.acknowledged_at
+    `, id, userID, time.Now())
+
+	if err != nil {
+		logger.Error("TodoRepo.Acknowledge error: " + err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return todo.ErrNotFound
+	}
+	return nil
+}
+
+// GetEscalationPolicy возвращает политику напоминаний пользователя или политику по умолчанию.
+func (r *TodoRepo) GetEscalationPolicy(ctx context.Context, userID int64) (todo.EscalationPolicy, error) {
+	p := todo.EscalationPolicy{UserID: userID}
+	var firstMin, maxMin int
+
+	err := r.db.QueryRow(ctx, `
+        SELECT enabled, first_delay_minutes, factor::float8, max_interval_minutes, max_nudges, morning_minute
+        FROM todo_escalation_policies
+        WHERE user_id=$1
+    `, userID).Scan(&p.Enabled, &firstMin, &p.Factor, &maxMin, &p.MaxNudges, &p.MorningMinute)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return todo.DefaultEscalationPolicy(userID), nil
+	}
+	if err != nil {
+		logger.Error("TodoRepo.GetEscalationPolicy error: " + err.Error())
+		return p, err
+	}
+
+	p.FirstDelay = time.Duration(firstMin) * time.Minute
+	p.MaxInterval = time.Duration(maxMin) * time.Minute
+	return p, nil
+}
+
+// SaveEscalationPolicy сохраняет политику напоминаний пользователя.
+func (r *TodoRepo) SaveEscalationPolicy(ctx context.Context, p todo.EscalationPolicy) error {
+	_, err := r.db.Exec(ctx, `
+        INSERT INTO todo_escalation_policies
+            (user_id, enabled, first_delay_minutes, factor, max_interval_minutes, max_nudges, morning_minute)
+        VALUES ($1,$2,$3,$4,$5,$6,$7)
+        ON CONFLICT (user_id) DO UPDATE SET
+            enabled = EXCLUDED.enabled,
+            first_delay_minutes = EXCLUDED.first_delay_minutes,
+            factor = EXCLUDED.factor,
+            max_interval_minutes = EXCLUDED.max_interval_minutes,
+            max_nudges = EXCLUDED.max_nudges,
+            morning_minute = EXCLUDED.morning_minute
+    `,
+		p.UserID, p.Enabled, int(p.FirstDelay/time.Minute), p.Factor, int(p.MaxInterval/time.Minute), p.MaxNudges, p.MorningMinute,
+	)
+	if err != nil {
+		logger.Error("TodoRepo.SaveEscalationPolicy error: " + err.Error())
 	}
+	return err
+}
 
+// ListOverdue возвращает просроченные задачи всех пользователей, напоминания о которых не приняты.
+func (r *TodoRepo) ListOverdue(ctx context.Context, now time.Time) ([]todo.Overdue, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT `+todoColumns+`, COALESCE(e.nudges, 0), e.last_nudge_at
+        FROM todos
+        LEFT JOIN todo_escalations e ON e.todo_id = todos.id
+        WHERE todos.status <> 'completed' AND todos.due_date IS NOT NULL AND todos.due_date < $1
+          AND e.acknowledged_at IS NULL
+          AND EXISTS (SELECT 1 FROM todo_reminders r WHERE r.todo_id = todos.id AND r.lead_minutes = 0)
+        ORDER BY todos.due_date
+    `, now)
+	if err != nil {
+		logger.Error("TodoRepo.ListOverdue error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []todo.Overdue
+	for rows.Next() {
+		var o todo.Overdue
+		if err := scanTodo(rows, &o.Item, &o.Nudges, &o.LastNudgeAt); err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+// ClaimNudge фиксирует отправку повторного напоминания, если состояние задачи не изменилось.
+func (r *TodoRepo) ClaimNudge(ctx context.Context, todoID, nudges int, at time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+        INSERT INTO todo_escalations (todo_id, nudges, last_nudge_at)
+        VALUES ($1, $2 + 1, $3)
+        ON CONFLICT (todo_id) DO UPDATE SET nudges = EXCLUDED.nudges, last_nudge_at = EXCLUDED.last_nudge_at
+        WHERE todo_escalations.nudges = $2 AND todo_escalations.acknowledged_at IS NULL
+    `, todoID, nudges, at)
+
+	if err != nil {
+		logger.Error("TodoRepo.ClaimNudge error: " + err.Error())
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReleaseNudge возвращает состояние напоминаний к значению до ClaimNudge.
+func (r *TodoRepo) ReleaseNudge(ctx context.Context, todoID, nudges int, last *time.Time) error {
+	_, err := r.db.Exec(ctx, `
+        UPDATE todo_escalations SET nudges = $2, last_nudge_at = $3
+        WHERE todo_id = $1 AND nudges = $2 + 1
+    `, todoID, nudges, last)
+
+	if err != nil {
+		logger.Error("TodoRepo.ReleaseNudge error: " + err.Error())
+	}
 	return err
 }
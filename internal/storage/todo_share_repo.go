@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateInvite сохраняет приглашение в проект.
+func (r *TodoRepo) CreateInvite(ctx context.Context, inv *todo.Invite) error {
+	_, err := r.db.Exec(ctx, `
+        INSERT INTO todo_project_invites (token, project_id, role, created_by, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+    `, inv.Token, inv.ProjectID, inv.Role, inv.CreatedBy, inv.ExpiresAt)
+	if err != nil {
+		logger.Error("TodoRepo.CreateInvite error: " + err.Error())
+	}
+	return err
+}
+
+// AcceptInvite принимает приглашение в одной транзакции: приглашение блокируется, проверяется,
+// что пользователь ещё не владелец и не участник проекта, затем приглашение удаляется, а участник добавляется.
+// Заодно удаляются все истёкшие приглашения.
+func (r *TodoRepo) AcceptInvite(ctx context.Context, token string, userID int64, now time.Time) (*todo.Invite, error) {
+	if _, err := r.db.Exec(ctx, `DELETE FROM todo_project_invites WHERE expires_at < $1`, now); err != nil {
+		logger.Error("TodoRepo.AcceptInvite cleanup error: " + err.Error())
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("TodoRepo.AcceptInvite begin error: " + err.Error())
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	inv := todo.Invite{Token: token}
+	var ownerID int64
+	err = tx.QueryRow(ctx, `
+        SELECT i.project_id, i.role, i.created_by, i.expires_at, p.user_id
+        FROM todo_project_invites i
+        JOIN todo_projects p ON p.id = i.project_id
+        WHERE i.token=$1 AND i.expires_at >= $2
+        FOR UPDATE OF i
+    `, token, now).Scan(&inv.ProjectID, &inv.Role, &inv.CreatedBy, &inv.ExpiresAt, &ownerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, todo.ErrInviteNotFound
+		}
+		logger.Error("TodoRepo.AcceptInvite error: " + err.Error())
+		return nil, err
+	}
+	if ownerID == userID {
+		return nil, todo.ErrAlreadyMember
+	}
+
+	tag, err := tx.Exec(ctx, `
+        INSERT INTO todo_project_members (project_id, user_id, role)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (project_id, user_id) DO NOTHING
+    `, inv.ProjectID, userID, inv.Role)
+	if err != nil {
+		logger.Error("TodoRepo.AcceptInvite member error: " + err.Error())
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, todo.ErrAlreadyMember
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM todo_project_invites WHERE token=$1`, token); err != nil {
+		logger.Error("TodoRepo.AcceptInvite delete error: " + err.Error())
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("TodoRepo.AcceptInvite commit error: " + err.Error())
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// ListMembers возвращает участников проекта в порядке вступления.
+func (r *TodoRepo) ListMembers(ctx context.Context, projectID int) ([]todo.Member, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT project_id, user_id, role, joined_at
+        FROM todo_project_members
+        WHERE project_id=$1
+        ORDER BY joined_at, user_id
+    `, projectID)
+	if err != nil {
+		logger.Error("TodoRepo.ListMembers error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []todo.Member
+	for rows.Next() {
+		var m todo.Member
+		if err := rows.Scan(&m.ProjectID, &m.UserID, &m.Role, &m.JoinedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
+
+// RemoveMember исключает участника; созданные им задачи проекта переходят владельцу в одной транзакции.
+func (r *TodoRepo) RemoveMember(ctx context.Context, projectID int, userID int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("TodoRepo.RemoveMember begin error: " + err.Error())
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+        DELETE FROM todo_project_members WHERE project_id=$1 AND user_id=$2
+    `, projectID, userID)
+	if err != nil {
+		logger.Error("TodoRepo.RemoveMember error: " + err.Error())
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return todo.ErrNotMember
+	}
+
+	if _, err := tx.Exec(ctx, `
+        UPDATE todos t SET user_id = p.user_id
+        FROM todo_projects p
+        WHERE p.id = t.project_id AND t.project_id=$1 AND t.user_id=$2
+    `, projectID, userID); err != nil {
+		logger.Error("TodoRepo.RemoveMember reassign error: " + err.Error())
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ProjectAudience возвращает название проекта, его владельца и участников.
+func (r *TodoRepo) ProjectAudience(ctx context.Context, projectID int) (string, []int64, error) {
+	var (
+		name    string
+		owner   int64
+		members []int64
+	)
+	err := r.db.QueryRow(ctx, `
+        SELECT p.name, p.user_id, COALESCE(array_agg(m.user_id) FILTER (WHERE m.user_id IS NOT NULL), '{}')
+        FROM todo_projects p
+        LEFT JOIN todo_project_members m ON m.project_id = p.id
+        WHERE p.id=$1
+        GROUP BY p.id
+    `, projectID).Scan(&name, &owner, &members)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil, todo.ErrProjectNotFound
+		}
+		logger.Error("TodoRepo.ProjectAudience error: " + err.Error())
+		return "", nil, err
+	}
+	return name, append([]int64{owner}, members...), nil
+}
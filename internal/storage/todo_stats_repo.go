@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+)
+
+// ListStatsItems возвращает даты собственных задач пользователя, созданных, выполненных или со сроком не раньше since.
+// created_at хранится без часового пояса (в UTC), поэтому приводится к TIMESTAMPTZ.
+func (r *TodoRepo) ListStatsItems(ctx context.Context, userID int64, since time.Time) ([]todo.StatsItem, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT created_at AT TIME ZONE 'UTC', completed_at, due_date
+        FROM todos
+        WHERE user_id=$1
+          AND (created_at AT TIME ZONE 'UTC' >= $2 OR completed_at >= $2 OR due_date >= $2)
+    `, userID, since)
+	if err != nil {
+		logger.Error("TodoRepo.ListStatsItems error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []todo.StatsItem
+	for rows.Next() {
+		var it todo.StatsItem
+		if err := rows.Scan(&it.CreatedAt, &it.CompletedAt, &it.DueDate); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/todo"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// StartTimer запускает таймер; единственность идущего таймера обеспечивает уникальный частичный индекс.
+func (r *TodoRepo) StartTimer(ctx context.Context, userID int64, todoID int, at time.Time) (*todo.TimeEntry, error) {
+	e := todo.TimeEntry{TodoID: todoID, UserID: userID, StartedAt: at}
+	err := r.db.QueryRow(ctx, `
+        INSERT INTO todo_time_entries (todo_id, user_id, started_at)
+        VALUES ($1, $2, $3)
+        RETURNING id
+    `, todoID, userID, at).Scan(&e.ID)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, todo.ErrTimerRunning
+		}
+		logger.Error("TodoRepo.StartTimer error: " + err.Error())
+		return nil, err
+	}
+	return &e, nil
+}
+
+// StopTimer останавливает идущий таймер пользователя.
+func (r *TodoRepo) StopTimer(ctx context.Context, userID int64, at time.Time) (*todo.TimeEntry, error) {
+	var e todo.TimeEntry
+	err := r.db.QueryRow(ctx, `
+        UPDATE todo_time_entries e
+        SET stopped_at = GREATEST($2, e.started_at)
+        FROM todos t
+        WHERE e.user_id=$1 AND e.stopped_at IS NULL AND t.id = e.todo_id
+        RETURNING e.id, e.todo_id, e.user_id, e.started_at, e.stopped_at, t.title
+    `, userID, at).Scan(&e.ID, &e.TodoID, &e.UserID, &e.StartedAt, &e.StoppedAt, &e.Title)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, todo.ErrNoTimer
+		}
+		logger.Error("TodoRepo.StopTimer error: " + err.Error())
+		return nil, err
+	}
+	return &e, nil
+}
+
+// RunningTimer возвращает идущий таймер пользователя или nil.
+func (r *TodoRepo) RunningTimer(ctx context.Context, userID int64) (*todo.TimeEntry, error) {
+	var e todo.TimeEntry
+	err := r.db.QueryRow(ctx, `
+        SELECT e.id, e.todo_id, e.user_id, e.started_at, e.stopped_at, t.title
+        FROM todo_time_entries e
+        JOIN todos t ON t.id = e.todo_id
+        WHERE e.user_id=$1 AND e.stopped_at IS NULL
+    `, userID).Scan(&e.ID, &e.TodoID, &e.UserID, &e.StartedAt, &e.StoppedAt, &e.Title)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		logger.Error("TodoRepo.RunningTimer error: " + err.Error())
+		return nil, err
+	}
+	return &e, nil
+}
+
+// TrackedTime суммирует интервалы пользователя по задаче; идущий таймер считается по now.
+func (r *TodoRepo) TrackedTime(ctx context.Context, userID int64, todoID int, now time.Time) (time.Duration, error) {
+	var seconds float64
+	err := r.db.QueryRow(ctx, `
+        SELECT COALESCE(sum(EXTRACT(EPOCH FROM COALESCE(stopped_at, $3) - started_at)), 0)
+        FROM todo_time_entries
+        WHERE user_id=$1 AND todo_id=$2
+    `, userID, todoID, now).Scan(&seconds)
+
+	if err != nil {
+		logger.Error("TodoRepo.TrackedTime error: " + err.Error())
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// ListTimeRows суммирует время пользователя по задачам за период [from, to) вместе с проектом и метками задачи.
+func (r *TodoRepo) ListTimeRows(ctx context.Context, userID int64, from, to, now time.Time) ([]todo.TimeRow, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT t.id, t.title, COALESCE(p.name, ''),
+               COALESCE((SELECT array_agg(tag ORDER BY tag) FROM todo_tags WHERE todo_id = t.id), '{}'),
+               sum(EXTRACT(EPOCH FROM LEAST(COALESCE(e.stopped_at, $4), $3) - GREATEST(e.started_at, $2)))
+        FROM todo_time_entries e
+        JOIN todos t ON t.id = e.todo_id
+        LEFT JOIN todo_projects p ON p.id = t.project_id
+        WHERE e.user_id=$1 AND e.started_at < $3 AND COALESCE(e.stopped_at, $4) > $2
+        GROUP BY t.id, p.name
+    `, userID, from, to, now)
+	if err != nil {
+		logger.Error("TodoRepo.ListTimeRows error: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []todo.TimeRow
+	for rows.Next() {
+		var (
+			row     todo.TimeRow
+			seconds float64
+		)
+		if err := rows.Scan(&row.TodoID, &row.Title, &row.Project, &row.Tags, &seconds); err != nil {
+			return nil, err
+		}
+		row.Duration = time.Duration(seconds * float64(time.Second))
+		list = append(list, row)
+	}
+	return list, rows.Err()
+}
@@ -2,10 +2,13 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"tg_bot_asist/internal/config"
 	"tg_bot_asist/internal/logger"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -35,3 +38,48 @@ func (r *UserRepo) RegisterUser(ctx context.Context, userID, chatID int64, lastM
 
 	return err
 }
+
+// GetTimezone возвращает часовой пояс пользователя или пустую строку, если он не задан.
+func (r *UserRepo) GetTimezone(ctx context.Context, userID int64) (string, error) {
+	var tz *string
+
+	err := r.db.QueryRow(ctx, `SELECT timezone FROM users WHERE user_id=$1`, userID).Scan(&tz)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		logger.Error("UserRepo.GetTimezone error: " + err.Error())
+		return "", err
+	}
+
+	if tz == nil {
+		return "", nil
+	}
+	return *tz, nil
+}
+
+// SetTimezone сохраняет часовой пояс пользователя.
+func (r *UserRepo) SetTimezone(ctx context.Context, userID int64, tz string) error {
+	_, err := r.db.Exec(ctx, `UPDATE users SET timezone=$2 WHERE user_id=$1`, userID, tz)
+
+	if err != nil {
+		logger.Error("UserRepo.SetTimezone error: " + err.Error())
+	}
+
+	return err
+}
+
+// Location возвращает часовой пояс пользователя, а при его отсутствии — часовой пояс по умолчанию.
+func (r *UserRepo) Location(ctx context.Context, userID int64) *time.Location {
+	tz, err := r.GetTimezone(ctx, userID)
+	if err != nil || tz == "" {
+		return config.DefaultLocation()
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		logger.Warn("UserRepo.Location: invalid timezone " + tz)
+		return config.DefaultLocation()
+	}
+	return loc
+}
@@ -0,0 +1,204 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Ошибки канбан-доски.
+var (
+	ErrInvalidStatus  = errors.New("неизвестный статус задачи")
+	ErrInvalidColumns = errors.New("некорректные колонки доски")
+)
+
+// MaxColumnName — наибольшая длина названия колонки доски в символах.
+const MaxColumnName = 32
+
+// BoardDoneDays — сколько дней выполненные задачи остаются на доске.
+const BoardDoneDays = 14
+
+// ValidStatus сообщает, является ли строка известным статусом задачи.
+func ValidStatus(s string) bool {
+	switch s {
+	case StatusPending, StatusInProgress, StatusCompleted:
+		return true
+	}
+	return false
+}
+
+// BoardColumn — колонка канбан-доски; показывает задачи одного статуса.
+type BoardColumn struct {
+	Status   string `json:"status"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+// DefaultColumns — колонки доски, пока пользователь не настроил свои.
+func DefaultColumns() []BoardColumn {
+	return []BoardColumn{
+		{Status: StatusPending, Name: "К выполнению", Position: 0},
+		{Status: StatusInProgress, Name: "В работе", Position: 1},
+		{Status: StatusCompleted, Name: "Готово", Position: 2},
+	}
+}
+
+// NormalizeColumns проверяет колонки доски: от одной до трёх, статусы известные и не повторяются,
+// названия непустые и не длиннее MaxColumnName. Позиции проставляются по порядку колонок.
+func NormalizeColumns(cols []BoardColumn) ([]BoardColumn, error) {
+	if len(cols) == 0 || len(cols) > 3 {
+		return nil, ErrInvalidColumns
+	}
+	seen := map[string]bool{}
+	out := make([]BoardColumn, 0, len(cols))
+	for i, c := range cols {
+		c.Name = strings.TrimSpace(c.Name)
+		if !ValidStatus(c.Status) || seen[c.Status] || c.Name == "" || len([]rune(c.Name)) > MaxColumnName {
+			return nil, ErrInvalidColumns
+		}
+		seen[c.Status] = true
+		c.Position = i
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// Lane — колонка доски с её задачами в порядке position.
+type Lane struct {
+	BoardColumn
+	Todos []Item `json:"todos"`
+}
+
+// NewBoard раскладывает задачи по колонкам; задачи статусов без колонки на доску не попадают.
+func NewBoard(cols []BoardColumn, items []Item) []Lane {
+	lanes := make([]Lane, len(cols))
+	index := map[string]int{}
+	for i, c := range cols {
+		lanes[i] = Lane{BoardColumn: c, Todos: []Item{}}
+		index[c.Status] = i
+	}
+	for _, it := range items {
+		if i, ok := index[it.Status]; ok {
+			lanes[i].Todos = append(lanes[i].Todos, it)
+		}
+	}
+	return lanes
+}
+
+// BoardMove — перемещение задачи на доске: в колонку статуса Status на место Position (с нуля)
+// среди задач колонки. ProjectID ограничивает колонку задачами проекта (0 — все проекты), как на доске.
+type BoardMove struct {
+	TodoID    int
+	Status    string
+	Position  int
+	ProjectID int
+}
+
+// BoardRepository хранит колонки доски и порядок задач.
+type BoardRepository interface {
+	// ListColumns возвращает колонки пользователя по позиции; пусто — колонки не настроены.
+	ListColumns(ctx context.Context, userID int64) ([]BoardColumn, error)
+	// SaveColumns заменяет колонки пользователя.
+	SaveColumns(ctx context.Context, userID int64, cols []BoardColumn) error
+	// ListBoard возвращает доступные пользователю задачи верхнего уровня для доски (проект projectID или все, кроме архивных)
+	// в порядке position; выполненные — только завершённые после doneSince.
+	ListBoard(ctx context.Context, userID int64, projectID int, doneSince time.Time) ([]Item, error)
+	// MoveOnBoard в одной транзакции меняет статус задачи и ставит её на место m.Position в колонке,
+	// перенумеровывая видимые на доске задачи колонки (выполненные — завершённые не раньше doneSince).
+	// Время завершения проставляется (now) или сбрасывается по новому статусу.
+	MoveOnBoard(ctx context.Context, userID int64, m BoardMove, now, doneSince time.Time) error
+}
+
+// Columns возвращает колонки доски пользователя или колонки по умолчанию.
+func (s *Service) Columns(ctx context.Context, userID int64) ([]BoardColumn, error) {
+	cols, err := s.repo.ListColumns(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return DefaultColumns(), nil
+	}
+	return cols, nil
+}
+
+// SetColumns сохраняет колонки доски пользователя.
+func (s *Service) SetColumns(ctx context.Context, userID int64, cols []BoardColumn) ([]BoardColumn, error) {
+	cols, err := NormalizeColumns(cols)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveColumns(ctx, userID, cols); err != nil {
+		return nil, err
+	}
+	return cols, nil
+}
+
+// Board возвращает канбан-доску: колонки пользователя с задачами верхнего уровня проекта projectID (0 — все проекты).
+func (s *Service) Board(ctx context.Context, userID int64, projectID int) ([]Lane, error) {
+	if projectID != 0 {
+		if _, err := s.repo.GetProject(ctx, userID, projectID); err != nil {
+			return nil, err
+		}
+	}
+	cols, err := s.Columns(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.ListBoard(ctx, userID, projectID, time.Now().AddDate(0, 0, -BoardDoneDays))
+	if err != nil {
+		return nil, err
+	}
+	return NewBoard(cols, items), nil
+}
+
+// MoveOnBoard перемещает задачу на доске и возвращает её после изменения.
+// Перенос в выполненные работает как выполнение задачи: создаётся следующее вхождение повторяющейся задачи,
+// останавливается таймер, проверяется автовыполнение родителя. Подзадачи на доске не показываются и не перемещаются.
+func (s *Service) MoveOnBoard(ctx context.Context, userID int64, m BoardMove) (*Item, error) {
+	if !ValidStatus(m.Status) {
+		return nil, ErrInvalidStatus
+	}
+	item, err := s.getForEdit(ctx, userID, m.TodoID)
+	if err != nil {
+		return nil, err
+	}
+	if item.ParentID != nil {
+		return nil, ErrInvalidParent
+	}
+	if m.Position < 0 {
+		m.Position = 0
+	}
+
+	now := time.Now()
+	// позиция считается среди задач, которые видны в колонке, как в Board
+	if err := s.repo.MoveOnBoard(ctx, userID, m, now, now.AddDate(0, 0, -BoardDoneDays)); err != nil {
+		return nil, err
+	}
+
+	// выполнение и возврат в работу дополнительно сообщаются участникам проекта сообщением бота
+	statusEvent := ""
+	if m.Status != item.Status {
+		switch {
+		case m.Status == StatusCompleted:
+			item.Status = StatusCompleted
+			item.CompletedAt = &now
+			if _, err := s.afterComplete(ctx, userID, item, now); err != nil {
+				return nil, err
+			}
+			statusEvent = EventTodoCompleted
+		case item.Status == StatusCompleted:
+			statusEvent = EventTodoReopened
+		}
+	}
+
+	moved, err := s.Get(ctx, userID, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.shareChange(ctx, userID, EventTodoMoved, moved)
+	if statusEvent != "" {
+		s.shareChange(ctx, userID, statusEvent, moved)
+	}
+	return moved, nil
+}
@@ -0,0 +1,58 @@
+package todo
+
+import "testing"
+
+func TestNormalizeColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		cols    []BoardColumn
+		wantErr bool
+	}{
+		{name: "по умолчанию", cols: DefaultColumns()},
+		{name: "две колонки", cols: []BoardColumn{{Status: StatusPending, Name: " Сделать "}, {Status: StatusCompleted, Name: "Готово"}}},
+		{name: "пусто", cols: nil, wantErr: true},
+		{name: "повтор статуса", cols: []BoardColumn{{Status: StatusPending, Name: "A"}, {Status: StatusPending, Name: "B"}}, wantErr: true},
+		{name: "неизвестный статус", cols: []BoardColumn{{Status: "blocked", Name: "A"}}, wantErr: true},
+		{name: "без названия", cols: []BoardColumn{{Status: StatusPending, Name: "  "}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeColumns(tt.cols)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeColumns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for i, c := range got {
+				if c.Position != i {
+					t.Errorf("column %d position = %d", i, c.Position)
+				}
+			}
+		})
+	}
+
+	got, _ := NormalizeColumns([]BoardColumn{{Status: StatusPending, Name: " Сделать "}})
+	if got[0].Name != "Сделать" {
+		t.Errorf("name not trimmed: %q", got[0].Name)
+	}
+}
+
+func TestNewBoard(t *testing.T) {
+	cols := []BoardColumn{{Status: StatusInProgress, Name: "В работе"}, {Status: StatusPending, Name: "Сделать"}}
+	items := []Item{
+		{ID: 1, Status: StatusPending},
+		{ID: 2, Status: StatusInProgress},
+		{ID: 3, Status: StatusCompleted},
+		{ID: 4, Status: StatusPending},
+	}
+
+	lanes := NewBoard(cols, items)
+
+	if len(lanes) != 2 || lanes[0].Status != StatusInProgress {
+		t.Fatalf("lanes = %+v", lanes)
+	}
+	if len(lanes[0].Todos) != 1 || lanes[0].Todos[0].ID != 2 {
+		t.Errorf("in progress lane = %+v", lanes[0].Todos)
+	}
+	if len(lanes[1].Todos) != 2 || lanes[1].Todos[0].ID != 1 || lanes[1].Todos[1].ID != 4 {
+		t.Errorf("pending lane = %+v, want order 1, 4", lanes[1].Todos)
+	}
+}
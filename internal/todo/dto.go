@@ -1,13 +1,80 @@
 package todo
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Статусы задачи.
+const (
+	StatusPending    = "pending"
+	StatusInProgress = "in_progress" // задача в работе (колонка канбан-доски)
+	StatusCompleted  = "completed"
+)
+
+// ErrNotFound возвращается, если задача не найдена или принадлежит другому пользователю.
+var ErrNotFound = errors.New("задача не найдена")
+
+// ErrInvalidPriority возвращается при попытке задать неизвестный приоритет.
+var ErrInvalidPriority = errors.New("неизвестный приоритет")
+
+// ErrInvalidRecurrence возвращается для некорректного правила повторения.
+var ErrInvalidRecurrence = errors.New("некорректное правило повторения")
+
+// ErrInvalidParent возвращается, если родительская задача не найдена или сама является подзадачей.
+var ErrInvalidParent = errors.New("некорректная родительская задача")
+
+// ErrInvalidPolicy возвращается для некорректной политики напоминаний.
+var ErrInvalidPolicy = errors.New("некорректные настройки напоминаний")
+
+// ErrInvalidSortOrder возвращается для неизвестного порядка сортировки задач.
+var ErrInvalidSortOrder = errors.New("неизвестный порядок сортировки")
+
+// ErrChecklistItemNotFound возвращается, если пункт чек-листа не найден или принадлежит другому пользователю.
+var ErrChecklistItemNotFound = errors.New("пункт чек-листа не найден")
 
 type Item struct {
-	ID          int
-	UserID      int64
-	Title       string
-	Description string
-	DueDate     *time.Time
-	Status      string
-	CreatedAt   time.Time
+	ID           int             `json:"id"`
+	UserID       int64           `json:"user_id"`
+	Title        string          `json:"title"`
+	Description  string          `json:"description"`
+	DueDate      *time.Time      `json:"due_date"`
+	Status       string          `json:"status"`
+	CreatedAt    time.Time       `json:"created_at"`
+	CompletedAt  *time.Time      `json:"completed_at"`
+	Priority     Priority        `json:"priority"`
+	Tags         []string        `json:"tags"`
+	RRule        string          `json:"rrule,omitempty"` // правило повторения (RFC 5545), пусто — без повтора
+	ParentID     *int            `json:"parent_id,omitempty"`
+	ProjectID    int             `json:"project_id"`
+	AutoComplete bool            `json:"auto_complete"` // выполнить задачу, когда выполнены все пункты чек-листа и подзадачи
+	Progress     Progress        `json:"progress"`
+	Checklist    []ChecklistItem `json:"checklist,omitempty"` // заполняется только в Service.Get
+	Position     int             `json:"position"`            // порядок в колонке канбан-доски
+}
+
+// Progress — выполнено пунктов чек-листа и подзадач из общего числа.
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// String возвращает прогресс в виде "3/5".
+func (p Progress) String() string {
+	return fmt.Sprintf("%d/%d", p.Done, p.Total)
+}
+
+// Complete сообщает, что все пункты и подзадачи выполнены.
+func (p Progress) Complete() bool {
+	return p.Total > 0 && p.Done == p.Total
+}
+
+// ChecklistItem — пункт чек-листа задачи.
+type ChecklistItem struct {
+	ID       int    `json:"id"`
+	TodoID   int    `json:"todo_id"`
+	Title    string `json:"title"`
+	Done     bool   `json:"done"`
+	Position int    `json:"position"`
 }
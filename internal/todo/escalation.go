@@ -0,0 +1,87 @@
+package todo
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// EscalationPolicy — как настойчиво напоминать о просроченной задаче.
+// После наступления срока напоминания повторяются с растущими интервалами:
+// FirstDelay, FirstDelay*Factor, FirstDelay*Factor², ... (но не реже MaxInterval),
+// пока пользователь не отметит напоминание («Принято»), не выполнит или не отложит задачу.
+type EscalationPolicy struct {
+	UserID      int64
+	Enabled     bool
+	FirstDelay  time.Duration
+	Factor      float64
+	MaxInterval time.Duration
+	MaxNudges   int // 0 — без повторных напоминаний
+	// MorningMinute — «утро» для кнопки «Завтра утром», в минутах от полуночи по времени пользователя.
+	MorningMinute int
+}
+
+// DefaultEscalationPolicy — политика для пользователя без сохранённых настроек.
+func DefaultEscalationPolicy(userID int64) EscalationPolicy {
+	return EscalationPolicy{
+		UserID:        userID,
+		Enabled:       true,
+		FirstDelay:    30 * time.Minute,
+		Factor:        2,
+		MaxInterval:   24 * time.Hour,
+		MaxNudges:     5,
+		MorningMinute: 9 * 60,
+	}
+}
+
+// Validate проверяет значения политики.
+func (p EscalationPolicy) Validate() error {
+	switch {
+	case p.FirstDelay < time.Minute:
+		return fmt.Errorf("%w: первый интервал не меньше минуты", ErrInvalidPolicy)
+	case p.Factor < 1 || p.Factor > 10:
+		return fmt.Errorf("%w: множитель от 1 до 10", ErrInvalidPolicy)
+	case p.MaxInterval < p.FirstDelay:
+		return fmt.Errorf("%w: максимальный интервал меньше первого", ErrInvalidPolicy)
+	case p.MaxNudges < 0 || p.MaxNudges > 50:
+		return fmt.Errorf("%w: число напоминаний от 0 до 50", ErrInvalidPolicy)
+	case p.MorningMinute < 0 || p.MorningMinute >= 24*60:
+		return fmt.Errorf("%w: время утра вне диапазона", ErrInvalidPolicy)
+	}
+	return nil
+}
+
+// Interval возвращает интервал перед напоминанием с номером n (с единицы).
+func (p EscalationPolicy) Interval(n int) time.Duration {
+	d := float64(p.FirstDelay) * math.Pow(p.Factor, float64(n-1))
+	if d > float64(p.MaxInterval) {
+		return p.MaxInterval
+	}
+	return time.Duration(d)
+}
+
+// NextNudge возвращает время следующего напоминания о задаче со сроком due,
+// если уже отправлено nudges напоминаний (последнее — в last). false — напоминаний больше не будет.
+func (p EscalationPolicy) NextNudge(due time.Time, nudges int, last *time.Time) (time.Time, bool) {
+	if !p.Enabled || nudges >= p.MaxNudges {
+		return time.Time{}, false
+	}
+	from := due
+	if last != nil && last.After(due) {
+		from = *last
+	}
+	return from.Add(p.Interval(nudges + 1)), true
+}
+
+// TomorrowMorning возвращает «завтра утром» относительно now в часовом поясе loc.
+func (p EscalationPolicy) TomorrowMorning(now time.Time, loc *time.Location) time.Time {
+	now = now.In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day()+1, p.MorningMinute/60, p.MorningMinute%60, 0, 0, loc)
+}
+
+// Overdue — просроченная задача с состоянием повторных напоминаний.
+type Overdue struct {
+	Item
+	Nudges      int
+	LastNudgeAt *time.Time
+}
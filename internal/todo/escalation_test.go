@@ -0,0 +1,57 @@
+package todo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEscalationNextNudge(t *testing.T) {
+	due := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := due.Add(d)
+		return &t
+	}
+	p := EscalationPolicy{
+		Enabled:     true,
+		FirstDelay:  30 * time.Minute,
+		Factor:      2,
+		MaxInterval: 90 * time.Minute,
+		MaxNudges:   4,
+	}
+
+	tests := []struct {
+		name   string
+		policy EscalationPolicy
+		nudges int
+		last   *time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{name: "первое — через FirstDelay после срока", policy: p, want: due.Add(30 * time.Minute), wantOK: true},
+		{name: "второе — через удвоенный интервал", policy: p, nudges: 1, last: at(30 * time.Minute), want: due.Add(90 * time.Minute), wantOK: true},
+		{name: "интервал ограничен MaxInterval", policy: p, nudges: 2, last: at(90 * time.Minute), want: due.Add(180 * time.Minute), wantOK: true},
+		{name: "после MaxNudges напоминаний больше нет", policy: p, nudges: 4, last: at(5 * time.Hour)},
+		{name: "выключенная политика", policy: EscalationPolicy{FirstDelay: time.Minute, MaxNudges: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.policy.NextNudge(due, tt.nudges, tt.last)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("NextNudge() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEscalationTomorrowMorning(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	p := EscalationPolicy{MorningMinute: 9*60 + 30}
+
+	// 23:30 UTC 16.10 — это уже 02:30 17.10 по Москве, «завтра» — 18.10
+	got := p.TomorrowMorning(time.Date(2026, time.October, 16, 23, 30, 0, 0, time.UTC), msk)
+	want := time.Date(2026, time.October, 18, 9, 30, 0, 0, msk)
+	if !got.Equal(want) {
+		t.Errorf("TomorrowMorning() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,154 @@
+package todo
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Priority — уровень приоритета задачи (соответствует todo_priorities.level).
+type Priority int
+
+const (
+	PriorityLow    Priority = 1
+	PriorityNormal Priority = 2
+	PriorityHigh   Priority = 3
+	PriorityUrgent Priority = 4
+)
+
+// priorityNames — допустимые названия приоритетов (англ. коды, русские названия, цифры).
+var priorityNames = map[string]Priority{
+	"low": PriorityLow, "низкий": PriorityLow, "1": PriorityLow,
+	"normal": PriorityNormal, "обычный": PriorityNormal, "2": PriorityNormal,
+	"high": PriorityHigh, "высокий": PriorityHigh, "3": PriorityHigh,
+	"urgent": PriorityUrgent, "срочный": PriorityUrgent, "срочно": PriorityUrgent, "4": PriorityUrgent,
+}
+
+// ParsePriority разбирает название или номер приоритета.
+func ParsePriority(s string) (Priority, bool) {
+	p, ok := priorityNames[strings.ToLower(strings.TrimSpace(s))]
+	return p, ok
+}
+
+// Valid проверяет, что приоритет входит в допустимый диапазон.
+func (p Priority) Valid() bool {
+	return p >= PriorityLow && p <= PriorityUrgent
+}
+
+// String возвращает код приоритета.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityUrgent:
+		return "urgent"
+	}
+	return strconv.Itoa(int(p))
+}
+
+// NormalizeTag приводит метку к каноническому виду: без '#', в нижнем регистре,
+// только буквы, цифры, '_' и '-'. Возвращает "" для некорректной метки.
+func NormalizeTag(tag string) string {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return ""
+		}
+	}
+	return tag
+}
+
+// NormalizeTags нормализует метки, отбрасывает пустые и дубликаты, сортирует.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = NormalizeTag(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// View — представление списка задач по сроку.
+type View string
+
+const (
+	ViewAll     View = ""
+	ViewOverdue View = "overdue" // просроченные
+	ViewToday   View = "today"   // со сроком сегодня
+	ViewWeek    View = "week"    // со сроком до конца текущей недели
+)
+
+// ParseView разбирает название представления (англ. или рус.).
+func ParseView(s string) (View, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "all", "все":
+		return ViewAll, true
+	case "overdue", "просрочено", "просроченные":
+		return ViewOverdue, true
+	case "today", "сегодня":
+		return ViewToday, true
+	case "week", "неделя":
+		return ViewWeek, true
+	}
+	return ViewAll, false
+}
+
+// ListFilter — фильтр списка задач. Пустые поля не ограничивают выборку.
+type ListFilter struct {
+	Tag       string
+	Priority  Priority
+	View      View
+	ProjectID int
+}
+
+// ListQuery — условия выборки задач для Repository.ListFiltered.
+type ListQuery struct {
+	Tag         string
+	Priority    Priority
+	PendingOnly bool
+	DueFrom     *time.Time // срок не раньше (включительно)
+	DueBefore   *time.Time // срок раньше (не включительно)
+	ProjectID   int        // 0 — все проекты, кроме архивных
+	Order       SortOrder  // пусто — сначала задачи со сроком, затем по приоритету
+}
+
+// query переводит фильтр в условия выборки. Границы «сегодня» и «неделя»
+// считаются в часовом поясе пользователя.
+func (f ListFilter) query(now time.Time, loc *time.Location) ListQuery {
+	q := ListQuery{Tag: NormalizeTag(f.Tag), Priority: f.Priority, ProjectID: f.ProjectID}
+
+	now = now.In(loc)
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	switch f.View {
+	case ViewOverdue:
+		q.PendingOnly = true
+		q.DueBefore = &now
+	case ViewToday:
+		end := startOfDay.AddDate(0, 0, 1)
+		q.PendingOnly = true
+		q.DueFrom, q.DueBefore = &startOfDay, &end
+	case ViewWeek:
+		// неделя заканчивается в понедельник 00:00
+		daysLeft := (8 - int(now.Weekday())) % 7
+		if daysLeft == 0 {
+			daysLeft = 7
+		}
+		end := startOfDay.AddDate(0, 0, daysLeft)
+		q.PendingOnly = true
+		q.DueFrom, q.DueBefore = &startOfDay, &end
+	}
+	return q
+}
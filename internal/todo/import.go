@@ -0,0 +1,167 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/dateparse"
+)
+
+// MaxImportItems — наибольшее число задач (вместе с подзадачами) в одном импорте.
+const MaxImportItems = 100
+
+// ErrImportEmpty возвращается, если в тексте импорта не найдено ни одной задачи.
+var ErrImportEmpty = errors.New("в тексте нет задач")
+
+// ErrImportTooLarge возвращается, если в тексте импорта больше MaxImportItems задач.
+var ErrImportTooLarge = errors.New("слишком много задач для импорта")
+
+// ImportItem — задача, разобранная из текста импорта.
+type ImportItem struct {
+	Title    string       `json:"title"`
+	DueDate  *time.Time   `json:"due_date,omitempty"`
+	Done     bool         `json:"done"`
+	Children []ImportItem `json:"children,omitempty"`
+}
+
+// CountImport возвращает число задач импорта вместе с подзадачами.
+func CountImport(items []ImportItem) int {
+	n := len(items)
+	for _, it := range items {
+		n += len(it.Children)
+	}
+	return n
+}
+
+// ParseImport разбирает многострочный текст или чек-лист Markdown в список задач.
+// Каждая непустая строка — задача; маркеры списка ("- ", "* ", "+ ", "• ", "1. ", "1) ")
+// и флажки чек-листа ("[ ]", "[x]") убираются, отмеченный флажок означает выполненную задачу.
+// Строка с отступом больше, чем у строк верхнего уровня, становится подзадачей ближайшей задачи выше;
+// более глубокие уровни тоже становятся её подзадачами, так как подзадачи бывают только одного уровня.
+// Срок задачи ищется в тексте строки относительно now. Заголовки Markdown ("# ...") пропускаются.
+func ParseImport(text string, now time.Time) []ImportItem {
+	var (
+		items []ImportItem
+		base  = -1 // отступ строк верхнего уровня
+	)
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		indent := importIndent(line)
+		body := strings.TrimSpace(line)
+		if body == "" || strings.HasPrefix(body, "# ") || strings.HasPrefix(body, "## ") || strings.HasPrefix(body, "### ") {
+			continue
+		}
+
+		title, done := stripImportMarker(body)
+		if title == "" {
+			continue
+		}
+		it := ImportItem{Title: title, Done: done}
+		if res, ok := dateparse.Parse(title, now); ok && res.Rest != "" {
+			it.Title = res.Rest
+			it.DueDate = &res.Time
+		}
+
+		if base < 0 || indent <= base || len(items) == 0 {
+			if base < 0 || indent < base {
+				base = indent
+			}
+			items = append(items, it)
+			continue
+		}
+		parent := &items[len(items)-1]
+		parent.Children = append(parent.Children, it)
+	}
+	return items
+}
+
+// importIndent возвращает ширину отступа строки; табуляция считается за четыре пробела.
+func importIndent(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// stripImportMarker убирает маркер списка и флажок чек-листа; второе значение — флажок отмечен.
+func stripImportMarker(s string) (string, bool) {
+	for _, m := range []string{"- ", "* ", "+ ", "• "} {
+		if strings.HasPrefix(s, m) {
+			s = strings.TrimSpace(s[len(m):])
+			break
+		}
+	}
+	if i := strings.IndexAny(s, ".)"); i > 0 && i <= 3 && len(s) > i+1 && s[i+1] == ' ' && isDigits(s[:i]) {
+		s = strings.TrimSpace(s[i+1:])
+	}
+
+	done := false
+	switch {
+	case strings.HasPrefix(s, "[ ]"):
+		s = s[3:]
+	case strings.HasPrefix(s, "[x]"), strings.HasPrefix(s, "[X]"), strings.HasPrefix(s, "[х]"), strings.HasPrefix(s, "[Х]"):
+		s = s[strings.Index(s, "]")+1:]
+		done = true
+	}
+	return strings.TrimSpace(s), done
+}
+
+// isDigits сообщает, состоит ли строка только из цифр.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Import создаёт задачи из разобранного текста в проекте projectID (0 — Inbox).
+// Подзадачи создаются после родительской задачи, отмеченные задачи сразу выполняются.
+// При ошибке возвращается число уже созданных задач: импорт не откатывается.
+func (s *Service) Import(ctx context.Context, userID int64, projectID int, items []ImportItem) (int, error) {
+	if len(items) == 0 {
+		return 0, ErrImportEmpty
+	}
+	if CountImport(items) > MaxImportItems {
+		return 0, ErrImportTooLarge
+	}
+
+	created := 0
+	for _, it := range items {
+		parent := &Item{UserID: userID, Title: it.Title, DueDate: it.DueDate, ProjectID: projectID}
+		if err := s.Create(ctx, parent); err != nil {
+			return created, err
+		}
+		created++
+
+		for _, ch := range it.Children {
+			sub := &Item{UserID: userID, Title: ch.Title, DueDate: ch.DueDate, ParentID: &parent.ID}
+			if err := s.Create(ctx, sub); err != nil {
+				return created, err
+			}
+			created++
+			if ch.Done {
+				if _, err := s.Complete(ctx, userID, sub.ID); err != nil {
+					return created, err
+				}
+			}
+		}
+
+		if it.Done {
+			if _, err := s.Complete(ctx, userID, parent.ID); err != nil {
+				return created, err
+			}
+		}
+	}
+	return created, nil
+}
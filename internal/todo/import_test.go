@@ -0,0 +1,86 @@
+package todo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseImport(t *testing.T) {
+	now := time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)
+
+	text := "# Переезд\n" +
+		"- [ ] Собрать вещи\n" +
+		"  - [x] Купить коробки\n" +
+		"  - [ ] Упаковать книги\n" +
+		"\n" +
+		"* Заказать грузовик завтра в 10:00\n" +
+		"1. Позвонить хозяину\n" +
+		"\t2) Передать ключи\n" +
+		"- [X] Оплатить интернет\n"
+
+	got := ParseImport(text, now)
+
+	want := []struct {
+		title    string
+		done     bool
+		hasDue   bool
+		children []string
+	}{
+		{title: "Собрать вещи", children: []string{"Купить коробки", "Упаковать книги"}},
+		{title: "Заказать грузовик", hasDue: true},
+		{title: "Позвонить хозяину", children: []string{"Передать ключи"}},
+		{title: "Оплатить интернет", done: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseImport() returned %d items, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		it := got[i]
+		if it.Title != w.title || it.Done != w.done || (it.DueDate != nil) != w.hasDue {
+			t.Errorf("item %d = %q done=%v due=%v; want %q done=%v due=%v", i, it.Title, it.Done, it.DueDate, w.title, w.done, w.hasDue)
+		}
+		if len(it.Children) != len(w.children) {
+			t.Errorf("item %d has %d children, want %d", i, len(it.Children), len(w.children))
+			continue
+		}
+		for j, title := range w.children {
+			if it.Children[j].Title != title {
+				t.Errorf("item %d child %d = %q, want %q", i, j, it.Children[j].Title, title)
+			}
+		}
+	}
+	if !got[0].Children[0].Done {
+		t.Errorf("checked subtask not marked done")
+	}
+	if due := got[1].DueDate; due != nil && (due.Day() != 18 || due.Hour() != 10) {
+		t.Errorf("due = %v, want 18.10 10:00", due)
+	}
+	if n := CountImport(got); n != 7 {
+		t.Errorf("CountImport() = %d, want 7", n)
+	}
+}
+
+func TestStripImportMarker(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		done bool
+	}{
+		{in: "Купить молоко", want: "Купить молоко"},
+		{in: "- Купить молоко", want: "Купить молоко"},
+		{in: "• Купить молоко", want: "Купить молоко"},
+		{in: "12. Купить молоко", want: "Купить молоко"},
+		{in: "- [ ] Купить молоко", want: "Купить молоко"},
+		{in: "- [х] Купить молоко", want: "Купить молоко", done: true},
+		{in: "3.5 кг яблок", want: "3.5 кг яблок"},
+		{in: "- [ ]", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, done := stripImportMarker(tt.in)
+			if got != tt.want || done != tt.done {
+				t.Errorf("stripImportMarker(%q) = %q, %v; want %q, %v", tt.in, got, done, tt.want, tt.done)
+			}
+		})
+	}
+}
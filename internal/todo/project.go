@@ -0,0 +1,254 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// InboxName — имя проекта по умолчанию, в который попадают задачи без проекта.
+const InboxName = "Inbox"
+
+// Ошибки проектов.
+var (
+	ErrProjectNotFound = errors.New("проект не найден")
+	ErrProjectExists   = errors.New("проект с таким именем уже есть")
+	ErrProjectName     = errors.New("некорректное имя проекта")
+	// ErrInboxProject возвращается при попытке удалить, архивировать или переименовать Inbox.
+	ErrInboxProject = errors.New("проект Inbox нельзя изменить")
+)
+
+// SortOrder — порядок задач внутри проекта.
+type SortOrder string
+
+const (
+	SortCreated  SortOrder = "created"  // сначала новые
+	SortDue      SortOrder = "due"      // по сроку, без срока — в конце
+	SortPriority SortOrder = "priority" // по убыванию приоритета
+	SortTitle    SortOrder = "title"    // по алфавиту
+)
+
+// ParseSortOrder разбирает порядок сортировки; пустая строка — SortCreated.
+func ParseSortOrder(s string) (SortOrder, bool) {
+	switch o := SortOrder(strings.ToLower(strings.TrimSpace(s))); o {
+	case "":
+		return SortCreated, true
+	case SortCreated, SortDue, SortPriority, SortTitle:
+		return o, true
+	}
+	return "", false
+}
+
+// Project — именованный список задач пользователя.
+type Project struct {
+	ID        int       `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Name      string    `json:"name"`
+	Position  int       `json:"position"` // порядок проекта в списке проектов
+	SortOrder SortOrder `json:"sort_order"`
+	Archived  bool      `json:"archived"`
+	Inbox     bool      `json:"inbox"`
+	Open      int       `json:"open"`    // число незавершённых задач
+	Role      Role      `json:"role"`    // права запросившего пользователя
+	Members   int       `json:"members"` // число участников, кроме владельца
+}
+
+// Shared сообщает, что у проекта есть участники или он принадлежит другому пользователю.
+func (p *Project) Shared() bool {
+	return p.Members > 0 || p.Role != RoleOwner
+}
+
+// ProjectUpdate — изменяемые поля проекта; nil-поля не меняются.
+type ProjectUpdate struct {
+	Name      *string    `json:"name,omitempty"`
+	Position  *int       `json:"position,omitempty"`
+	SortOrder *SortOrder `json:"sort_order,omitempty"`
+	Archived  *bool      `json:"archived,omitempty"`
+}
+
+// ProjectRepository хранит проекты задач.
+type ProjectRepository interface {
+	// ListProjects возвращает проекты пользователя и общие проекты, в которых он участвует, по position;
+	// архивные — только при includeArchived.
+	ListProjects(ctx context.Context, userID int64, includeArchived bool) ([]Project, error)
+	// GetProject возвращает проект, доступный пользователю, с его ролью, или ErrProjectNotFound.
+	GetProject(ctx context.Context, userID int64, id int) (*Project, error)
+	// EnsureInbox возвращает проект Inbox пользователя, создавая его при необходимости.
+	EnsureInbox(ctx context.Context, userID int64) (*Project, error)
+	// CreateProject сохраняет проект в конец списка и заполняет его ID и Position (ErrProjectExists при совпадении имени).
+	CreateProject(ctx context.Context, p *Project) error
+	// UpdateProject сохраняет имя, позицию, порядок сортировки и признак архива.
+	UpdateProject(ctx context.Context, p *Project) error
+	// DeleteProject переносит все задачи проекта в Inbox владельца и удаляет проект.
+	DeleteProject(ctx context.Context, userID int64, id int) error
+	// MoveTodo переносит задачу (вместе с подзадачами) в проект.
+	// Если у автора задачи нет доступа к проекту, задача переходит владельцу проекта.
+	MoveTodo(ctx context.Context, todoID, projectID int) error
+}
+
+// Projects возвращает проекты пользователя и общие проекты; Inbox создаётся при первом обращении.
+func (s *Service) Projects(ctx context.Context, userID int64, includeArchived bool) ([]Project, error) {
+	if _, err := s.repo.EnsureInbox(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListProjects(ctx, userID, includeArchived)
+}
+
+// Project возвращает проект пользователя.
+func (s *Service) Project(ctx context.Context, userID int64, id int) (*Project, error) {
+	return s.repo.GetProject(ctx, userID, id)
+}
+
+// CreateProject создаёт проект с порядком задач по умолчанию.
+func (s *Service) CreateProject(ctx context.Context, userID int64, name string) (*Project, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > 64 {
+		return nil, ErrProjectName
+	}
+	if strings.EqualFold(name, InboxName) {
+		return nil, ErrProjectExists
+	}
+
+	p := &Project{UserID: userID, Name: name, SortOrder: SortCreated}
+	if err := s.repo.CreateProject(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UpdateProject изменяет проект. Inbox можно только переупорядочить и сменить в нём сортировку.
+// Изменять проект может только владелец.
+func (s *Service) UpdateProject(ctx context.Context, userID int64, id int, u ProjectUpdate) (*Project, error) {
+	p, err := s.repo.GetProject(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Role != RoleOwner {
+		return nil, ErrForbidden
+	}
+
+	if u.Name != nil {
+		name := strings.TrimSpace(*u.Name)
+		if p.Inbox && name != p.Name {
+			return nil, ErrInboxProject
+		}
+		if name == "" || len([]rune(name)) > 64 {
+			return nil, ErrProjectName
+		}
+		if !p.Inbox && strings.EqualFold(name, InboxName) {
+			return nil, ErrProjectExists
+		}
+		p.Name = name
+	}
+	if u.Archived != nil {
+		if p.Inbox && *u.Archived {
+			return nil, ErrInboxProject
+		}
+		p.Archived = *u.Archived
+	}
+	if u.SortOrder != nil {
+		order, ok := ParseSortOrder(string(*u.SortOrder))
+		if !ok {
+			return nil, ErrInvalidSortOrder
+		}
+		p.SortOrder = order
+	}
+	if u.Position != nil {
+		p.Position = *u.Position
+	}
+
+	if err := s.repo.UpdateProject(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// DeleteProject удаляет проект; его задачи, в том числе созданные участниками, переходят в Inbox владельца.
+func (s *Service) DeleteProject(ctx context.Context, userID int64, id int) error {
+	p, err := s.repo.GetProject(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if p.Role != RoleOwner {
+		return ErrForbidden
+	}
+	if p.Inbox {
+		return ErrInboxProject
+	}
+
+	// участников нужно узнать до удаления: записи о них удаляются вместе с проектом
+	_, audience, err := s.repo.ProjectAudience(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteProject(ctx, userID, id); err != nil {
+		return err
+	}
+	s.publish(ctx, userID, audience, "project_deleted", map[string]int{"id": id}, "👥 Общий проект «"+p.Name+"» удалён владельцем")
+	return nil
+}
+
+// ListProject возвращает задачи проекта в порядке, заданном в проекте.
+func (s *Service) ListProject(ctx context.Context, userID int64, projectID int) (*Project, []Item, error) {
+	p, err := s.repo.GetProject(ctx, userID, projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := s.repo.ListFiltered(ctx, userID, ListQuery{ProjectID: p.ID, Order: p.SortOrder})
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, items, nil
+}
+
+// MoveToProject переносит задачу верхнего уровня (вместе с подзадачами) в другой проект.
+// Нужны права на изменение и задачи, и проекта назначения.
+func (s *Service) MoveToProject(ctx context.Context, userID int64, todoID, projectID int) (*Item, error) {
+	item, err := s.getForEdit(ctx, userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	if item.ParentID != nil {
+		return nil, ErrInvalidParent
+	}
+	if err := s.checkProjectEdit(ctx, userID, projectID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.MoveTodo(ctx, todoID, projectID); err != nil {
+		return nil, err
+	}
+	// участники старого проекта узнают об уходе задачи, участники нового — о её появлении
+	s.shareChange(ctx, userID, EventTodoDeleted, item)
+
+	moved, err := s.Get(ctx, userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	s.shareChange(ctx, userID, EventTodoAdded, moved)
+	return moved, nil
+}
+
+// checkProjectEdit проверяет, что пользователь может добавлять задачи в проект.
+func (s *Service) checkProjectEdit(ctx context.Context, userID int64, projectID int) error {
+	p, err := s.repo.GetProject(ctx, userID, projectID)
+	if err != nil {
+		return err
+	}
+	if !p.Role.CanEdit() {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// resolveProject проверяет проект новой задачи; без проекта задача попадает в Inbox.
+func (s *Service) resolveProject(ctx context.Context, item *Item) error {
+	if item.ProjectID == 0 {
+		inbox, err := s.repo.EnsureInbox(ctx, item.UserID)
+		if err != nil {
+			return err
+		}
+		item.ProjectID = inbox.ID
+		return nil
+	}
+	return s.checkProjectEdit(ctx, item.UserID, item.ProjectID)
+}
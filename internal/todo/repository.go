@@ -1,9 +1,64 @@
 package todo
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Repository interface {
+	ProjectRepository
+	ShareRepository
+	TimeRepository
+	BoardRepository
+	StatsRepository
+
 	Create(ctx context.Context, t *Item) (int, error)
+	// List и остальные методы чтения возвращают задачи пользователя и задачи общих проектов, в которых он участвует.
 	List(ctx context.Context, userID int64) ([]Item, error)
 	Delete(ctx context.Context, userID int64, id int) error
+	Get(ctx context.Context, userID int64, id int) (*Item, error)
+	// ListFiltered возвращает задачи пользователя, удовлетворяющие условиям q.
+	ListFiltered(ctx context.Context, userID int64, q ListQuery) ([]Item, error)
+	// Update сохраняет заголовок, описание, срок, статус, время завершения, приоритет, метки, правило повторения и флаг автовыполнения задачи.
+	// При изменении срока отметки об отправленных напоминаниях сбрасываются.
+	Update(ctx context.Context, t *Item) error
+	// ListChildren возвращает подзадачи задачи parentID.
+	ListChildren(ctx context.Context, userID int64, parentID int) ([]Item, error)
+
+	// ListChecklist возвращает пункты чек-листа задачи в порядке добавления.
+	ListChecklist(ctx context.Context, todoID int) ([]ChecklistItem, error)
+	// AddChecklistItem добавляет пункт в конец чек-листа задачи пользователя (ErrNotFound, если задачи нет).
+	AddChecklistItem(ctx context.Context, userID int64, todoID int, title string) (*ChecklistItem, error)
+	// ToggleChecklistItem меняет отметку пункта на противоположную (ErrChecklistItemNotFound, если пункта нет).
+	ToggleChecklistItem(ctx context.Context, userID int64, itemID int) (*ChecklistItem, error)
+	// DeleteChecklistItem удаляет пункт и возвращает его (ErrChecklistItemNotFound, если пункта нет).
+	DeleteChecklistItem(ctx context.Context, userID int64, itemID int) (*ChecklistItem, error)
+
+	// Acknowledge прекращает повторные напоминания о просроченной задаче до изменения её срока.
+	Acknowledge(ctx context.Context, userID int64, id int) error
+	// GetEscalationPolicy возвращает политику напоминаний пользователя или DefaultEscalationPolicy.
+	GetEscalationPolicy(ctx context.Context, userID int64) (EscalationPolicy, error)
+	SaveEscalationPolicy(ctx context.Context, p EscalationPolicy) error
+}
+
+// ReminderRepository определяет интерфейс хранилища для планировщика напоминаний.
+type ReminderRepository interface {
+	// ListDueBefore возвращает незавершённые задачи всех пользователей со сроком в [since, until].
+	ListDueBefore(ctx context.Context, since, until time.Time) ([]Item, error)
+	// ClaimReminder отмечает напоминание как отправленное.
+	// Возвращает false, если напоминание с таким упреждением уже было отправлено.
+	ClaimReminder(ctx context.Context, todoID int, lead time.Duration) (bool, error)
+	// ReleaseReminder снимает отметку об отправке (если отправка не удалась).
+	ReleaseReminder(ctx context.Context, todoID int, lead time.Duration) error
+
+	// ListOverdue возвращает просроченные незавершённые задачи, по которым было отправлено напоминание
+	// о наступлении срока, а повторные напоминания не отмечены как принятые.
+	ListOverdue(ctx context.Context, now time.Time) ([]Overdue, error)
+	// GetEscalationPolicy возвращает политику напоминаний пользователя или DefaultEscalationPolicy.
+	GetEscalationPolicy(ctx context.Context, userID int64) (EscalationPolicy, error)
+	// ClaimNudge отмечает отправку повторного напоминания с номером nudges+1.
+	// Возвращает false, если состояние задачи уже изменилось (напоминание отправлено или принято).
+	ClaimNudge(ctx context.Context, todoID, nudges int, at time.Time) (bool, error)
+	// ReleaseNudge возвращает состояние до ClaimNudge (если отправка не удалась).
+	ReleaseNudge(ctx context.Context, todoID, nudges int, last *time.Time) error
 }
@@ -1 +1,232 @@
 package todo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tg_bot_asist/internal/logger"
+)
+
+// Notifier отправляет текстовое уведомление пользователю (реализуется ботом).
+type Notifier interface {
+	Notify(ctx context.Context, userID int64, text string) error
+}
+
+// ReminderNotifier отправляет напоминание о задаче с кнопками «Отложить», «Выполнено» и «Принято» (реализуется ботом).
+type ReminderNotifier interface {
+	NotifyTodo(ctx context.Context, item Item, text string) error
+}
+
+// LocationProvider возвращает часовой пояс пользователя (реализуется storage.UserRepo).
+type LocationProvider interface {
+	Location(ctx context.Context, userID int64) *time.Location
+}
+
+// MaxOverdueReminder — насколько давно может наступить срок, чтобы о нём ещё напомнить.
+// Старые просроченные задачи (например, при первом запуске или после долгого простоя бота)
+// не получают ни напоминания о сроке, ни повторных напоминаний: повторные отправляются
+// только по задачам, о сроке которых планировщик уже напомнил.
+const MaxOverdueReminder = 24 * time.Hour
+
+// ReminderScheduler напоминает о приближающихся и наступивших сроках задач.
+// Для каждой задачи напоминание с конкретным упреждением отправляется ровно один раз:
+// факт отправки фиксируется в хранилище до отправки сообщения.
+// О просроченных задачах планировщик напоминает повторно по политике пользователя (EscalationPolicy).
+type ReminderScheduler struct {
+	repo     ReminderRepository
+	notifier ReminderNotifier
+	users    LocationProvider
+	leads    []time.Duration // по убыванию, последний элемент всегда 0 (срок наступил)
+}
+
+// NewReminderScheduler создаёт планировщик напоминаний.
+// leads — за сколько до срока напоминать (например, 24h и 1h).
+// Напоминание в момент наступления срока добавляется автоматически.
+func NewReminderScheduler(repo ReminderRepository, notifier ReminderNotifier, users LocationProvider, leads []time.Duration) *ReminderScheduler {
+	uniq := map[time.Duration]bool{0: true}
+	for _, l := range leads {
+		if l > 0 {
+			uniq[l] = true
+		}
+	}
+
+	sorted := make([]time.Duration, 0, len(uniq))
+	for l := range uniq {
+		sorted = append(sorted, l)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+
+	return &ReminderScheduler{
+		repo:     repo,
+		notifier: notifier,
+		users:    users,
+		leads:    sorted,
+	}
+}
+
+// RunCheck находит задачи, по которым пора отправить напоминание, и отправляет их.
+// Вызывается каждую минуту из main.go.
+func (s *ReminderScheduler) RunCheck(ctx context.Context) {
+	now := time.Now()
+
+	items, err := s.repo.ListDueBefore(ctx, now.Add(-MaxOverdueReminder), now.Add(s.leads[0]))
+	if err != nil {
+		logger.Error("ReminderScheduler: failed to list due todos: " + err.Error())
+		return
+	}
+
+	sent := 0
+	for _, it := range items {
+		if it.DueDate == nil {
+			continue
+		}
+
+		lead, ok := s.currentLead(it.DueDate.Sub(now))
+		if !ok {
+			continue
+		}
+
+		claimed, err := s.repo.ClaimReminder(ctx, it.ID, lead)
+		if err != nil {
+			logger.Error("ReminderScheduler: failed to claim reminder: " + err.Error())
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.notifier.NotifyTodo(ctx, it, reminderText(it, lead, s.users.Location(ctx, it.UserID))); err != nil {
+			logger.Error(fmt.Sprintf("ReminderScheduler: failed to notify user %d: %s", it.UserID, err.Error()))
+			if err := s.repo.ReleaseReminder(ctx, it.ID, lead); err != nil {
+				logger.Error("ReminderScheduler: failed to release reminder: " + err.Error())
+			}
+			continue
+		}
+
+		sent++
+	}
+
+	if sent > 0 {
+		logger.Info(fmt.Sprintf("ReminderScheduler: sent %d reminders", sent))
+	}
+
+	s.escalate(ctx, now)
+}
+
+// escalate отправляет повторные напоминания о просроченных задачах, время которых наступило.
+func (s *ReminderScheduler) escalate(ctx context.Context, now time.Time) {
+	overdue, err := s.repo.ListOverdue(ctx, now)
+	if err != nil {
+		logger.Error("ReminderScheduler: failed to list overdue todos: " + err.Error())
+		return
+	}
+
+	policies := make(map[int64]EscalationPolicy)
+	sent := 0
+	for _, o := range overdue {
+		p, ok := policies[o.UserID]
+		if !ok {
+			if p, err = s.repo.GetEscalationPolicy(ctx, o.UserID); err != nil {
+				logger.Error("ReminderScheduler: failed to get escalation policy: " + err.Error())
+				continue
+			}
+			policies[o.UserID] = p
+		}
+
+		at, ok := p.NextNudge(*o.DueDate, o.Nudges, o.LastNudgeAt)
+		if !ok || at.After(now) {
+			continue
+		}
+
+		claimed, err := s.repo.ClaimNudge(ctx, o.ID, o.Nudges, now)
+		if err != nil || !claimed {
+			continue
+		}
+
+		text := nudgeText(o, p, now, s.users.Location(ctx, o.UserID))
+		if err := s.notifier.NotifyTodo(ctx, o.Item, text); err != nil {
+			logger.Error(fmt.Sprintf("ReminderScheduler: failed to nudge user %d: %s", o.UserID, err.Error()))
+			if err := s.repo.ReleaseNudge(ctx, o.ID, o.Nudges, o.LastNudgeAt); err != nil {
+				logger.Error("ReminderScheduler: failed to release nudge: " + err.Error())
+			}
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		logger.Info(fmt.Sprintf("ReminderScheduler: sent %d overdue nudges", sent))
+	}
+}
+
+func nudgeText(o Overdue, p EscalationPolicy, now time.Time, loc *time.Location) string {
+	late := now.Sub(*o.DueDate).Truncate(time.Minute)
+	if late >= time.Hour {
+		late = late.Truncate(time.Hour)
+	}
+	return fmt.Sprintf("⚠️ Задача просрочена на %s: %s\nСрок был: %s\nНапоминание %d из %d. Отложите задачу или нажмите «Принято», чтобы больше не напоминать.",
+		FormatLead(late), o.Title, o.DueDate.In(loc).Format("02.01.2006 15:04"), o.Nudges+1, p.MaxNudges)
+}
+
+// currentLead возвращает наименьшее упреждение, в окно которого попадает задача.
+// Если бот был выключен и пропустил более ранние напоминания, отправляется только актуальное.
+func (s *ReminderScheduler) currentLead(left time.Duration) (time.Duration, bool) {
+	for i := len(s.leads) - 1; i >= 0; i-- {
+		if left <= s.leads[i] {
+			return s.leads[i], true
+		}
+	}
+	return 0, false
+}
+
+func reminderText(it Item, lead time.Duration, loc *time.Location) string {
+	due := it.DueDate.In(loc).Format("02.01.2006 15:04")
+	if lead == 0 {
+		return fmt.Sprintf("⏰ Срок задачи наступил: %s\nСрок: %s", it.Title, due)
+	}
+	return fmt.Sprintf("🔔 Напоминание: %s\nСрок через %s (%s)", it.Title, FormatLead(lead), due)
+}
+
+// FormatLead форматирует интервал упреждения для пользователя: "1 д", "2 ч", "15 мин".
+func FormatLead(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%d д", int(d/(24*time.Hour)))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%d ч", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%d мин", int(d/time.Minute))
+	}
+}
+
+// ParseLeads разбирает список интервалов упреждения через запятую, например "1d,1h,15m".
+// Помимо формата time.ParseDuration поддерживаются дни ("1d").
+func ParseLeads(s string) ([]time.Duration, error) {
+	var leads []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if days, ok := strings.CutSuffix(part, "d"); ok {
+			n, err := strconv.Atoi(days)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid reminder lead %q", part)
+			}
+			leads = append(leads, time.Duration(n)*24*time.Hour)
+			continue
+		}
+
+		d, err := time.ParseDuration(part)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid reminder lead %q", part)
+		}
+		leads = append(leads, d)
+	}
+	return leads, nil
+}
@@ -0,0 +1,84 @@
+package todo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLeads(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []time.Duration
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "1d,1h", want: []time.Duration{24 * time.Hour, time.Hour}},
+		{in: " 15m , 2d ,, 1h30m ", want: []time.Duration{15 * time.Minute, 48 * time.Hour, 90 * time.Minute}},
+		{in: "1h,1h", want: []time.Duration{time.Hour, time.Hour}},
+		{in: "0d", wantErr: true},
+		{in: "-1h", wantErr: true},
+		{in: "0m", wantErr: true},
+		{in: "1.5d", wantErr: true},
+		{in: "tomorrow", wantErr: true},
+		{in: "1h,abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLeads(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLeads(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseLeads(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewReminderSchedulerLeads(t *testing.T) {
+	tests := []struct {
+		name  string
+		leads []time.Duration
+		want  []time.Duration
+	}{
+		{name: "только срок", leads: nil, want: []time.Duration{0}},
+		{name: "по убыванию", leads: []time.Duration{time.Hour, 24 * time.Hour, 15 * time.Minute},
+			want: []time.Duration{24 * time.Hour, time.Hour, 15 * time.Minute, 0}},
+		{name: "дубликаты и неположительные", leads: []time.Duration{time.Hour, time.Hour, 0, -time.Hour},
+			want: []time.Duration{time.Hour, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewReminderScheduler(nil, nil, nil, tt.leads)
+			if !reflect.DeepEqual(s.leads, tt.want) {
+				t.Errorf("leads = %v, want %v", s.leads, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrentLead(t *testing.T) {
+	s := NewReminderScheduler(nil, nil, nil, []time.Duration{time.Hour, 24 * time.Hour})
+
+	tests := []struct {
+		name   string
+		left   time.Duration
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "раньше всех окон", left: 25 * time.Hour, wantOK: false},
+		{name: "ровно за сутки", left: 24 * time.Hour, want: 24 * time.Hour, wantOK: true},
+		{name: "в окне суток", left: 5 * time.Hour, want: 24 * time.Hour, wantOK: true},
+		{name: "в окне часа", left: 30 * time.Minute, want: time.Hour, wantOK: true},
+		{name: "срок наступил", left: 0, want: 0, wantOK: true},
+		{name: "просрочено", left: -10 * time.Minute, want: 0, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := s.currentLead(tt.left)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("currentLead(%v) = %v, %v, want %v, %v", tt.left, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
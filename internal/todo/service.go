@@ -2,36 +2,588 @@ package todo
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
+
+	"tg_bot_asist/internal/recurrence"
 )
 
 type Service struct {
 	repo Repository
+
+	// участники общих проектов узнают об изменениях через бота и события в реальном времени
+	notifier Notifier
+	events   EventPublisher
+
+	// часовые пояса пользователей для расчёта повторений (BYDAY, BYMONTHDAY — по местным дням)
+	users LocationProvider
 }
 
 func NewService(r Repository) *Service {
 	return &Service{repo: r}
 }
 
+// SetLocations подключает часовые пояса пользователей; без них повторения считаются в поясе сервера.
+func (s *Service) SetLocations(users LocationProvider) {
+	s.users = users
+}
+
+// location возвращает часовой пояс пользователя или часовой пояс сервера.
+func (s *Service) location(ctx context.Context, userID int64) *time.Location {
+	if s.users == nil {
+		return time.Local
+	}
+	return s.users.Location(ctx, userID)
+}
+
 func (s *Service) Add(ctx context.Context, userID int64, title, desc string, due *time.Time) error {
-	item := &Item{
+	return s.Create(ctx, &Item{
 		UserID:      userID,
 		Title:       title,
 		Description: desc,
 		DueDate:     due,
-		Status:      "pending",
-		CreatedAt:   time.Now(),
+	})
+}
+
+// Create сохраняет задачу с приоритетом и метками и заполняет её ID.
+// Статус, время создания и приоритет по умолчанию проставляются автоматически.
+// Подзадача (ParentID) может ссылаться только на задачу верхнего уровня того же пользователя.
+// Задача без проекта попадает в Inbox.
+func (s *Service) Create(ctx context.Context, item *Item) error {
+	if item.ParentID != nil {
+		parent, err := s.getForEdit(ctx, item.UserID, *item.ParentID)
+		if errors.Is(err, ErrNotFound) || (err == nil && parent.ParentID != nil) {
+			return ErrInvalidParent
+		}
+		if err != nil {
+			return err
+		}
+		// подзадача всегда в проекте родительской задачи
+		item.ProjectID = parent.ProjectID
 	}
-	_, err := s.repo.Create(ctx, item)
-	return err
+	if err := s.resolveProject(ctx, item); err != nil {
+		return err
+	}
+
+	item.Status = StatusPending
+	item.CreatedAt = time.Now()
+	if !item.Priority.Valid() {
+		item.Priority = PriorityNormal
+	}
+	item.Tags = NormalizeTags(item.Tags)
+	if item.RRule != "" {
+		rule, err := parseRule(item.RRule)
+		if err != nil {
+			return err
+		}
+		item.RRule = rule.String()
+	}
+
+	id, err := s.repo.Create(ctx, item)
+	if err != nil {
+		return err
+	}
+	item.ID = id
+	s.shareChange(ctx, item.UserID, EventTodoAdded, item)
+	return nil
 }
 
 func (s *Service) List(ctx context.Context, userID int64) ([]Item, error) {
 	return s.repo.List(ctx, userID)
 }
 
+// ListFiltered возвращает задачи по фильтру: метке, приоритету и представлению по сроку.
+// loc — часовой пояс пользователя для границ «сегодня» и «неделя».
+func (s *Service) ListFiltered(ctx context.Context, userID int64, f ListFilter, loc *time.Location) ([]Item, error) {
+	return s.repo.ListFiltered(ctx, userID, f.query(time.Now(), loc))
+}
+
+// Get возвращает задачу пользователя по ID вместе с чек-листом.
+func (s *Service) Get(ctx context.Context, userID int64, id int) (*Item, error) {
+	item, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Checklist, err = s.repo.ListChecklist(ctx, id); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Subtasks возвращает подзадачи задачи.
+func (s *Service) Subtasks(ctx context.Context, userID int64, id int) ([]Item, error) {
+	return s.repo.ListChildren(ctx, userID, id)
+}
+
+// AddSubtask создаёт подзадачу.
+func (s *Service) AddSubtask(ctx context.Context, userID int64, parentID int, title string) (*Item, error) {
+	item := &Item{UserID: userID, Title: title, ParentID: &parentID}
+	if err := s.Create(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// AddChecklistItem добавляет пункт в чек-лист задачи.
+func (s *Service) AddChecklistItem(ctx context.Context, userID int64, todoID int, title string) (*ChecklistItem, error) {
+	item, err := s.getForEdit(ctx, userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.repo.AddChecklistItem(ctx, item.UserID, todoID, title)
+	if err != nil {
+		return nil, err
+	}
+	s.shareChange(ctx, userID, EventTodoUpdated, item)
+	return c, nil
+}
+
+// ToggleChecklistItem отмечает пункт чек-листа (или снимает отметку) и возвращает задачу после изменения.
+// Если у задачи включено автовыполнение и отмечен последний пункт, задача выполняется.
+func (s *Service) ToggleChecklistItem(ctx context.Context, userID int64, itemID int) (*ChecklistItem, *Item, error) {
+	c, err := s.repo.ToggleChecklistItem(ctx, userID, itemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.Done {
+		if err := s.autoComplete(ctx, userID, c.TodoID); err != nil {
+			return c, nil, err
+		}
+	}
+	item, err := s.Get(ctx, userID, c.TodoID)
+	if err != nil {
+		return c, nil, err
+	}
+	s.shareChange(ctx, userID, EventTodoUpdated, item)
+	return c, item, nil
+}
+
+// DeleteChecklistItem удаляет пункт чек-листа и возвращает задачу после изменения.
+func (s *Service) DeleteChecklistItem(ctx context.Context, userID int64, itemID int) (*Item, error) {
+	c, err := s.repo.DeleteChecklistItem(ctx, userID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.Get(ctx, userID, c.TodoID)
+	if err != nil {
+		return nil, err
+	}
+	s.shareChange(ctx, userID, EventTodoUpdated, item)
+	return item, nil
+}
+
+// SetAutoComplete включает или выключает автовыполнение задачи.
+// Если все пункты и подзадачи уже выполнены, задача выполняется сразу.
+func (s *Service) SetAutoComplete(ctx context.Context, userID int64, id int, on bool) (*Item, error) {
+	item, err := s.getForEdit(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item.AutoComplete = on
+
+	if err := s.save(ctx, userID, item, EventTodoUpdated); err != nil {
+		return nil, err
+	}
+	if err := s.autoComplete(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+// autoComplete выполняет задачу с включённым автовыполнением, если все её пункты и подзадачи выполнены.
+func (s *Service) autoComplete(ctx context.Context, userID int64, id int) error {
+	item, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !item.AutoComplete || item.Status == StatusCompleted || !item.Progress.Complete() {
+		return nil
+	}
+	_, _, err = s.CompleteWithNext(ctx, userID, id)
+	return err
+}
+
+// Delete удаляет задачу вместе с подзадачами и чек-листом.
 func (s *Service) Delete(ctx context.Context, userID int64, id int) error {
-	return s.repo.Delete(ctx, userID, id)
+	item, err := s.getForEdit(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, item.UserID, id); err != nil {
+		return err
+	}
+	s.shareChange(ctx, userID, EventTodoDeleted, item)
+	return nil
+}
+
+// Complete отмечает задачу выполненной и сохраняет время завершения.
+func (s *Service) Complete(ctx context.Context, userID int64, id int) (*Item, error) {
+	item, _, err := s.CompleteWithNext(ctx, userID, id)
+	return item, err
+}
+
+// CompleteWithNext отмечает задачу выполненной. Для повторяющейся задачи создаётся
+// следующее вхождение, которое возвращается вторым значением (nil, если серия закончилась).
+// Выполнение подзадачи может выполнить родительскую задачу (см. AutoComplete).
+// Запущенный пользователем таймер задачи останавливается.
+func (s *Service) CompleteWithNext(ctx context.Context, userID int64, id int) (*Item, *Item, error) {
+	item, err := s.getForEdit(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item.Status == StatusCompleted {
+		return item, nil, nil
+	}
+
+	now := time.Now()
+	item.Status = StatusCompleted
+	item.CompletedAt = &now
+
+	if err := s.save(ctx, userID, item, EventTodoCompleted); err != nil {
+		return nil, nil, err
+	}
+
+	next, err := s.afterComplete(ctx, userID, item, now)
+	return item, next, err
+}
+
+// afterComplete выполняет действия после сохранения выполненной задачи: останавливает её таймер,
+// создаёт следующее вхождение повторяющейся задачи и проверяет автовыполнение родителя.
+// Задача уже выполнена, поэтому ошибки возвращаются вызывающему вместе с созданным вхождением.
+func (s *Service) afterComplete(ctx context.Context, userID int64, item *Item, now time.Time) (*Item, error) {
+	if running, err := s.repo.RunningTimer(ctx, userID); err == nil && running != nil && running.TodoID == item.ID {
+		if _, err := s.repo.StopTimer(ctx, userID, now); err != nil && !errors.Is(err, ErrNoTimer) {
+			return nil, err
+		}
+	}
+
+	var (
+		next *Item
+		err  error
+	)
+	if item.RRule != "" {
+		if next, err = s.createNextOccurrence(ctx, item, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if item.ParentID != nil {
+		if err := s.autoComplete(ctx, userID, *item.ParentID); err != nil {
+			return next, err
+		}
+	}
+	return next, nil
+}
+
+// createNextOccurrence создаёт следующее вхождение повторяющейся задачи (подзадача остаётся подзадачей).
+// Серия отсчитывается от срока текущего вхождения (или от момента выполнения, если срока нет)
+// в часовом поясе владельца задачи. COUNT уменьшается на единицу с каждым вхождением.
+func (s *Service) createNextOccurrence(ctx context.Context, item *Item, completedAt time.Time) (*Item, error) {
+	rule, err := parseRule(item.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	anchor := completedAt
+	if item.DueDate != nil {
+		anchor = *item.DueDate
+	}
+	anchor = anchor.In(s.location(ctx, item.UserID))
+
+	due, ok := rule.Next(anchor, anchor)
+	if !ok {
+		return nil, nil
+	}
+	// просроченная задача: пропускаем вхождения, срок которых уже прошёл
+	for due.Before(completedAt) {
+		if rule.Count > 0 {
+			rule.Count--
+		}
+		anchor = due
+		if due, ok = rule.Next(anchor, anchor); !ok {
+			return nil, nil
+		}
+	}
+	if rule.Count > 0 {
+		rule.Count--
+	}
+
+	next := &Item{
+		UserID:       item.UserID,
+		Title:        item.Title,
+		Description:  item.Description,
+		DueDate:      &due,
+		Priority:     item.Priority,
+		Tags:         item.Tags,
+		RRule:        rule.String(),
+		AutoComplete: item.AutoComplete,
+		ProjectID:    item.ProjectID,
+		ParentID:     item.ParentID,
+	}
+	if err := s.Create(ctx, next); err != nil {
+		return nil, err
+	}
+
+	// чек-лист переносится в следующее вхождение без отметок
+	checklist, err := s.repo.ListChecklist(ctx, item.ID)
+	if err != nil {
+		return next, err
+	}
+	for _, c := range checklist {
+		if _, err := s.repo.AddChecklistItem(ctx, next.UserID, next.ID, c.Title); err != nil {
+			return next, err
+		}
+	}
+	return next, nil
+}
+
+// Reopen возвращает выполненную задачу в работу.
+func (s *Service) Reopen(ctx context.Context, userID int64, id int) (*Item, error) {
+	item, err := s.getForEdit(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Status = StatusPending
+	item.CompletedAt = nil
+
+	if err := s.save(ctx, userID, item, EventTodoReopened); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Update изменяет заголовок, описание и срок задачи.
+// nil-поля остаются без изменений; для снятия срока используйте ClearDue.
+func (s *Service) Update(ctx context.Context, userID int64, id int, title, desc *string, due *time.Time) (*Item, error) {
+	item, err := s.getForEdit(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if title != nil {
+		item.Title = *title
+	}
+	if desc != nil {
+		item.Description = *desc
+	}
+	if due != nil {
+		item.DueDate = due
+	}
+
+	if err := s.save(ctx, userID, item, EventTodoUpdated); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Patch — изменения задачи для Edit; nil-поля остаются без изменений.
+type Patch struct {
+	Title        *string
+	Description  *string
+	DueDate      *time.Time
+	ClearDue     bool // снять срок; DueDate при этом не учитывается
+	Priority     *Priority
+	Tags         *[]string // заменяет все метки
+	RRule        *string   // правило повторения; пустая строка отключает повтор
+	AutoComplete *bool
+	ProjectID    *int // перенос задачи (с подзадачами) в проект
+}
+
+// Edit сначала проверяет все изменения (приоритет, правило повторения, права на проект), затем применяет их
+// и сохраняет задачу одним обновлением. Если хотя бы одно изменение некорректно, задача не меняется.
+// Участники получают одно событие об изменении; при переносе в другой проект — уход из старого и появление в новом.
+func (s *Service) Edit(ctx context.Context, userID int64, id int, p Patch) (*Item, error) {
+	if p.Priority != nil && !p.Priority.Valid() {
+		return nil, ErrInvalidPriority
+	}
+	rrule := ""
+	if p.RRule != nil && *p.RRule != "" {
+		rule, err := parseRule(*p.RRule)
+		if err != nil {
+			return nil, err
+		}
+		rrule = rule.String()
+	}
+
+	item, err := s.getForEdit(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	move := p.ProjectID != nil && *p.ProjectID != item.ProjectID
+	if move {
+		if item.ParentID != nil {
+			return nil, ErrInvalidParent
+		}
+		if err := s.checkProjectEdit(ctx, userID, *p.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Title != nil {
+		item.Title = *p.Title
+	}
+	if p.Description != nil {
+		item.Description = *p.Description
+	}
+	if p.ClearDue {
+		item.DueDate = nil
+	} else if p.DueDate != nil {
+		item.DueDate = p.DueDate
+	}
+	if p.Priority != nil {
+		item.Priority = *p.Priority
+	}
+	if p.Tags != nil {
+		item.Tags = NormalizeTags(*p.Tags)
+	}
+	if p.RRule != nil {
+		item.RRule = rrule
+	}
+	if p.AutoComplete != nil {
+		item.AutoComplete = *p.AutoComplete
+	}
+
+	if !move {
+		if err := s.save(ctx, userID, item, EventTodoUpdated); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := s.repo.Update(ctx, item); err != nil {
+			return nil, err
+		}
+		if err := s.repo.MoveTodo(ctx, id, *p.ProjectID); err != nil {
+			return nil, err
+		}
+		// участники старого проекта узнают об уходе задачи, участники нового — о её появлении
+		s.shareChange(ctx, userID, EventTodoDeleted, item)
+	}
+
+	// включённое автовыполнение срабатывает сразу, если всё уже выполнено
+	if p.AutoComplete != nil && *p.AutoComplete {
+		if err := s.autoComplete(ctx, userID, id); err != nil {
+			return nil, err
+		}
+	}
+	updated, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if move {
+		s.shareChange(ctx, userID, EventTodoAdded, updated)
+	}
+	return updated, nil
+}
+
+// SetPriority меняет приоритет задачи.
+func (s *Service) SetPriority(ctx context.Context, userID int64, id int, p Priority) (*Item, error) {
+	if !p.Valid() {
+		return nil, ErrInvalidPriority
+	}
+
+	item, err := s.getForEdit(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Priority = p
+
+	if err := s.save(ctx, userID, item, EventTodoUpdated); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// SetTags заменяет метки задачи.
+func (s *Service) SetTags(ctx context.Context, userID int64, id int, tags []string) (*Item, error) {
+	item, err := s.getForEdit(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Tags = NormalizeTags(tags)
+
+	if err := s.save(ctx, userID, item, EventTodoUpdated); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// SetRecurrence задаёт правило повторения задачи; пустая строка отключает повтор.
+func (s *Service) SetRecurrence(ctx context.Context, userID int64, id int, rrule string) (*Item, error) {
+	if rrule != "" {
+		rule, err := parseRule(rrule)
+		if err != nil {
+			return nil, err
+		}
+		rrule = rule.String()
+	}
+
+	item, err := s.getForEdit(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item.RRule = rrule
+
+	if err := s.save(ctx, userID, item, EventTodoUpdated); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// parseRule разбирает правило повторения и приводит ошибку к ErrInvalidRecurrence.
+func parseRule(rrule string) (recurrence.Rule, error) {
+	rule, err := recurrence.Parse(rrule)
+	if err != nil {
+		return recurrence.Rule{}, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+	return rule, nil
+}
+
+// Snooze откладывает задачу: переносит срок на until.
+// Отметки об отправленных и повторных напоминаниях сбрасываются, напоминания начнутся заново.
+func (s *Service) Snooze(ctx context.Context, userID int64, id int, until time.Time) (*Item, error) {
+	return s.Update(ctx, userID, id, nil, nil, &until)
+}
+
+// Acknowledge прекращает повторные напоминания о просроченной задаче до изменения её срока.
+func (s *Service) Acknowledge(ctx context.Context, userID int64, id int) error {
+	item, err := s.getForEdit(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	return s.repo.Acknowledge(ctx, item.UserID, id)
+}
+
+// EscalationPolicy возвращает политику напоминаний пользователя.
+func (s *Service) EscalationPolicy(ctx context.Context, userID int64) (EscalationPolicy, error) {
+	return s.repo.GetEscalationPolicy(ctx, userID)
+}
+
+// SaveEscalationPolicy проверяет и сохраняет политику напоминаний пользователя.
+func (s *Service) SaveEscalationPolicy(ctx context.Context, p EscalationPolicy) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	return s.repo.SaveEscalationPolicy(ctx, p)
+}
+
+// ClearDue снимает срок задачи.
+func (s *Service) ClearDue(ctx context.Context, userID int64, id int) (*Item, error) {
+	item, err := s.getForEdit(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item.DueDate = nil
+
+	if err := s.save(ctx, userID, item, EventTodoUpdated); err != nil {
+		return nil, err
+	}
+	return item, nil
 }
 
 func (s *Service) CreateAuto(ctx context.Context, userID int64, title string) error {
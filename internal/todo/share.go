@@ -0,0 +1,254 @@
+package todo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"tg_bot_asist/internal/logger"
+)
+
+// Role — права пользователя в проекте.
+type Role string
+
+const (
+	RoleOwner Role = "owner" // создатель проекта: управляет проектом и участниками
+	RoleEdit  Role = "edit"  // участник может добавлять, изменять и выполнять задачи
+	RoleView  Role = "view"  // участник только просматривает задачи
+)
+
+// ParseRole разбирает роль участника (edit или view).
+func ParseRole(s string) (Role, bool) {
+	switch r := Role(s); r {
+	case RoleEdit, RoleView:
+		return r, true
+	}
+	return "", false
+}
+
+// CanEdit сообщает, может ли пользователь с этой ролью изменять задачи проекта.
+func (r Role) CanEdit() bool {
+	return r == RoleOwner || r == RoleEdit
+}
+
+// InviteTTL — срок действия ссылки-приглашения.
+const InviteTTL = 7 * 24 * time.Hour
+
+// События изменения задач, которые рассылаются участникам общих проектов.
+const (
+	EventTodoAdded     = "todo_added"
+	EventTodoUpdated   = "todo_updated"
+	EventTodoCompleted = "todo_completed"
+	EventTodoReopened  = "todo_reopened"
+	EventTodoDeleted   = "todo_deleted"
+	EventTodoMoved     = "todo_moved" // перемещение на канбан-доске
+)
+
+// Ошибки совместного доступа.
+var (
+	ErrForbidden      = errors.New("недостаточно прав")
+	ErrInviteNotFound = errors.New("приглашение не найдено или устарело")
+	ErrInboxShared    = errors.New("Inbox нельзя сделать общим")
+	ErrNotMember      = errors.New("пользователь не участник проекта")
+	ErrAlreadyMember  = errors.New("вы уже участник проекта")
+)
+
+// Invite — одноразовое приглашение в проект.
+type Invite struct {
+	Token     string
+	ProjectID int
+	Role      Role
+	CreatedBy int64
+	ExpiresAt time.Time
+}
+
+// Member — участник общего проекта.
+type Member struct {
+	ProjectID int       `json:"project_id"`
+	UserID    int64     `json:"user_id"`
+	Role      Role      `json:"role"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+// EventPublisher рассылает события в реальном времени (реализуется websocket.Hub).
+type EventPublisher interface {
+	Publish(userID int64, eventType string, data interface{})
+}
+
+// ShareRepository хранит участников и приглашения общих проектов.
+type ShareRepository interface {
+	CreateInvite(ctx context.Context, inv *Invite) error
+	// AcceptInvite в одной транзакции удаляет действующее приглашение и добавляет userID в участники проекта
+	// с ролью из приглашения (ErrInviteNotFound, если его нет или оно истекло).
+	// Владельцу и участнику проекта возвращается ErrAlreadyMember, приглашение при этом остаётся.
+	AcceptInvite(ctx context.Context, token string, userID int64, now time.Time) (*Invite, error)
+	ListMembers(ctx context.Context, projectID int) ([]Member, error)
+	// RemoveMember исключает участника; его задачи в проекте переходят владельцу (ErrNotMember, если участника нет).
+	RemoveMember(ctx context.Context, projectID int, userID int64) error
+	// ProjectAudience возвращает название проекта и всех, у кого есть к нему доступ: владельца и участников.
+	ProjectAudience(ctx context.Context, projectID int) (string, []int64, error)
+}
+
+// SetSharing подключает рассылку изменений общих проектов: сообщения бота и события websocket.
+// Любой из аргументов может быть nil.
+func (s *Service) SetSharing(n Notifier, events EventPublisher) {
+	s.notifier = n
+	s.events = events
+}
+
+// Invite создаёт ссылку-приглашение в проект с ролью role. Приглашать может только владелец.
+func (s *Service) Invite(ctx context.Context, userID int64, projectID int, role Role) (*Invite, error) {
+	if _, ok := ParseRole(string(role)); !ok {
+		return nil, fmt.Errorf("неизвестная роль %q", role)
+	}
+	p, err := s.repo.GetProject(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Role != RoleOwner {
+		return nil, ErrForbidden
+	}
+	if p.Inbox {
+		return nil, ErrInboxShared
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	inv := &Invite{
+		Token:     hex.EncodeToString(buf),
+		ProjectID: p.ID,
+		Role:      role,
+		CreatedBy: userID,
+		ExpiresAt: time.Now().Add(InviteTTL),
+	}
+	if err := s.repo.CreateInvite(ctx, inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// Join принимает приглашение: пользователь становится участником проекта.
+// Приглашение одноразовое; владелец и участники проекта получают ErrAlreadyMember и не тратят его.
+func (s *Service) Join(ctx context.Context, userID int64, token string) (*Project, error) {
+	inv, err := s.repo.AcceptInvite(ctx, token, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetProject(ctx, userID, inv.ProjectID)
+}
+
+// Members возвращает участников проекта (без владельца).
+func (s *Service) Members(ctx context.Context, userID int64, projectID int) ([]Member, error) {
+	if _, err := s.repo.GetProject(ctx, userID, projectID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, projectID)
+}
+
+// RemoveMember исключает участника из проекта. Исключать может владелец; участник может выйти сам.
+// Задачи, созданные участником в проекте, остаются в проекте и переходят владельцу.
+func (s *Service) RemoveMember(ctx context.Context, userID int64, projectID int, memberID int64) error {
+	p, err := s.repo.GetProject(ctx, userID, projectID)
+	if err != nil {
+		return err
+	}
+	if p.Role != RoleOwner && memberID != userID {
+		return ErrForbidden
+	}
+	return s.repo.RemoveMember(ctx, projectID, memberID)
+}
+
+// getForEdit возвращает задачу, которую пользователь может изменять:
+// свою задачу или задачу общего проекта, в котором у него есть право на изменение.
+func (s *Service) getForEdit(ctx context.Context, userID int64, id int) (*Item, error) {
+	item, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.ProjectID == 0 {
+		return item, nil
+	}
+	p, err := s.repo.GetProject(ctx, userID, item.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Role.CanEdit() {
+		return nil, ErrForbidden
+	}
+	return item, nil
+}
+
+// GetForEdit возвращает задачу, если пользователь может её изменять (ErrForbidden — доступ только на просмотр).
+func (s *Service) GetForEdit(ctx context.Context, userID int64, id int) (*Item, error) {
+	return s.getForEdit(ctx, userID, id)
+}
+
+// save сохраняет задачу и рассылает изменение участникам её проекта.
+func (s *Service) save(ctx context.Context, userID int64, item *Item, event string) error {
+	if err := s.repo.Update(ctx, item); err != nil {
+		return err
+	}
+	s.shareChange(ctx, userID, event, item)
+	return nil
+}
+
+// shareChange сообщает участникам общего проекта (кроме автора изменения) об изменении задачи:
+// событием websocket — о любом изменении, сообщением бота — о добавлении, выполнении, возврате в работу и удалении.
+// Ошибки рассылки только логируются: изменение уже сохранено.
+func (s *Service) shareChange(ctx context.Context, actor int64, event string, item *Item) {
+	if (s.notifier == nil && s.events == nil) || item.ProjectID == 0 {
+		return
+	}
+
+	name, audience, err := s.repo.ProjectAudience(ctx, item.ProjectID)
+	if err != nil {
+		logger.Error("Todo share error: " + err.Error())
+		return
+	}
+	if len(audience) < 2 {
+		// проект не общий
+		return
+	}
+
+	s.publish(ctx, actor, audience, event, item, shareText(event, name, item))
+}
+
+// publish отправляет событие eventType и сообщение text (если не пусто) всем из audience, кроме actor.
+func (s *Service) publish(ctx context.Context, actor int64, audience []int64, eventType string, data interface{}, text string) {
+	for _, uid := range audience {
+		if uid == actor {
+			continue
+		}
+		if s.events != nil {
+			s.events.Publish(uid, eventType, data)
+		}
+		if s.notifier != nil && text != "" {
+			if err := s.notifier.Notify(ctx, uid, text); err != nil {
+				logger.Error(fmt.Sprintf("Todo share: failed to notify user %d: %s", uid, err.Error()))
+			}
+		}
+	}
+}
+
+// shareText возвращает текст сообщения участнику проекта; пустая строка — сообщение не отправляется.
+func shareText(event, project string, item *Item) string {
+	var action string
+	switch event {
+	case EventTodoAdded:
+		action = "добавлена задача"
+	case EventTodoCompleted:
+		action = "выполнена задача"
+	case EventTodoReopened:
+		action = "возвращена в работу задача"
+	case EventTodoDeleted:
+		action = "удалена задача"
+	default:
+		return ""
+	}
+	return fmt.Sprintf("👥 %s: %s %d) %s", project, action, item.ID, item.Title)
+}
@@ -0,0 +1,208 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// Ограничения периода статистики в днях.
+const (
+	DefaultStatsDays = 28
+	MaxStatsDays     = 365
+)
+
+// StreakLookback — насколько далеко в прошлое ищутся дни с выполненными задачами для серий.
+const StreakLookback = 365 * 24 * time.Hour
+
+// ErrInvalidStatsPeriod возвращается для периода статистики вне 1..MaxStatsDays дней.
+var ErrInvalidStatsPeriod = errors.New("некорректный период статистики")
+
+// StatsItem — даты задачи, по которым считается статистика.
+type StatsItem struct {
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	DueDate     *time.Time
+}
+
+// StatsBucket — создано и выполнено задач за день или неделю, начинающиеся в Start.
+type StatsBucket struct {
+	Start     time.Time `json:"start"`
+	Created   int       `json:"created"`
+	Completed int       `json:"completed"`
+}
+
+// Stats — статистика выполнения задач за период [From, To).
+type Stats struct {
+	From      time.Time     `json:"from"`
+	To        time.Time     `json:"to"`
+	Created   int           `json:"created"`
+	Completed int           `json:"completed"`
+	Days      []StatsBucket `json:"days"`
+	Weeks     []StatsBucket `json:"weeks"` // недели с понедельника; крайние недели могут быть неполными
+	// MedianCompletion — медиана времени от создания до выполнения задач, выполненных за период.
+	MedianCompletion time.Duration `json:"-"`
+	// Due — задачи, срок которых наступил за период; Overdue — из них выполнены позже срока или не выполнены.
+	Due     int `json:"due"`
+	Overdue int `json:"overdue"`
+	// CurrentStreak — дней подряд с выполненными задачами, включая сегодня (или до вчера, если сегодня ещё ничего не выполнено).
+	CurrentStreak int `json:"current_streak"`
+	// LongestStreak — самая длинная серия за StreakLookback.
+	LongestStreak int `json:"longest_streak"`
+}
+
+// OverdueRate возвращает долю просроченных задач среди задач со сроком в периоде (0..1).
+func (s Stats) OverdueRate() float64 {
+	if s.Due == 0 {
+		return 0
+	}
+	return float64(s.Overdue) / float64(s.Due)
+}
+
+// StatsRange возвращает период из days последних дней, включая сегодняшний, в часовом поясе loc.
+func StatsRange(now time.Time, loc *time.Location, days int) (time.Time, time.Time) {
+	now = now.In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+	return to.AddDate(0, 0, -days), to
+}
+
+// dayStart возвращает начало дня t в часовом поясе loc.
+func dayStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// NewStats считает статистику за период [from, to) в часовом поясе loc на момент now.
+// items должны включать задачи, выполненные за StreakLookback до now, чтобы серии считались полностью.
+func NewStats(items []StatsItem, from, to, now time.Time, loc *time.Location) Stats {
+	s := Stats{From: from, To: to}
+
+	dayIndex := map[time.Time]int{}
+	for d := dayStart(from, loc); d.Before(to); d = d.AddDate(0, 0, 1) {
+		dayIndex[d] = len(s.Days)
+		s.Days = append(s.Days, StatsBucket{Start: d})
+	}
+	weekIndex := map[time.Time]int{}
+	for _, d := range s.Days {
+		w, _ := WeekRange(d.Start, loc, 0)
+		if _, ok := weekIndex[w]; !ok {
+			weekIndex[w] = len(s.Weeks)
+			s.Weeks = append(s.Weeks, StatsBucket{Start: w})
+		}
+	}
+	inPeriod := func(t time.Time) bool { return !t.Before(from) && t.Before(to) }
+	bucket := func(t time.Time) (*StatsBucket, *StatsBucket) {
+		d := dayStart(t, loc)
+		w, _ := WeekRange(t, loc, 0)
+		return &s.Days[dayIndex[d]], &s.Weeks[weekIndex[w]]
+	}
+
+	var durations []time.Duration
+	doneDays := map[time.Time]bool{}
+	for _, it := range items {
+		if inPeriod(it.CreatedAt) {
+			s.Created++
+			day, week := bucket(it.CreatedAt)
+			day.Created++
+			week.Created++
+		}
+
+		if it.CompletedAt != nil {
+			done := *it.CompletedAt
+			doneDays[dayStart(done, loc)] = true
+			if inPeriod(done) {
+				s.Completed++
+				day, week := bucket(done)
+				day.Completed++
+				week.Completed++
+				if d := done.Sub(it.CreatedAt); d >= 0 {
+					durations = append(durations, d)
+				}
+			}
+		}
+
+		// просрочка считается только по срокам, которые уже наступили
+		if it.DueDate != nil && inPeriod(*it.DueDate) && it.DueDate.Before(now) {
+			s.Due++
+			if it.CompletedAt == nil || it.CompletedAt.After(*it.DueDate) {
+				s.Overdue++
+			}
+		}
+	}
+
+	s.MedianCompletion = medianDuration(durations)
+	s.CurrentStreak, s.LongestStreak = streaks(doneDays, dayStart(now, loc))
+	return s
+}
+
+// medianDuration возвращает медиану длительностей (0 для пустого списка).
+func medianDuration(ds []time.Duration) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	mid := len(ds) / 2
+	if len(ds)%2 == 1 {
+		return ds[mid]
+	}
+	return (ds[mid-1] + ds[mid]) / 2
+}
+
+// streaks возвращает текущую и самую длинную серию дней подряд с выполненными задачами.
+// Текущая серия не прерывается, если сегодня ещё ничего не выполнено.
+func streaks(days map[time.Time]bool, today time.Time) (current, longest int) {
+	sorted := make([]time.Time, 0, len(days))
+	for d := range days {
+		if !d.After(today) {
+			sorted = append(sorted, d)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	run := 0
+	for i, d := range sorted {
+		if i > 0 && sorted[i-1].AddDate(0, 0, 1).Equal(d) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	day := today
+	if !days[day] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return current, longest
+}
+
+// StatsRepository выбирает даты задач для статистики.
+type StatsRepository interface {
+	// ListStatsItems возвращает собственные задачи пользователя, созданные, выполненные или со сроком не раньше since.
+	ListStatsItems(ctx context.Context, userID int64, since time.Time) ([]StatsItem, error)
+}
+
+// Stats считает статистику выполнения задач пользователя за days последних дней в часовом поясе loc.
+func (s *Service) Stats(ctx context.Context, userID int64, days int, loc *time.Location) (Stats, error) {
+	if days < 1 || days > MaxStatsDays {
+		return Stats{}, ErrInvalidStatsPeriod
+	}
+
+	now := time.Now()
+	from, to := StatsRange(now, loc, days)
+	since := from
+	if lookback := now.Add(-StreakLookback); lookback.Before(since) {
+		since = lookback
+	}
+
+	items, err := s.repo.ListStatsItems(ctx, userID, since)
+	if err != nil {
+		return Stats{}, err
+	}
+	return NewStats(items, from, to, now, loc), nil
+}
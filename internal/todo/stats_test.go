@@ -0,0 +1,83 @@
+package todo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewStats(t *testing.T) {
+	loc := time.UTC
+	day := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 0, 0, 0, loc) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	// период — 7 дней со вторника 3 марта по понедельник 9 марта, сейчас 9 марта 12:00
+	now := day(9, 12)
+	from, to := StatsRange(now, loc, 7)
+	if !from.Equal(day(3, 0)) || !to.Equal(day(10, 0)) {
+		t.Fatalf("StatsRange() = %v, %v", from, to)
+	}
+
+	items := []StatsItem{
+		{CreatedAt: day(3, 9), CompletedAt: ptr(day(3, 11))},                          // 2 ч
+		{CreatedAt: day(4, 9), CompletedAt: ptr(day(5, 9)), DueDate: ptr(day(4, 18))}, // 24 ч, просрочена
+		{CreatedAt: day(5, 9), CompletedAt: ptr(day(5, 13)), DueDate: ptr(day(6, 9))}, // 4 ч, в срок
+		{CreatedAt: day(6, 9), DueDate: ptr(day(8, 9))},                               // не выполнена, просрочена
+		{CreatedAt: day(8, 9), DueDate: ptr(day(9, 18))},                              // срок ещё не наступил
+		{CreatedAt: day(9, 9), CompletedAt: ptr(day(9, 10))},                          // 1 ч
+		{CreatedAt: day(1, 9), CompletedAt: ptr(day(2, 9))},                           // до периода, только серия
+	}
+
+	s := NewStats(items, from, to, now, loc)
+
+	if s.Created != 6 || s.Completed != 4 {
+		t.Errorf("created/completed = %d/%d, want 6/4", s.Created, s.Completed)
+	}
+	if len(s.Days) != 7 || s.Days[2].Created != 1 || s.Days[2].Completed != 2 {
+		t.Errorf("days = %+v", s.Days)
+	}
+	// неделя со 2 марта (3–8 марта) и неделя с 9 марта
+	if len(s.Weeks) != 2 || !s.Weeks[0].Start.Equal(day(2, 0)) || s.Weeks[0].Completed != 3 || s.Weeks[1].Completed != 1 {
+		t.Errorf("weeks = %+v", s.Weeks)
+	}
+	// длительности 1, 2, 4, 24 ч — медиана 3 ч
+	if s.MedianCompletion != 3*time.Hour {
+		t.Errorf("median = %v, want 3h", s.MedianCompletion)
+	}
+	if s.Due != 3 || s.Overdue != 2 {
+		t.Errorf("due/overdue = %d/%d, want 3/2", s.Due, s.Overdue)
+	}
+	// выполнения 2, 3, 5, 9 марта: текущая серия 1, самая длинная 2
+	if s.CurrentStreak != 1 || s.LongestStreak != 2 {
+		t.Errorf("streaks = %d/%d, want 1/2", s.CurrentStreak, s.LongestStreak)
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	today := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	days := func(offsets ...int) map[time.Time]bool {
+		m := map[time.Time]bool{}
+		for _, o := range offsets {
+			m[today.AddDate(0, 0, -o)] = true
+		}
+		return m
+	}
+
+	tests := []struct {
+		name             string
+		days             map[time.Time]bool
+		current, longest int
+	}{
+		{name: "пусто", days: days()},
+		{name: "сегодня и вчера", days: days(0, 1), current: 2, longest: 2},
+		{name: "сегодня ещё ничего", days: days(1, 2, 3), current: 3, longest: 3},
+		{name: "серия прервана", days: days(2, 3, 5, 6, 7, 8), current: 0, longest: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, longest := streaks(tt.days, today)
+			if current != tt.current || longest != tt.longest {
+				t.Errorf("streaks() = %d, %d, want %d, %d", current, longest, tt.current, tt.longest)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// Ошибки учёта времени.
+var (
+	// ErrTimerRunning возвращается, если у пользователя уже запущен таймер (запуск из другого места одновременно).
+	ErrTimerRunning = errors.New("таймер уже запущен")
+	// ErrNoTimer возвращается при остановке, если таймер не запущен.
+	ErrNoTimer = errors.New("таймер не запущен")
+)
+
+// NoTag — название группы отчёта для задач без меток.
+const NoTag = "без меток"
+
+// TimeEntry — интервал работы над задачей. Пока таймер идёт, StoppedAt == nil.
+type TimeEntry struct {
+	ID        int        `json:"id"`
+	TodoID    int        `json:"todo_id"`
+	UserID    int64      `json:"user_id"`
+	StartedAt time.Time  `json:"started_at"`
+	StoppedAt *time.Time `json:"stopped_at"`
+	Title     string     `json:"title"` // заголовок задачи
+}
+
+// Duration возвращает длительность интервала; для идущего таймера — по момент now.
+func (e TimeEntry) Duration(now time.Time) time.Duration {
+	end := now
+	if e.StoppedAt != nil {
+		end = *e.StoppedAt
+	}
+	return end.Sub(e.StartedAt)
+}
+
+// TimeRow — время, затраченное на задачу за период.
+type TimeRow struct {
+	TodoID   int
+	Title    string
+	Project  string
+	Tags     []string
+	Duration time.Duration
+}
+
+// TimeGroup — суммарное время группы отчёта (проекта или метки).
+type TimeGroup struct {
+	Name     string
+	Duration time.Duration
+}
+
+// TimeReport — отчёт о затраченном времени за период [From, To).
+// Задача с несколькими метками учитывается в каждой из них, поэтому сумма по меткам может превышать Total.
+type TimeReport struct {
+	From      time.Time
+	To        time.Time
+	Total     time.Duration
+	ByProject []TimeGroup
+	ByTag     []TimeGroup
+	Todos     []TimeRow
+}
+
+// NewTimeReport группирует время задач по проектам и меткам; группы и задачи упорядочены по убыванию времени.
+func NewTimeReport(from, to time.Time, rows []TimeRow) TimeReport {
+	r := TimeReport{From: from, To: to, Todos: rows}
+	projects := map[string]time.Duration{}
+	tags := map[string]time.Duration{}
+
+	for _, row := range rows {
+		r.Total += row.Duration
+		projects[row.Project] += row.Duration
+		if len(row.Tags) == 0 {
+			tags[NoTag] += row.Duration
+		}
+		for _, tag := range row.Tags {
+			tags[tag] += row.Duration
+		}
+	}
+
+	r.ByProject = sortedGroups(projects)
+	r.ByTag = sortedGroups(tags)
+	sort.SliceStable(r.Todos, func(i, j int) bool { return r.Todos[i].Duration > r.Todos[j].Duration })
+	return r
+}
+
+// sortedGroups переводит суммы в группы по убыванию времени, при равенстве — по имени.
+func sortedGroups(m map[string]time.Duration) []TimeGroup {
+	groups := make([]TimeGroup, 0, len(m))
+	for name, d := range m {
+		groups = append(groups, TimeGroup{Name: name, Duration: d})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Duration != groups[j].Duration {
+			return groups[i].Duration > groups[j].Duration
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// WeekRange возвращает границы недели (понедельник 00:00 — следующий понедельник 00:00), содержащей now,
+// в часовом поясе loc. offset сдвигает неделю: -1 — прошлая неделя.
+func WeekRange(now time.Time, loc *time.Location, offset int) (time.Time, time.Time) {
+	now = now.In(loc)
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	start := time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday+7*offset, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 0, 7)
+}
+
+// TimeRepository хранит интервалы учёта времени.
+type TimeRepository interface {
+	// StartTimer запускает таймер задачи (ErrTimerRunning, если у пользователя уже есть запущенный таймер).
+	StartTimer(ctx context.Context, userID int64, todoID int, at time.Time) (*TimeEntry, error)
+	// StopTimer останавливает запущенный таймер пользователя (ErrNoTimer, если его нет).
+	StopTimer(ctx context.Context, userID int64, at time.Time) (*TimeEntry, error)
+	// RunningTimer возвращает запущенный таймер пользователя или nil.
+	RunningTimer(ctx context.Context, userID int64) (*TimeEntry, error)
+	// TrackedTime возвращает время, затраченное пользователем на задачу, с учётом идущего таймера.
+	TrackedTime(ctx context.Context, userID int64, todoID int, now time.Time) (time.Duration, error)
+	// ListTimeRows возвращает время пользователя по задачам за период [from, to);
+	// интервалы на границах периода обрезаются, идущий таймер считается по now.
+	ListTimeRows(ctx context.Context, userID int64, from, to, now time.Time) ([]TimeRow, error)
+}
+
+// StartTimer запускает таймер задачи. Если запущен таймер другой задачи, он останавливается
+// и возвращается вторым значением: у пользователя всегда не больше одного идущего таймера.
+// Если таймер этой задачи уже идёт, он возвращается без изменений.
+func (s *Service) StartTimer(ctx context.Context, userID int64, todoID int) (*TimeEntry, *TimeEntry, error) {
+	item, err := s.repo.Get(ctx, userID, todoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	running, err := s.repo.RunningTimer(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if running != nil && running.TodoID == item.ID {
+		return running, nil, nil
+	}
+
+	now := time.Now()
+	var stopped *TimeEntry
+	if running != nil {
+		if stopped, err = s.repo.StopTimer(ctx, userID, now); err != nil && !errors.Is(err, ErrNoTimer) {
+			return nil, nil, err
+		}
+	}
+
+	started, err := s.repo.StartTimer(ctx, userID, item.ID, now)
+	if err != nil {
+		return nil, stopped, err
+	}
+	started.Title = item.Title
+	return started, stopped, nil
+}
+
+// StopTimer останавливает запущенный таймер пользователя.
+func (s *Service) StopTimer(ctx context.Context, userID int64) (*TimeEntry, error) {
+	return s.repo.StopTimer(ctx, userID, time.Now())
+}
+
+// RunningTimer возвращает запущенный таймер пользователя или nil.
+func (s *Service) RunningTimer(ctx context.Context, userID int64) (*TimeEntry, error) {
+	return s.repo.RunningTimer(ctx, userID)
+}
+
+// TrackedTime возвращает время, затраченное пользователем на задачу.
+func (s *Service) TrackedTime(ctx context.Context, userID int64, todoID int) (time.Duration, error) {
+	return s.repo.TrackedTime(ctx, userID, todoID, time.Now())
+}
+
+// TimeReport строит отчёт о затраченном времени за период [from, to).
+func (s *Service) TimeReport(ctx context.Context, userID int64, from, to time.Time) (TimeReport, error) {
+	rows, err := s.repo.ListTimeRows(ctx, userID, from, to, time.Now())
+	if err != nil {
+		return TimeReport{}, err
+	}
+	return NewTimeReport(from, to, rows), nil
+}
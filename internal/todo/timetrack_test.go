@@ -0,0 +1,67 @@
+package todo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewTimeReport(t *testing.T) {
+	rows := []TimeRow{
+		{TodoID: 1, Project: "Работа", Tags: []string{"клиент", "срочно"}, Duration: 30 * time.Minute},
+		{TodoID: 2, Project: "Inbox", Duration: 20 * time.Minute},
+		{TodoID: 3, Project: "Работа", Tags: []string{"клиент"}, Duration: 90 * time.Minute},
+	}
+
+	r := NewTimeReport(time.Time{}, time.Time{}, rows)
+
+	if r.Total != 140*time.Minute {
+		t.Errorf("Total = %v, want 2h20m", r.Total)
+	}
+	wantProjects := []TimeGroup{{"Работа", 120 * time.Minute}, {"Inbox", 20 * time.Minute}}
+	if !equalGroups(r.ByProject, wantProjects) {
+		t.Errorf("ByProject = %v, want %v", r.ByProject, wantProjects)
+	}
+	// задача с двумя метками учитывается в обеих
+	wantTags := []TimeGroup{{"клиент", 120 * time.Minute}, {"срочно", 30 * time.Minute}, {NoTag, 20 * time.Minute}}
+	if !equalGroups(r.ByTag, wantTags) {
+		t.Errorf("ByTag = %v, want %v", r.ByTag, wantTags)
+	}
+	if r.Todos[0].TodoID != 3 || r.Todos[2].TodoID != 2 {
+		t.Errorf("Todos not sorted by duration: %v", r.Todos)
+	}
+}
+
+func TestWeekRange(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	// воскресенье 23:30 по Москве — ещё та же неделя
+	now := time.Date(2026, time.October, 18, 20, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		offset int
+		want   time.Time
+	}{
+		{name: "текущая неделя", offset: 0, want: time.Date(2026, time.October, 12, 0, 0, 0, 0, msk)},
+		{name: "прошлая неделя", offset: -1, want: time.Date(2026, time.October, 5, 0, 0, 0, 0, msk)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := WeekRange(now, msk, tt.offset)
+			if !from.Equal(tt.want) || !to.Equal(tt.want.AddDate(0, 0, 7)) {
+				t.Errorf("WeekRange() = %v, %v; want %v, %v", from, to, tt.want, tt.want.AddDate(0, 0, 7))
+			}
+		})
+	}
+}
+
+func equalGroups(a, b []TimeGroup) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
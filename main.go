@@ -13,11 +13,15 @@ import (
 	"tg_bot_asist/internal/api"
 	"tg_bot_asist/internal/api/auth"
 	"tg_bot_asist/internal/api/websocket"
+	"tg_bot_asist/internal/attachment"
 	"tg_bot_asist/internal/bot"
+	"tg_bot_asist/internal/calendar"
 	"tg_bot_asist/internal/config"
 	"tg_bot_asist/internal/credits"
+	"tg_bot_asist/internal/digest"
 	"tg_bot_asist/internal/finance"
 	"tg_bot_asist/internal/logger"
+	"tg_bot_asist/internal/search"
 	"tg_bot_asist/internal/storage"
 	"tg_bot_asist/internal/todo"
 )
@@ -62,11 +66,30 @@ func main() {
 	creditsRepo := storage.NewCreditsRepo(db)
 	financeRepo := storage.NewFinanceRepo(db)
 	recurringRepo := finance.NewRecurringRepo(db)
+	calendarTokenRepo := storage.NewCalendarTokenRepo(db)
+	digestRepo := storage.NewDigestRepo(db)
+	searchRepo := storage.NewSearchRepo(db)
+	attachmentRepo := storage.NewAttachmentRepo(db)
 
 	// Инициализация сервисов
 	todoService := todo.NewService(todoRepo)
 	creditService := credits.NewService(creditsRepo)
 	financeService := finance.NewService(financeRepo, recurringRepo)
+	calendarService := calendar.NewService(todoService, financeService, creditService, calendarTokenRepo)
+	searchService := search.NewService(searchRepo)
+	attachmentService := attachment.NewService(attachmentRepo, todoService, financeService, state.Bot)
+
+	// Создание WebSocket Hub
+	wsHub := websocket.NewHub()
+	go wsHub.Run()
+
+	// Зависимости сервисов подключаются до запуска планировщиков: их первый проход начинается сразу.
+	// Изменения общих проектов задач рассылаются участникам через бота и WebSocket
+	todoService.SetSharing(bot.NewNotifier(state.Bot), wsHub)
+	todoService.SetLocations(userRepo)
+
+	// Пересечение 80% и 100% месячных бюджетов сообщается через бота и WebSocket
+	financeService.SetBudgetAlerts(userRepo, bot.NewNotifier(state.Bot), wsHub)
 
 	// Запуск планировщика регулярных платежей
 	scheduler := finance.NewRecurringScheduler(recurringRepo, todoService, financeService)
@@ -92,19 +115,64 @@ func main() {
 		}
 	}()
 
+	// Запуск планировщика напоминаний о сроках задач
+	reminderLeads, err := todo.ParseLeads(config.GetWithDefault("TODO_REMINDER_LEADS", "1d,1h"))
+	if err != nil {
+		logger.Fatal("Invalid TODO_REMINDER_LEADS: " + err.Error())
+	}
+	reminderScheduler := todo.NewReminderScheduler(todoRepo, bot.NewNotifier(state.Bot), userRepo, reminderLeads)
+	schedulerWg.Add(1)
+	go func() {
+		defer schedulerWg.Done()
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		reminderScheduler.RunCheck(schedulerCtx)
+
+		for {
+			select {
+			case <-schedulerCtx.Done():
+				logger.Info("Reminder scheduler stopped")
+				return
+			case <-ticker.C:
+				reminderScheduler.RunCheck(schedulerCtx)
+			}
+		}
+	}()
+
+	// Запуск рассылки утренней сводки
+	digestService := digest.NewService(digestRepo, todoService, financeService, creditService, userRepo, bot.NewNotifier(state.Bot))
+	schedulerWg.Add(1)
+	go func() {
+		defer schedulerWg.Done()
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		digestService.RunCheck(schedulerCtx)
+
+		for {
+			select {
+			case <-schedulerCtx.Done():
+				logger.Info("Digest scheduler stopped")
+				return
+			case <-ticker.C:
+				digestService.RunCheck(schedulerCtx)
+			}
+		}
+	}()
+
 	// Инициализация JWT
 	auth.InitJWT()
 
-	// Создание WebSocket Hub
-	wsHub := websocket.NewHub()
-	go wsHub.Run()
-
 	// Создание API роутера
 	apiRouter := api.NewRouter(
 		userRepo,
 		todoService,
 		financeService,
 		creditService,
+		calendarService,
+		searchService,
+		attachmentService,
 		wsHub,
 	)
 
@@ -145,12 +213,18 @@ func main() {
 			botCtx,
 			state.Bot,
 			state.Updates,
-			userRepo,
-			todoService,
-			stateRepo,
-			creditService,
-			financeService,
-			recurringRepo,
+			bot.Deps{
+				Users:       userRepo,
+				States:      stateRepo,
+				Todo:        todoService,
+				Finance:     financeService,
+				Recurring:   recurringRepo,
+				Credits:     creditService,
+				Calendar:    calendarService,
+				Digest:      digestService,
+				Search:      searchService,
+				Attachments: attachmentService,
+			},
 		)
 	}()
 